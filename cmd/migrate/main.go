@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"flight-booking/internal/database"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up                 apply every pending migration
+  down [-steps N]    revert the latest N applied migrations (default 1)
+  status             list migrations and when they were applied
+
+The database is configured by the same DB_* variables as the server.`
+
+func main() {
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dbConfig, err := database.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid database configuration: %v", err)
+	}
+	// Open skips the schema check that InitDB does; migrating is the point here
+	db, err := database.Open(dbConfig)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		for _, m := range applied {
+			fmt.Printf("applied  %04d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+
+	case "down":
+		downFlags := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := downFlags.Int("steps", 1, "number of migrations to revert")
+		downFlags.Parse(args)
+		if *steps < 1 {
+			log.Fatal("steps must be a positive integer")
+		}
+
+		reverted, err := database.MigrateDown(db, *steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations to revert")
+		}
+
+	case "status":
+		statuses, err := database.Status(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"flight-booking/internal/database"
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"flight-booking/internal/refdata"
+	"flight-booking/internal/repository"
+	"flight-booking/internal/service"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type airline struct {
+	Code string
+	Name string
+}
+
+type route struct {
+	From     string
+	To       string
+	Duration time.Duration
+	Fare     float64 // base economy fare in USD
+}
+
+var airlines = []airline{
+	{"BR", "EVA Air"},
+	{"CI", "China Airlines"},
+	{"JL", "Japan Airlines"},
+	{"NH", "All Nippon Airways"},
+	{"KE", "Korean Air"},
+	{"OZ", "Asiana Airlines"},
+	{"SQ", "Singapore Airlines"},
+	{"CX", "Cathay Pacific"},
+}
+
+// defaultStartDate is fixed so that a seed produces the same data whatever day it runs,
+// and far enough ahead that the generated flights can still be booked
+const defaultStartDate = "2030-01-01"
+
+// routes lists one direction only; the reverse direction is added in init
+var routes = []route{
+	{"TPE", "NRT", 3*time.Hour + 10*time.Minute, 320},
+	{"TPE", "HND", 3*time.Hour + 5*time.Minute, 340},
+	{"TPE", "KIX", 2*time.Hour + 40*time.Minute, 280},
+	{"TPE", "ICN", 2*time.Hour + 30*time.Minute, 260},
+	{"TPE", "HKG", 1*time.Hour + 50*time.Minute, 180},
+	{"TPE", "SIN", 4*time.Hour + 35*time.Minute, 390},
+	{"NRT", "ICN", 2*time.Hour + 35*time.Minute, 270},
+	{"NRT", "HKG", 5*time.Hour + 5*time.Minute, 420},
+	{"NRT", "SIN", 7*time.Hour + 20*time.Minute, 560},
+	{"ICN", "HKG", 3*time.Hour + 50*time.Minute, 330},
+	{"ICN", "SIN", 6*time.Hour + 25*time.Minute, 510},
+	{"HKG", "SIN", 3*time.Hour + 55*time.Minute, 300},
+}
+
+var passengerNames = []string{
+	"Chen Wei", "Lin Mei", "Wang Hao", "Sato Yuki", "Tanaka Ken",
+	"Kim Minji", "Park Joon", "Tan Li Ying", "Wong Ka Ho", "Lee Jia Hui",
+}
+
+func init() {
+	n := len(routes)
+	for _, r := range routes[:n] {
+		routes = append(routes, route{From: r.To, To: r.From, Duration: r.Duration, Fare: r.Fare})
+	}
+}
+
+func main() {
+	flightCount := flag.Int("flights", 1000, "number of flights to generate")
+	bookingCount := flag.Int("bookings", 0, "number of bookings to generate")
+	seed := flag.Int64("seed", 42, "random seed; the same seed produces the same data")
+	startDate := flag.String("start", defaultStartDate, "first departure date (YYYY-MM-DD)")
+	days := flag.Int("days", 31, "number of days departures are spread over")
+	flag.Parse()
+
+	start, err := time.Parse("2006-01-02", *startDate)
+	if err != nil {
+		log.Fatalf("invalid start date: %v", err)
+	}
+	if *flightCount < 0 || *bookingCount < 0 || *days < 1 {
+		log.Fatal("flights and bookings must not be negative and days must be positive")
+	}
+
+	// The seeder writes to the same database as the server, configured by the DB_* variables
+	dbConfig, err := database.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("invalid database configuration: %v", err)
+	}
+	db, err := database.InitDB(dbConfig)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	if err := service.LoadReferenceData(db); err != nil {
+		log.Fatalf("failed to load reference data: %v", err)
+	}
+
+	// Every generated row is stamped as written the day before the first departure
+	// rather than at the wall-clock time, so the data only depends on the flags
+	seededAt := start.AddDate(0, 0, -1)
+	db.Config.NowFunc = func() time.Time { return seededAt }
+
+	rng := rand.New(rand.NewSource(*seed))
+
+	flights := make([]models.Flight, 0, *flightCount)
+	for i := 0; i < *flightCount; i++ {
+		flights = append(flights, generateFlight(rng, start, *days))
+	}
+	if len(flights) > 0 {
+		if err := db.CreateInBatches(flights, 200).Error; err != nil {
+			log.Fatalf("failed to insert flights: %v", err)
+		}
+	}
+	fmt.Printf("Inserted %d flights\n", len(flights))
+
+	if *bookingCount == 0 || len(flights) == 0 {
+		return
+	}
+
+	// Bookings go through the service so seat inventory and statuses stay consistent
+	gateway := payment.NewFakeGateway()
+	bookingService := service.NewBookingService(repository.NewGORMBookingRepository(db), db, service.DefaultOversellPolicy, service.NewRefundService(db, gateway))
+	// Seeded confirmed bookings are paid right away so the payment sweeper does not
+	// release them; waitlisted ones have nothing to pay for until they are promoted
+	paymentService := service.NewPaymentService(db, gateway)
+
+	var created []uint
+	for i := 0; i < *bookingCount; i++ {
+		flight := flights[rng.Intn(len(flights))]
+		booking := models.Booking{
+			FlightID:      flight.ID,
+			PassengerName: passengerNames[rng.Intn(len(passengerNames))],
+			Quantity:      1 + rng.Intn(4),
+		}
+		for p := 0; p < booking.Quantity; p++ {
+			booking.Passengers = append(booking.Passengers, generatePassenger(rng, flight, p == 0))
+		}
+		if _, err := bookingService.CreateBooking(&booking); err != nil {
+			if errors.Is(err, service.ErrInsufficientSeats) {
+				continue // flight is full, try another one
+			}
+			log.Fatalf("failed to create booking on flight %d: %v", flight.ID, err)
+		}
+		created = append(created, booking.ID)
+		if booking.BookingStatus != service.BookingStatusConfirmed {
+			continue
+		}
+		if _, err := paymentService.PayBooking(booking.ID, "tok_seed"); err != nil {
+			log.Fatalf("failed to pay booking %d: %v", booking.ID, err)
+		}
+	}
+	if err := settleSeededBookings(db, created, seededAt); err != nil {
+		log.Fatalf("failed to settle seeded bookings: %v", err)
+	}
+	fmt.Printf("Inserted %d bookings\n", len(created))
+}
+
+// settleSeededBookings replaces the wall-clock times the booking and payment services
+// recorded with times derived from seededAt, and drops the bookings' notifications:
+// seeded customers are made up and must not be emailed once the server starts
+func settleSeededBookings(db *gorm.DB, ids []uint, seededAt time.Time) error {
+	const batchSize = 500
+	for len(ids) > 0 {
+		batch := ids[:min(batchSize, len(ids))]
+		ids = ids[len(batch):]
+
+		if err := db.Model(&models.Booking{}).Where("id IN ? AND paid_at IS NOT NULL", batch).
+			Update("paid_at", seededAt).Error; err != nil {
+			return err
+		}
+		if err := db.Model(&models.Booking{}).Where("id IN ? AND payment_deadline IS NOT NULL", batch).
+			Update("payment_deadline", seededAt.Add(service.DefaultPaymentWindow)).Error; err != nil {
+			return err
+		}
+		if err := db.Unscoped().Where("booking_id IN ?", batch).Delete(&models.OutboxEvent{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateFlight builds a random but realistic flight departing within the date range
+func generateFlight(rng *rand.Rand, start time.Time, days int) models.Flight {
+	r := routes[rng.Intn(len(routes))]
+	a := airlines[rng.Intn(len(airlines))]
+
+	// Departures between 06:00 and 22:55 local time in 5 minute steps
+	zone, err := refdata.Location(r.From)
+	if err != nil {
+		log.Fatalf("route %s-%s: %v", r.From, r.To, err)
+	}
+	day := start.AddDate(0, 0, rng.Intn(days))
+	minutes := 6*60 + rng.Intn(204)*5
+	departure := time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, zone)
+	arrival := departure.Add(r.Duration)
+
+	// Fares vary by +/-30% around the route's base fare, rounded to whole dollars
+	price := float64(int(r.Fare * (0.7 + rng.Float64()*0.6)))
+
+	seatOptions := []int{120, 150, 180, 220, 280}
+	seats := seatOptions[rng.Intn(len(seatOptions))]
+
+	// Roughly 80% economy, 12% premium and the rest business, like a typical two-aisle cabin
+	premium := seats * 12 / 100
+	business := seats * 8 / 100
+	economy := seats - premium - business
+
+	flight := models.Flight{
+		FlightNumber:     fmt.Sprintf("%s%d", a.Code, 100+rng.Intn(900)),
+		DepartureAirport: r.From,
+		ArrivalAirport:   r.To,
+		Airline:          a.Name,
+		Price:            price,
+		AvailableSeats:   seats,
+		Capacity:         seats,
+		FareClasses: []models.FareClass{
+			{Name: service.FareClassEconomy, Price: price, Capacity: economy, AvailableSeats: economy, OversellLimit: economy / 20},
+			{Name: service.FareClassPremium, Price: price * 2, Capacity: premium, AvailableSeats: premium},
+			{Name: service.FareClassBusiness, Price: price * 4, Capacity: business, AvailableSeats: business},
+		},
+		SeatMap: generateSeatMap(business, premium, economy),
+	}
+	if err := service.SetFlightTimes(&flight, departure, arrival); err != nil {
+		log.Fatalf("route %s-%s: %v", r.From, r.To, err)
+	}
+	return flight
+}
+
+// generateSeatMap lays the cabins out front to back in rows of six seats, with the
+// exit rows at the start of economy
+func generateSeatMap(business, premium, economy int) *models.SeatMap {
+	const columns = "ABCDEF"
+	seatMap := &models.SeatMap{Columns: columns}
+
+	row := 1
+	for _, cabin := range []struct {
+		name  string
+		seats int
+	}{
+		{service.FareClassBusiness, business},
+		{service.FareClassPremium, premium},
+		{service.FareClassEconomy, economy},
+	} {
+		rows := (cabin.seats + len(columns) - 1) / len(columns)
+		seatMap.Cabins = append(seatMap.Cabins, models.SeatMapCabin{Name: cabin.name, FirstRow: row, LastRow: row + rows - 1})
+		if cabin.name == service.FareClassEconomy {
+			seatMap.ExitRows = fmt.Sprintf("%d,%d", row, row+1)
+		}
+		row += rows
+	}
+	seatMap.Rows = row - 1
+	return seatMap
+}
+
+// generatePassenger builds a passenger whose date of birth matches their type at departure.
+// The lead passenger is always an adult.
+func generatePassenger(rng *rand.Rand, flight models.Flight, lead bool) models.Passenger {
+	departure, _ := time.Parse(service.FlightTimeLayout, flight.DepartureLocalTime)
+
+	passengerType := service.PassengerTypeAdult
+	if !lead {
+		switch n := rng.Intn(10); {
+		case n == 0:
+			passengerType = service.PassengerTypeInfant
+		case n < 3:
+			passengerType = service.PassengerTypeChild
+		}
+	}
+
+	var birth time.Time
+	switch passengerType {
+	case service.PassengerTypeInfant:
+		birth = departure.AddDate(0, 0, -(30 + rng.Intn(600)))
+	case service.PassengerTypeChild:
+		birth = departure.AddDate(-(3 + rng.Intn(8)), 0, -rng.Intn(300))
+	default:
+		birth = departure.AddDate(-(18 + rng.Intn(60)), 0, -rng.Intn(300))
+	}
+
+	return models.Passenger{
+		Name:           passengerNames[rng.Intn(len(passengerNames))],
+		DateOfBirth:    birth.Format("2006-01-02"),
+		DocumentNumber: fmt.Sprintf("P%08d", rng.Intn(100000000)),
+		PassengerType:  passengerType,
+	}
+}
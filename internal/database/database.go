@@ -1,24 +1,167 @@
 package database
 
 import (
-	"flight-booking/internal/models"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-// InitDB initializes the database connection and performs auto-migrations
-func InitDB() (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open("flights.db"), &gorm.Config{})
+// Supported database drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// Config selects the database and sizes its connection pool. Zero pool values keep
+// the database/sql defaults.
+type Config struct {
+	Driver          string
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectTimeout  time.Duration // how long InitDB waits for the database to answer
+}
+
+// DefaultConfig is the local SQLite file used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		Driver:         DriverSQLite,
+		DSN:            "flights.db",
+		ConnectTimeout: 5 * time.Second,
+	}
+}
+
+// ConfigFromEnv reads the configuration from DB_DRIVER, DB_DSN, DB_MAX_OPEN_CONNS,
+// DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME and
+// DB_CONNECT_TIMEOUT. Durations use Go syntax such as "30s" or "5m"; unset
+// variables keep DefaultConfig.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		cfg.Driver = driver
+		// The default file name only makes sense for SQLite
+		if driver != DriverSQLite {
+			cfg.DSN = ""
+		}
+	}
+	if dsn := os.Getenv("DB_DSN"); dsn != "" {
+		cfg.DSN = dsn
+	}
+
+	ints := map[string]*int{
+		"DB_MAX_OPEN_CONNS": &cfg.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS": &cfg.MaxIdleConns,
+	}
+	for name, target := range ints {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return Config{}, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+			}
+			*target = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"DB_CONN_MAX_LIFETIME":  &cfg.ConnMaxLifetime,
+		"DB_CONN_MAX_IDLE_TIME": &cfg.ConnMaxIdleTime,
+		"DB_CONNECT_TIMEOUT":    &cfg.ConnectTimeout,
+	}
+	for name, target := range durations {
+		if value := os.Getenv(name); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return Config{}, fmt.Errorf("%s must be a duration such as 30s, got %q", name, value)
+			}
+			*target = d
+		}
+	}
+
+	return cfg, nil
+}
+
+// Open connects to the configured database and applies the pool settings
+func Open(cfg Config) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Migrate the schema and create indexes
-	err = db.AutoMigrate(&models.Flight{}, &models.Booking{})
+	db, err := gorm.Open(dialector, &gorm.Config{
+		// Translate driver errors such as unique violations into gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// Fail at startup rather than on the first request when the database is unreachable
+	ctx := context.Background()
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to reach %s database: %w", cfg.Driver, err)
+	}
+
+	return db, nil
+}
+
+// dialectorFor returns the GORM dialector for the configured driver
+func dialectorFor(cfg Config) (gorm.Dialector, error) {
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("DB_DSN is required for the %s driver", cfg.Driver)
+	}
+	switch cfg.Driver {
+	case DriverSQLite:
+		return sqlite.Open(cfg.DSN), nil
+	case DriverPostgres:
+		return postgres.Open(cfg.DSN), nil
+	case DriverMySQL:
+		// MySQL cannot index TEXT columns, so strings become VARCHAR(191), which
+		// fits a utf8mb4 index; long fields are tagged type:text in the models
+		return mysql.New(mysql.Config{DSN: cfg.DSN, DefaultStringSize: 191}), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q: use %s, %s or %s", cfg.Driver, DriverSQLite, DriverPostgres, DriverMySQL)
+}
+
+// InitDB connects to the configured database and refuses to continue unless every
+// migration has been applied; the schema is changed only by `migrate up`
+func InitDB(cfg Config) (*gorm.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckSchema(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
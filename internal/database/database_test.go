@@ -0,0 +1,99 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigFromEnv tests reading the driver, DSN and pool settings from the environment
+func TestConfigFromEnv(t *testing.T) {
+	// Given
+	t.Setenv("DB_DRIVER", DriverPostgres)
+	t.Setenv("DB_DSN", "host=localhost user=flights dbname=flights")
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("DB_MAX_IDLE_CONNS", "5")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "30m")
+	t.Setenv("DB_CONNECT_TIMEOUT", "2s")
+
+	// When
+	cfg, err := ConfigFromEnv()
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Driver:          DriverPostgres,
+		DSN:             "host=localhost user=flights dbname=flights",
+		MaxOpenConns:    20,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnectTimeout:  2 * time.Second,
+	}, cfg)
+}
+
+// TestConfigFromEnv_Defaults tests that an empty environment uses the local SQLite file
+func TestConfigFromEnv_Defaults(t *testing.T) {
+	for _, name := range []string{"DB_DRIVER", "DB_DSN", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS",
+		"DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME", "DB_CONNECT_TIMEOUT"} {
+		t.Setenv(name, "")
+	}
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), cfg)
+}
+
+// TestConfigFromEnv_Invalid tests that malformed pool settings are rejected
+func TestConfigFromEnv_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"DB_MAX_OPEN_CONNS":    "many",
+		"DB_MAX_IDLE_CONNS":    "-1",
+		"DB_CONN_MAX_LIFETIME": "30",
+		"DB_CONNECT_TIMEOUT":   "-5s",
+	}
+	for name, value := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := ConfigFromEnv()
+			assert.ErrorContains(t, err, name)
+		})
+	}
+}
+
+// TestOpen_InvalidDriver tests that an unknown driver or a missing DSN fails before connecting
+func TestOpen_InvalidDriver(t *testing.T) {
+	_, err := Open(Config{Driver: "oracle", DSN: "flights"})
+	assert.ErrorContains(t, err, "unsupported database driver")
+
+	_, err = Open(Config{Driver: DriverMySQL})
+	assert.ErrorContains(t, err, "DB_DSN is required")
+}
+
+// TestInitDB_SQLite tests that the SQLite driver connects, applies the pool size and
+// refuses to start until the schema is migrated
+func TestInitDB_SQLite(t *testing.T) {
+	// Given
+	cfg := Config{Driver: DriverSQLite, DSN: filepath.Join(t.TempDir(), "flights.db"), MaxOpenConns: 2, ConnectTimeout: time.Second}
+
+	// When: the database was never migrated
+	_, err := InitDB(cfg)
+
+	// Then
+	assert.ErrorIs(t, err, ErrSchemaOutdated)
+
+	// After migrating it starts
+	db, err := Open(cfg)
+	require.NoError(t, err)
+	_, err = MigrateUp(db)
+	require.NoError(t, err)
+
+	db, err = InitDB(cfg)
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	assert.Equal(t, 2, sqlDB.Stats().MaxOpenConnections)
+}
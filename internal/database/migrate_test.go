@@ -0,0 +1,325 @@
+package database
+
+import (
+	"flight-booking/internal/models"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// schemaModels is every model the application stores
+var schemaModels = []interface{}{
+	&models.Flight{},
+	&models.FareClass{},
+	&models.OversellRule{},
+	&models.Booking{},
+	&models.Passenger{},
+	&models.SeatMap{},
+	&models.SeatMapCabin{},
+	&models.SeatAssignment{},
+	&models.Order{},
+	&models.SeatHold{},
+	&models.Refund{},
+	&models.OutboxEvent{},
+	&models.WebhookSubscription{},
+	&models.WebhookDelivery{},
+	&models.Airport{},
+	&models.Airline{},
+	&models.Schedule{},
+	&models.FlightStatusEvent{},
+}
+
+// openTestDB opens an empty in-memory database
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := Open(Config{Driver: DriverSQLite, DSN: ":memory:", MaxOpenConns: 1})
+	require.NoError(t, err)
+	return db
+}
+
+// TestMigrations_Versions tests that migrations are numbered 1, 2, 3, ... and reversible
+func TestMigrations_Versions(t *testing.T) {
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration %q", m.Name)
+		assert.NotEmpty(t, m.Name)
+		assert.NotNil(t, m.Up, "migration %04d", m.Version)
+		assert.NotNil(t, m.Down, "migration %04d", m.Version)
+	}
+}
+
+// TestMigrateUp_MatchesModels tests that the migrated schema has every table, column and
+// index of the models, so a model change without a migration is caught
+func TestMigrateUp_MatchesModels(t *testing.T) {
+	// Given
+	db := openTestDB(t)
+
+	// When
+	applied, err := MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+
+	for _, model := range schemaModels {
+		s, err := schema.Parse(model, &sync.Map{}, db.NamingStrategy)
+		require.NoError(t, err)
+
+		require.True(t, db.Migrator().HasTable(s.Table), "table %s", s.Table)
+		for _, name := range s.DBNames {
+			assert.True(t, db.Migrator().HasColumn(model, name), "column %s.%s", s.Table, name)
+		}
+		for _, index := range s.ParseIndexes() {
+			assert.True(t, db.Migrator().HasIndex(model, index.Name), "index %s on %s", index.Name, s.Table)
+		}
+	}
+}
+
+// TestMigrate_UpDownStatus tests applying, reporting and reverting migrations
+func TestMigrate_UpDownStatus(t *testing.T) {
+	// Given
+	db := openTestDB(t)
+
+	statuses, err := Status(db)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(migrations))
+	assert.Nil(t, statuses[0].AppliedAt)
+	err = CheckSchema(db)
+	assert.ErrorIs(t, err, ErrSchemaOutdated)
+	assert.ErrorContains(t, err, fmt.Sprintf("%d pending", len(migrations)))
+	assert.False(t, db.Migrator().HasTable(&SchemaMigration{}), "checking the schema must not change it")
+
+	// When
+	_, err = MigrateUp(db)
+	require.NoError(t, err)
+
+	// Then
+	require.NoError(t, CheckSchema(db))
+	statuses, err = Status(db)
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[len(statuses)-1].AppliedAt)
+
+	// Running up again is a no-op
+	applied, err := MigrateUp(db)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// Reverting everything drops the tables
+	reverted, err := MigrateDown(db, len(migrations))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(migrations))
+	assert.Equal(t, migrations[len(migrations)-1].Version, reverted[0].Version, "newest first")
+	assert.False(t, db.Migrator().HasTable(&models.Flight{}))
+	assert.ErrorIs(t, CheckSchema(db), ErrSchemaOutdated)
+}
+
+// TestMigrateUp_AdoptsExistingSchema tests that a database created by AutoMigrate before
+// migrations existed is adopted without losing data
+func TestMigrateUp_AdoptsExistingSchema(t *testing.T) {
+	// Given
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(baselineTables()...))
+	require.NoError(t, db.Exec(`INSERT INTO flights (flight_number, departure_airport, arrival_airport, departure_time, arrival_time)
+		VALUES ('BR198', 'TPE', 'NRT', '2025-08-01 08:50', '2025-08-01 13:15')`).Error)
+
+	// When
+	_, err := MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, CheckSchema(db))
+	var count int64
+	require.NoError(t, db.Model(&models.Flight{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestCheckSchema_NewerDatabase tests that a database migrated by a newer build is refused
+func TestCheckSchema_NewerDatabase(t *testing.T) {
+	db := openTestDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Create(&SchemaMigration{Version: len(migrations) + 1, Name: "from the future"}).Error)
+
+	err = CheckSchema(db)
+	assert.ErrorIs(t, err, ErrSchemaOutdated)
+	assert.ErrorContains(t, err, "newer build")
+}
+
+// TestMigration0002_FlightTimesUTC tests that string flight times become UTC times with
+// time zones, local times and durations, and are restored when reverted
+func TestMigration0002_FlightTimesUTC(t *testing.T) {
+	// Given a database at the baseline with string times, read as local times at each airport
+	db := openTestDB(t)
+	require.NoError(t, db.AutoMigrate(baselineTables()...))
+	require.NoError(t, db.AutoMigrate(&SchemaMigration{}))
+	require.NoError(t, db.Create(&SchemaMigration{Version: 1, Name: "baseline"}).Error)
+	require.NoError(t, db.Exec(`INSERT INTO flights (id, flight_number, departure_airport, arrival_airport, departure_time, arrival_time) VALUES
+		(1, 'BR198', 'TPE', 'NRT', '2025-08-01 08:50', '2025-08-01 13:15'),
+		(2, 'SQ877', 'SIN', 'TPE', '2025-08-01 23:30', '2025-08-02 04:10'),
+		(3, 'XX001', 'ZZZ', 'TPE', '2025-08-01 10:00', '2025-08-01 12:00'),
+		(4, 'JL802', 'TPE', 'NRT', '2025-08-01 10:00', '2025-08-01 10:30')`).Error)
+
+	// When
+	applied, err := MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 2, applied[0].Version)
+
+	var flights []models.Flight
+	require.NoError(t, db.Order("id").Find(&flights).Error)
+	require.Len(t, flights, 4)
+
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 50, 0, 0, time.UTC), flights[0].DepartureTime.UTC())
+	assert.Equal(t, time.Date(2025, 8, 1, 4, 15, 0, 0, time.UTC), flights[0].ArrivalTime.UTC())
+	assert.Equal(t, "2025-08-01 08:50", flights[0].DepartureLocalTime)
+	assert.Equal(t, "Asia/Taipei", flights[0].DepartureTimeZone)
+	assert.Equal(t, "Asia/Tokyo", flights[0].ArrivalTimeZone)
+	assert.Equal(t, 205, flights[0].DurationMinutes)
+
+	// Same offset at both ends
+	assert.Equal(t, 280, flights[1].DurationMinutes)
+
+	// Unknown airports fall back to UTC
+	assert.Equal(t, "UTC", flights[2].DepartureTimeZone)
+	assert.Equal(t, time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC), flights[2].DepartureTime.UTC())
+
+	// An arrival that would come first in its own zone was recorded in the departure zone
+	assert.Equal(t, 30, flights[3].DurationMinutes)
+	assert.Equal(t, "2025-08-01 11:30", flights[3].ArrivalLocalTime)
+
+	// Later migrations fill in the new columns of existing flights
+	assert.Equal(t, "Scheduled", flights[0].Status)
+
+	// When reverted to the baseline
+	_, err = MigrateDown(db, len(applied))
+	require.NoError(t, err)
+
+	// Then the strings hold the local times again
+	var departure, arrival string
+	require.NoError(t, db.Raw("SELECT departure_time, arrival_time FROM flights WHERE id = 1").Row().Scan(&departure, &arrival))
+	assert.Equal(t, "2025-08-01 08:50", departure)
+	assert.Equal(t, "2025-08-01 13:15", arrival)
+	assert.False(t, db.Migrator().HasColumn("flights", "departure_local_time"))
+	assert.True(t, db.Migrator().HasIndex("flights", "idx_flight_search"))
+}
+
+// TestMigration0006_RefundRetries tests that refunds still waiting for the gateway are due
+// as soon as the retry columns are added
+func TestMigration0006_RefundRetries(t *testing.T) {
+	// Given a refund that failed before retries were tracked
+	db := openTestDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	_, err = MigrateDown(db, len(migrations)-5)
+	require.NoError(t, err)
+	require.False(t, db.Migrator().HasColumn("refunds", "next_attempt_at"))
+	require.NoError(t, db.Exec(`INSERT INTO refunds (id, created_at, updated_at, booking_id, amount, refund_status)
+		VALUES (1, '2025-08-01 10:00:00', '2025-08-01 10:05:00', 1, 400, 'Failed')`).Error)
+
+	// When
+	applied, err := MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 6, applied[0].Version)
+
+	var refund models.Refund
+	require.NoError(t, db.First(&refund, 1).Error)
+	assert.Zero(t, refund.Attempts)
+	assert.Equal(t, time.Date(2025, 8, 1, 10, 5, 0, 0, time.UTC), refund.NextAttemptAt.UTC())
+}
+
+// TestMigration0007_RecordLocators tests that bookings made before record locators
+// existed each get a distinct one
+func TestMigration0007_RecordLocators(t *testing.T) {
+	// Given two bookings without a locator and one with
+	db := openTestDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	_, err = MigrateDown(db, len(migrations)-6)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`INSERT INTO bookings (id, flight_id, passenger_name, quantity, booking_status, record_locator) VALUES
+		(1, 1, 'A', 1, 'Confirmed', NULL),
+		(2, 1, 'B', 1, 'Confirmed', NULL),
+		(3, 1, 'C', 1, 'Confirmed', 'ABC234')`).Error)
+
+	// When
+	_, err = MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	var bookings []models.Booking
+	require.NoError(t, db.Order("id").Find(&bookings).Error)
+	require.Len(t, bookings, 3)
+	assert.Regexp(t, `^[A-HJ-NP-Z2-9]{6}$`, bookings[0].RecordLocator)
+	assert.Regexp(t, `^[A-HJ-NP-Z2-9]{6}$`, bookings[1].RecordLocator)
+	assert.NotEqual(t, bookings[0].RecordLocator, bookings[1].RecordLocator)
+	assert.Equal(t, "ABC234", bookings[2].RecordLocator)
+}
+
+// TestMigration0008_FlightCapacity tests that a flight's capacity counts its available
+// seats plus the seats of active and disrupted bookings and of active holds
+func TestMigration0008_FlightCapacity(t *testing.T) {
+	// Given
+	db := openTestDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	_, err = MigrateDown(db, len(migrations)-7)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`INSERT INTO flights (id, flight_number, available_seats, capacity) VALUES
+		(1, 'BR198', 5, 0), (2, 'BR199', 5, 180)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO bookings (flight_id, passenger_name, quantity, booking_status, record_locator) VALUES
+		(1, 'A', 3, 'Confirmed', 'AAAAAA'),
+		(1, 'B', 2, 'Disrupted', 'BBBBBB'),
+		(1, 'C', 1, 'Waitlisted', 'CCCCCC'),
+		(1, 'D', 4, 'Cancelled', 'DDDDDD')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO seat_holds (flight_id, token, quantity, hold_status) VALUES
+		(1, 'active', 2, 'Active'), (1, 'expired', 3, 'Expired')`).Error)
+
+	// When
+	_, err = MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	var flights []models.Flight
+	require.NoError(t, db.Order("id").Find(&flights).Error)
+	require.Len(t, flights, 2)
+	assert.Equal(t, 13, flights[0].Capacity)
+	assert.Equal(t, 180, flights[1].Capacity, "a known capacity is kept")
+}
+
+// TestMigration0010_OrderStatus tests that orders take their status from their bookings
+func TestMigration0010_OrderStatus(t *testing.T) {
+	// Given orders still showing the status they were made with
+	db := openTestDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	_, err = MigrateDown(db, len(migrations)-9)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`INSERT INTO orders (id, passenger_name, quantity, order_status) VALUES
+		(1, 'A', 1, 'Waitlisted'), (2, 'B', 1, 'Confirmed'), (3, 'C', 1, 'Confirmed')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO bookings (flight_id, order_id, passenger_name, quantity, booking_status, record_locator) VALUES
+		(1, 1, 'A', 1, 'Confirmed', 'AAAAAA'),
+		(2, 1, 'A', 1, 'Confirmed', 'AAAAAB'),
+		(1, 2, 'B', 1, 'Confirmed', 'BBBBBA'),
+		(2, 2, 'B', 1, 'Cancelled', 'BBBBBB'),
+		(1, 3, 'C', 1, 'Cancelled', 'CCCCCC')`).Error)
+
+	// When
+	_, err = MigrateUp(db)
+
+	// Then
+	require.NoError(t, err)
+	var orders []models.Order
+	require.NoError(t, db.Order("id").Find(&orders).Error)
+	require.Len(t, orders, 3)
+	assert.Equal(t, "Confirmed", orders[0].OrderStatus)
+	assert.Equal(t, "PartiallyCancelled", orders[1].OrderStatus)
+	assert.Equal(t, "Cancelled", orders[2].OrderStatus)
+}
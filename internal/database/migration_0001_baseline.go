@@ -0,0 +1,194 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration0001Baseline creates the schema as it was when versioned migrations were
+// introduced. The tables are copies of the models at that point, so later model
+// changes do not alter what this migration does. It uses AutoMigrate so databases
+// created before migrations existed are adopted instead of failing on existing tables.
+var migration0001Baseline = Migration{
+	Version: 1,
+	Name:    "baseline",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(baselineTables()...)
+	},
+	Down: func(tx *gorm.DB) error {
+		tables := baselineTables()
+		for i := len(tables) - 1; i >= 0; i-- {
+			if err := tx.Migrator().DropTable(tables[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// baselineTables returns the baseline tables, referenced tables first
+func baselineTables() []interface{} {
+	type FareClass struct {
+		gorm.Model
+		FlightID       uint   `gorm:"uniqueIndex:idx_fare_class"`
+		Name           string `gorm:"uniqueIndex:idx_fare_class"`
+		Price          float64
+		Capacity       int
+		AvailableSeats int
+		OversellLimit  int
+	}
+	type SeatMapCabin struct {
+		gorm.Model
+		SeatMapID uint `gorm:"index"`
+		Name      string
+		FirstRow  int
+		LastRow   int
+	}
+	type SeatMap struct {
+		gorm.Model
+		FlightID     uint `gorm:"uniqueIndex"`
+		Rows         int
+		Columns      string
+		Cabins       []SeatMapCabin
+		ExitRows     string
+		BlockedSeats string `gorm:"type:text"`
+	}
+	type Flight struct {
+		gorm.Model
+		FlightNumber     string `gorm:"index"`
+		DepartureAirport string `gorm:"index:idx_flight_search"`
+		ArrivalAirport   string `gorm:"index:idx_flight_search"`
+		DepartureTime    string `gorm:"index:idx_flight_search"`
+		ArrivalTime      string
+		Airline          string  `gorm:"index"`
+		Price            float64 `gorm:"index"`
+		AvailableSeats   int
+		Capacity         int
+		RefundRule       string `gorm:"default:Standard"`
+		OversellPolicy   string
+		OversellValue    float64
+		FareClasses      []FareClass
+		SeatMap          *SeatMap
+		Version          int `gorm:"not null;default:0"`
+	}
+	type OversellRule struct {
+		gorm.Model
+		Airline          string `gorm:"index"`
+		DepartureAirport string
+		ArrivalAirport   string
+		Policy           string
+		Value            float64
+	}
+	type Passenger struct {
+		gorm.Model
+		BookingID      uint `gorm:"index"`
+		Name           string
+		DateOfBirth    string
+		DocumentNumber string
+		PassengerType  string
+		Fare           float64
+		Seat           string
+	}
+	type Refund struct {
+		gorm.Model
+		BookingID        uint `gorm:"index"`
+		RefundRule       string
+		Amount           float64
+		Fee              float64
+		RefundStatus     string `gorm:"index"`
+		GatewayReference string
+	}
+	type Booking struct {
+		gorm.Model
+		RecordLocator    string `gorm:"size:6;uniqueIndex"`
+		FlightID         uint   `gorm:"index:idx_booking_search"`
+		FareClass        string
+		OrderID          *uint  `gorm:"index"`
+		PassengerName    string `gorm:"index:idx_booking_search"`
+		ContactEmail     string
+		Quantity         int
+		TotalPrice       float64
+		BookingStatus    string `gorm:"index"`
+		Passengers       []Passenger
+		PaymentStatus    string     `gorm:"index:idx_booking_payment"`
+		PaymentDeadline  *time.Time `gorm:"index:idx_booking_payment"`
+		PaymentReference string
+		PaidAt           *time.Time
+		Refunds          []Refund
+		NotificationSent bool
+	}
+	type SeatAssignment struct {
+		gorm.Model
+		FlightID    uint   `gorm:"uniqueIndex:idx_seat_assignment"`
+		Seat        string `gorm:"size:4;uniqueIndex:idx_seat_assignment"`
+		BookingID   uint   `gorm:"index"`
+		PassengerID uint   `gorm:"uniqueIndex"`
+	}
+	type Order struct {
+		gorm.Model
+		PassengerName string
+		Quantity      int
+		TotalPrice    float64
+		OrderStatus   string `gorm:"index"`
+		Bookings      []Booking
+	}
+	type SeatHold struct {
+		gorm.Model
+		Token      string `gorm:"size:32;uniqueIndex"`
+		FlightID   uint   `gorm:"index"`
+		FareClass  string
+		Quantity   int
+		HoldStatus string    `gorm:"index:idx_hold_expiry"`
+		ExpiresAt  time.Time `gorm:"index:idx_hold_expiry"`
+		BookingID  *uint
+	}
+	type OutboxEvent struct {
+		gorm.Model
+		EventType     string
+		BookingID     uint   `gorm:"index"`
+		Payload       string `gorm:"type:text"`
+		OutboxStatus  string `gorm:"index:idx_outbox_due"`
+		Attempts      int
+		NextAttemptAt time.Time `gorm:"index:idx_outbox_due"`
+		LastError     string    `gorm:"type:text"`
+		DeliveredAt   *time.Time
+	}
+	type WebhookSubscription struct {
+		gorm.Model
+		URL        string `gorm:"type:text"`
+		Secret     string
+		EventTypes string `gorm:"type:text"`
+		Active     bool   `gorm:"index"`
+	}
+	type WebhookDelivery struct {
+		gorm.Model
+		SubscriptionID uint `gorm:"index"`
+		OutboxEventID  uint
+		EventType      string
+		Payload        string `gorm:"type:text"`
+		DeliveryStatus string `gorm:"index:idx_webhook_due"`
+		Attempts       int
+		NextAttemptAt  time.Time `gorm:"index:idx_webhook_due"`
+		ResponseStatus int
+		LastError      string `gorm:"type:text"`
+		DeliveredAt    *time.Time
+	}
+
+	return []interface{}{
+		&Flight{},
+		&FareClass{},
+		&OversellRule{},
+		&Order{},
+		&Booking{},
+		&Passenger{},
+		&SeatMap{},
+		&SeatMapCabin{},
+		&SeatAssignment{},
+		&SeatHold{},
+		&Refund{},
+		&OutboxEvent{},
+		&WebhookSubscription{},
+		&WebhookDelivery{},
+	}
+}
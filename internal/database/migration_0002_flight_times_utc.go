@@ -0,0 +1,250 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the IANA database so time zones resolve on hosts without zoneinfo files
+	_ "time/tzdata"
+
+	"gorm.io/gorm"
+)
+
+// legacyFlightTimeLayout is the layout flight times were stored in before migration 0002
+const legacyFlightTimeLayout = "2006-01-02 15:04"
+
+// migration0002FlightTimesUTC turns the departure_time and arrival_time strings into
+// UTC timestamps and adds the airports' time zones, the local times and the duration.
+// The strings had no zone; they are read as local times at their airports.
+var migration0002FlightTimesUTC = Migration{
+	Version: 2,
+	Name:    "flight_times_utc",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		// The search index moves from departure_time to departure_local_time
+		if err := m.DropIndex("flights", "idx_flight_search"); err != nil {
+			return err
+		}
+		for _, column := range []string{"departure_time", "arrival_time"} {
+			if err := m.RenameColumn("flights", column, "legacy_"+column); err != nil {
+				return err
+			}
+		}
+		if err := tx.AutoMigrate(flightTimesUTCTable()); err != nil {
+			return err
+		}
+		if err := convertLegacyFlightTimes(tx); err != nil {
+			return err
+		}
+		return dropColumns(tx, "flights", "legacy_departure_time", "legacy_arrival_time")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, index := range []string{"idx_flight_search", "idx_flights_departure_time"} {
+			if err := m.DropIndex("flights", index); err != nil {
+				return err
+			}
+		}
+		if err := dropColumns(tx, "flights", "departure_time", "arrival_time"); err != nil {
+			return err
+		}
+		// The local times are what the strings held before
+		if err := tx.AutoMigrate(flightTimesStringTable()); err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE flights SET departure_time = departure_local_time, arrival_time = arrival_local_time").Error; err != nil {
+			return err
+		}
+		return dropColumns(tx, "flights",
+			"departure_local_time", "arrival_local_time", "departure_time_zone", "arrival_time_zone", "duration_minutes")
+	},
+}
+
+// flightTimesStringTable is the flights table with string times, as in the baseline
+func flightTimesStringTable() interface{} {
+	type Flight struct {
+		gorm.Model
+		FlightNumber     string `gorm:"index"`
+		DepartureAirport string `gorm:"index:idx_flight_search"`
+		ArrivalAirport   string `gorm:"index:idx_flight_search"`
+		DepartureTime    string `gorm:"index:idx_flight_search"`
+		ArrivalTime      string
+		Airline          string  `gorm:"index"`
+		Price            float64 `gorm:"index"`
+		AvailableSeats   int
+		Capacity         int
+		RefundRule       string `gorm:"default:Standard"`
+		OversellPolicy   string
+		OversellValue    float64
+		Version          int `gorm:"not null;default:0"`
+	}
+	return &Flight{}
+}
+
+// flightTimesUTCTable is the flights table with UTC times and derived local times
+func flightTimesUTCTable() interface{} {
+	type Flight struct {
+		gorm.Model
+		FlightNumber       string    `gorm:"index"`
+		DepartureAirport   string    `gorm:"index:idx_flight_search"`
+		ArrivalAirport     string    `gorm:"index:idx_flight_search"`
+		DepartureTime      time.Time `gorm:"index"`
+		ArrivalTime        time.Time
+		DepartureLocalTime string `gorm:"index:idx_flight_search"`
+		ArrivalLocalTime   string
+		DepartureTimeZone  string
+		ArrivalTimeZone    string
+		DurationMinutes    int
+		Airline            string  `gorm:"index"`
+		Price              float64 `gorm:"index"`
+		AvailableSeats     int
+		Capacity           int
+		RefundRule         string `gorm:"default:Standard"`
+		OversellPolicy     string
+		OversellValue      float64
+		Version            int `gorm:"not null;default:0"`
+	}
+	return &Flight{}
+}
+
+// convertLegacyFlightTimes fills the new time columns of every flight, deleted ones
+// included, from its legacy strings
+func convertLegacyFlightTimes(tx *gorm.DB) error {
+	type legacyFlight struct {
+		ID                  uint
+		DepartureAirport    string
+		ArrivalAirport      string
+		LegacyDepartureTime string
+		LegacyArrivalTime   string
+	}
+
+	var batch []legacyFlight
+	return tx.Table("flights").
+		Select("id", "departure_airport", "arrival_airport", "legacy_departure_time", "legacy_arrival_time").
+		FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+			for _, f := range batch {
+				departureZone, arrivalZone := legacyAirportZone(f.DepartureAirport), legacyAirportZone(f.ArrivalAirport)
+
+				departure, err := time.ParseInLocation(legacyFlightTimeLayout, f.LegacyDepartureTime, departureZone)
+				if err != nil {
+					return fmt.Errorf("flight %d has an invalid departure_time %q", f.ID, f.LegacyDepartureTime)
+				}
+				arrival, err := time.ParseInLocation(legacyFlightTimeLayout, f.LegacyArrivalTime, arrivalZone)
+				if err != nil {
+					return fmt.Errorf("flight %d has an invalid arrival_time %q", f.ID, f.LegacyArrivalTime)
+				}
+				// Some rows recorded the arrival in the departure airport's zone; read that
+				// way the flight arrives after it departs, as it did when it was validated
+				if !arrival.After(departure) {
+					arrival, _ = time.ParseInLocation(legacyFlightTimeLayout, f.LegacyArrivalTime, departureZone)
+				}
+
+				if err := tx.Table("flights").Where("id = ?", f.ID).Updates(map[string]interface{}{
+					"departure_time":       departure.UTC(),
+					"arrival_time":         arrival.UTC(),
+					"departure_local_time": departure.In(departureZone).Format(legacyFlightTimeLayout),
+					"arrival_local_time":   arrival.In(arrivalZone).Format(legacyFlightTimeLayout),
+					"departure_time_zone":  departureZone.String(),
+					"arrival_time_zone":    arrivalZone.String(),
+					"duration_minutes":     int(arrival.Sub(departure).Minutes()),
+				}).Error; err != nil {
+					return fmt.Errorf("failed to convert flight %d: %w", f.ID, err)
+				}
+			}
+			return nil
+		}).Error
+}
+
+// legacyAirportTimeZones maps IATA airport codes to their IANA time zone as they were
+// when this migration was released. It is kept here rather than read from the bundled
+// reference data so that later data updates do not change what the migration does.
+var legacyAirportTimeZones = map[string]string{
+	// Taiwan
+	"TPE": "Asia/Taipei",
+	"TSA": "Asia/Taipei",
+	"KHH": "Asia/Taipei",
+	"RMQ": "Asia/Taipei",
+	// Japan
+	"NRT": "Asia/Tokyo",
+	"HND": "Asia/Tokyo",
+	"KIX": "Asia/Tokyo",
+	"ITM": "Asia/Tokyo",
+	"NGO": "Asia/Tokyo",
+	"CTS": "Asia/Tokyo",
+	"FUK": "Asia/Tokyo",
+	"OKA": "Asia/Tokyo",
+	// Korea
+	"ICN": "Asia/Seoul",
+	"GMP": "Asia/Seoul",
+	"PUS": "Asia/Seoul",
+	"CJU": "Asia/Seoul",
+	// China, Hong Kong and Macau
+	"PEK": "Asia/Shanghai",
+	"PKX": "Asia/Shanghai",
+	"PVG": "Asia/Shanghai",
+	"SHA": "Asia/Shanghai",
+	"CAN": "Asia/Shanghai",
+	"SZX": "Asia/Shanghai",
+	"HKG": "Asia/Hong_Kong",
+	"MFM": "Asia/Macau",
+	// Southeast Asia
+	"SIN": "Asia/Singapore",
+	"BKK": "Asia/Bangkok",
+	"DMK": "Asia/Bangkok",
+	"KUL": "Asia/Kuala_Lumpur",
+	"MNL": "Asia/Manila",
+	"CGK": "Asia/Jakarta",
+	"DPS": "Asia/Makassar",
+	"SGN": "Asia/Ho_Chi_Minh",
+	"HAN": "Asia/Ho_Chi_Minh",
+	// South Asia and the Middle East
+	"DEL": "Asia/Kolkata",
+	"BOM": "Asia/Kolkata",
+	"DXB": "Asia/Dubai",
+	"DOH": "Asia/Qatar",
+	// Oceania
+	"SYD": "Australia/Sydney",
+	"MEL": "Australia/Melbourne",
+	"BNE": "Australia/Brisbane",
+	"AKL": "Pacific/Auckland",
+	"HNL": "Pacific/Honolulu",
+	// Europe
+	"LHR": "Europe/London",
+	"CDG": "Europe/Paris",
+	"FRA": "Europe/Berlin",
+	"AMS": "Europe/Amsterdam",
+	"IST": "Europe/Istanbul",
+	// North America
+	"LAX": "America/Los_Angeles",
+	"SFO": "America/Los_Angeles",
+	"SEA": "America/Los_Angeles",
+	"YVR": "America/Vancouver",
+	"ORD": "America/Chicago",
+	"JFK": "America/New_York",
+	"EWR": "America/New_York",
+	"YYZ": "America/Toronto",
+}
+
+// legacyAirportZone returns the airport's time zone, or UTC for airports without one
+func legacyAirportZone(code string) *time.Location {
+	name, ok := legacyAirportTimeZones[code]
+	if !ok {
+		return time.UTC
+	}
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return zone
+}
+
+// dropColumns drops columns with plain SQL, which every supported database accepts.
+// GORM's SQLite migrator would copy the whole table instead and lose its indexes.
+func dropColumns(tx *gorm.DB, table string, columns ...string) error {
+	for _, column := range columns {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)).Error; err != nil {
+			return fmt.Errorf("failed to drop %s.%s: %w", table, column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package database
+
+import "gorm.io/gorm"
+
+// migration0003ReferenceData creates the airports and airlines tables. They are
+// filled at startup from the bundled CSV files, so updated data ships with a release
+// instead of a migration.
+var migration0003ReferenceData = Migration{
+	Version: 3,
+	Name:    "reference_data",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(referenceDataTables()...)
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(referenceDataTables()...)
+	},
+}
+
+// referenceDataTables returns the airports and airlines tables
+func referenceDataTables() []interface{} {
+	type Airport struct {
+		gorm.Model
+		Code      string `gorm:"size:3;uniqueIndex"`
+		Name      string
+		City      string
+		Country   string
+		TimeZone  string
+		Latitude  float64
+		Longitude float64
+	}
+	type Airline struct {
+		gorm.Model
+		IATACode string `gorm:"size:2;uniqueIndex"`
+		ICAOCode string `gorm:"size:3;uniqueIndex"`
+		Name     string `gorm:"uniqueIndex"`
+	}
+	return []interface{}{&Airport{}, &Airline{}}
+}
@@ -0,0 +1,52 @@
+package database
+
+import "gorm.io/gorm"
+
+// migration0004Schedules creates the schedules table and links flights to the
+// schedule and date they were generated for
+var migration0004Schedules = Migration{
+	Version: 4,
+	Name:    "schedules",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(schedulesTable(), flightScheduleColumns())
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex("flights", "idx_flight_schedule"); err != nil {
+			return err
+		}
+		if err := dropColumns(tx, "flights", "schedule_id", "schedule_date"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable(schedulesTable())
+	},
+}
+
+// schedulesTable is the schedules table
+func schedulesTable() interface{} {
+	type Schedule struct {
+		gorm.Model
+		FlightNumber     string `gorm:"index"`
+		Airline          string
+		DepartureAirport string
+		ArrivalAirport   string
+		DaysOfWeek       string
+		DepartureTime    string
+		DurationMinutes  int
+		EffectiveFrom    string
+		EffectiveTo      string
+		Capacity         int
+		Price            float64
+		RefundRule       string `gorm:"default:Standard"`
+	}
+	return &Schedule{}
+}
+
+// flightScheduleColumns holds only the columns this migration adds to flights;
+// AutoMigrate adds them and leaves the rest of the table alone
+func flightScheduleColumns() interface{} {
+	type Flight struct {
+		ScheduleID   *uint  `gorm:"uniqueIndex:idx_flight_schedule"`
+		ScheduleDate string `gorm:"size:10;uniqueIndex:idx_flight_schedule"`
+	}
+	return &Flight{}
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration0005FlightStatus adds the operational status and estimated times to
+// flights, and the table that keeps their history. Existing flights start Scheduled.
+var migration0005FlightStatus = Migration{
+	Version: 5,
+	Name:    "flight_status",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(flightStatusColumns(), flightStatusEventsTable())
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropTable(flightStatusEventsTable()); err != nil {
+			return err
+		}
+		return dropColumns(tx, "flights", "status", "estimated_departure_time", "estimated_arrival_time",
+			"estimated_departure_local_time", "estimated_arrival_local_time")
+	},
+}
+
+// flightStatusColumns holds only the columns this migration adds to flights
+func flightStatusColumns() interface{} {
+	type Flight struct {
+		Status                      string `gorm:"default:Scheduled"`
+		EstimatedDepartureTime      *time.Time
+		EstimatedArrivalTime        *time.Time
+		EstimatedDepartureLocalTime string
+		EstimatedArrivalLocalTime   string
+	}
+	return &Flight{}
+}
+
+// flightStatusEventsTable is the flight_status_events table
+func flightStatusEventsTable() interface{} {
+	type FlightStatusEvent struct {
+		gorm.Model
+		FlightID               uint `gorm:"index"`
+		Status                 string
+		EstimatedDepartureTime *time.Time
+		EstimatedArrivalTime   *time.Time
+		Reason                 string
+	}
+	return &FlightStatusEvent{}
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration0006RefundRetries adds the retry bookkeeping to refunds. Refunds still
+// waiting for the gateway are due at once.
+var migration0006RefundRetries = Migration{
+	Version: 6,
+	Name:    "refund_retries",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(refundRetryColumns()); err != nil {
+			return err
+		}
+		return tx.Table("refunds").Where("next_attempt_at IS NULL").
+			Update("next_attempt_at", gorm.Expr("updated_at")).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		// SQLite cannot drop an indexed column
+		if err := tx.Migrator().DropIndex(refundRetryColumns(), "NextAttemptAt"); err != nil {
+			return err
+		}
+		return dropColumns(tx, "refunds", "attempts", "next_attempt_at", "last_error")
+	},
+}
+
+// refundRetryColumns holds only the columns this migration adds to refunds
+func refundRetryColumns() interface{} {
+	type Refund struct {
+		Attempts      int
+		NextAttemptAt time.Time `gorm:"index"`
+		LastError     string    `gorm:"type:text"`
+	}
+	return &Refund{}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"gorm.io/gorm"
+)
+
+// migration0007RecordLocators gives a record locator to every booking made before
+// locators existed, so it can be found by PNR lookup. Down keeps the locators: they
+// stay valid either way.
+var migration0007RecordLocators = Migration{
+	Version: 7,
+	Name:    "record_locators",
+	Up: func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Table("bookings").Where("record_locator IS NULL OR record_locator = ''").
+			Order("id").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := assignBookingLocator(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		return nil
+	},
+}
+
+// bookingLocatorAlphabet leaves out 0, 1, I and O, like the locators of new bookings
+const bookingLocatorAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// assignBookingLocator sets a random locator on the booking. The unique index decides
+// collisions; each attempt runs in a savepoint so a collision does not abort the migration.
+func assignBookingLocator(tx *gorm.DB, id uint) error {
+	for attempt := 0; attempt < 5; attempt++ {
+		locator := make([]byte, 6)
+		for i := range locator {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(bookingLocatorAlphabet))))
+			if err != nil {
+				return err
+			}
+			locator[i] = bookingLocatorAlphabet[n.Int64()]
+		}
+
+		err := tx.Transaction(func(sp *gorm.DB) error {
+			return sp.Table("bookings").Where("id = ?", id).Update("record_locator", string(locator)).Error
+		})
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("failed to assign a record locator to booking %d: %w", id, err)
+		}
+	}
+	return fmt.Errorf("failed to allocate a unique record locator for booking %d", id)
+}
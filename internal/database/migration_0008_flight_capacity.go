@@ -0,0 +1,24 @@
+package database
+
+import "gorm.io/gorm"
+
+// migration0008FlightCapacity sets the capacity of flights created before it was
+// tracked: the seats still available plus the seats that active bookings and holds
+// take. Disrupted bookings keep their seats until they are cancelled, so they count.
+// Down keeps the capacities, which later bookings rely on.
+var migration0008FlightCapacity = Migration{
+	Version: 8,
+	Name:    "flight_capacity",
+	Up: func(tx *gorm.DB) error {
+		return tx.Exec(`UPDATE flights SET capacity = available_seats
+			+ COALESCE((SELECT SUM(quantity) FROM bookings
+				WHERE bookings.flight_id = flights.id AND bookings.booking_status IN ('Confirmed', 'Waitlisted', 'Disrupted')
+				AND bookings.deleted_at IS NULL), 0)
+			+ COALESCE((SELECT SUM(quantity) FROM seat_holds
+				WHERE seat_holds.flight_id = flights.id AND seat_holds.hold_status = 'Active' AND seat_holds.deleted_at IS NULL), 0)
+			WHERE capacity = 0 OR capacity IS NULL`).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,24 @@
+package database
+
+import "gorm.io/gorm"
+
+// migration0009OutboxSinks records which sinks have accepted each outbox event, so a
+// retry only goes to the sinks that failed. Pending events start with none.
+var migration0009OutboxSinks = Migration{
+	Version: 9,
+	Name:    "outbox_sinks",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(outboxSinkColumns())
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropColumns(tx, "outbox_events", "delivered_sinks")
+	},
+}
+
+// outboxSinkColumns holds only the columns this migration adds to outbox_events
+func outboxSinkColumns() interface{} {
+	type OutboxEvent struct {
+		DeliveredSinks string `gorm:"type:text"`
+	}
+	return &OutboxEvent{}
+}
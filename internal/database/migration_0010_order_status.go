@@ -0,0 +1,58 @@
+package database
+
+import "gorm.io/gorm"
+
+// migration0010OrderStatus recomputes the status of every order from its bookings,
+// which was set when the order was made and not updated afterwards. Down keeps the
+// statuses: they are correct either way.
+var migration0010OrderStatus = Migration{
+	Version: 10,
+	Name:    "order_status",
+	Up: func(tx *gorm.DB) error {
+		var rows []struct {
+			OrderID       uint
+			BookingStatus string
+		}
+		if err := tx.Table("bookings").Select("order_id, booking_status").
+			Where("order_id IS NOT NULL AND deleted_at IS NULL").
+			Order("order_id").Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		statuses := make(map[uint][]string)
+		for _, r := range rows {
+			statuses[r.OrderID] = append(statuses[r.OrderID], r.BookingStatus)
+		}
+		for orderID, s := range statuses {
+			if err := tx.Table("orders").Where("id = ?", orderID).
+				Update("order_status", legacyOrderStatus(s)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		return nil
+	},
+}
+
+// legacyOrderStatus is a frozen copy of the order status rule this migration shipped
+// with: a disrupted segment comes first, then a cancelled one, then a waitlisted one
+func legacyOrderStatus(statuses []string) string {
+	count := make(map[string]int, len(statuses))
+	for _, status := range statuses {
+		count[status]++
+	}
+	switch {
+	case count["Cancelled"] == len(statuses):
+		return "Cancelled"
+	case count["Disrupted"] > 0:
+		return "Disrupted"
+	case count["Cancelled"] > 0:
+		return "PartiallyCancelled"
+	case count["Waitlisted"] > 0:
+		return "Waitlisted"
+	default:
+		return "Confirmed"
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FareClassRequest is a fare class in a FlightRequest
+type FareClassRequest struct {
+	Name          string  `json:"name" binding:"required"`
+	Price         float64 `json:"price" binding:"required"`
+	Capacity      int     `json:"capacity"`
+	OversellLimit int     `json:"oversell_limit"`
+}
+
+// FlightRequest is the request body for creating or replacing a flight. Price and
+// available_seats may be left out when fare classes are given; fare classes can only
+// be set when the flight is created.
+type FlightRequest struct {
+	FlightNumber     string             `json:"flight_number" binding:"required"`
+	DepartureAirport string             `json:"departure_airport" binding:"required"`
+	ArrivalAirport   string             `json:"arrival_airport" binding:"required"`
+	DepartureTime    string             `json:"departure_time" binding:"required"` // local time at the departure airport, YYYY-MM-DD HH:MM
+	ArrivalTime      string             `json:"arrival_time" binding:"required"`   // local time at the arrival airport, YYYY-MM-DD HH:MM
+	Airline          string             `json:"airline" binding:"required"`
+	Price            float64            `json:"price" binding:"required_without=FareClasses"`
+	AvailableSeats   *int               `json:"available_seats" binding:"required_without=FareClasses"`
+	RefundRule       string             `json:"refund_rule"`     // defaults to Standard
+	OversellPolicy   string             `json:"oversell_policy"` // Fixed, Percentage or None; empty uses the oversell rules
+	OversellValue    float64            `json:"oversell_value"`
+	FareClasses      []FareClassRequest `json:"fare_classes" binding:"dive"`
+}
+
+// FlightPatchRequest is the request body for partially updating a flight
+type FlightPatchRequest struct {
+	FlightNumber     *string  `json:"flight_number"`
+	DepartureAirport *string  `json:"departure_airport"`
+	ArrivalAirport   *string  `json:"arrival_airport"`
+	DepartureTime    *string  `json:"departure_time"` // local time at the departure airport
+	ArrivalTime      *string  `json:"arrival_time"`   // local time at the arrival airport
+	Airline          *string  `json:"airline"`
+	Price            *float64 `json:"price"`
+	AvailableSeats   *int     `json:"available_seats"`
+	RefundRule       *string  `json:"refund_rule"`
+	OversellPolicy   *string  `json:"oversell_policy"`
+	OversellValue    *float64 `json:"oversell_value"`
+}
+
+// FlightStatusRequest is the request body for changing a flight's operational status
+type FlightStatusRequest struct {
+	Status                 string `json:"status" binding:"required"`
+	EstimatedDepartureTime string `json:"estimated_departure_time"` // local time at the departure airport, YYYY-MM-DD HH:MM
+	EstimatedArrivalTime   string `json:"estimated_arrival_time"`   // local time at the arrival airport; defaults to departure plus duration
+	Reason                 string `json:"reason"`
+}
+
+// AdminFlightHandler handles flight management requests from administrators
+type AdminFlightHandler struct {
+	FlightService service.FlightService
+}
+
+// NewAdminFlightHandler creates a new AdminFlightHandler
+func NewAdminFlightHandler(flightService service.FlightService) *AdminFlightHandler {
+	return &AdminFlightHandler{FlightService: flightService}
+}
+
+// CreateFlight handles requests to create a flight
+func (h *AdminFlightHandler) CreateFlight(c *gin.Context) {
+	var req FlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	flight := models.Flight{
+		FlightNumber:       req.FlightNumber,
+		DepartureAirport:   req.DepartureAirport,
+		ArrivalAirport:     req.ArrivalAirport,
+		DepartureLocalTime: req.DepartureTime,
+		ArrivalLocalTime:   req.ArrivalTime,
+		Airline:            req.Airline,
+		Price:              req.Price,
+		RefundRule:         req.RefundRule,
+		OversellPolicy:     req.OversellPolicy,
+		OversellValue:      req.OversellValue,
+	}
+	if req.AvailableSeats != nil {
+		flight.AvailableSeats = *req.AvailableSeats
+	}
+	for _, fc := range req.FareClasses {
+		flight.FareClasses = append(flight.FareClasses, models.FareClass{
+			Name:          fc.Name,
+			Price:         fc.Price,
+			Capacity:      fc.Capacity,
+			OversellLimit: fc.OversellLimit,
+		})
+	}
+
+	createdFlight, err := h.FlightService.CreateFlight(&flight)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, createdFlight)
+}
+
+// ReplaceFlight handles requests to replace all editable fields of a flight
+func (h *AdminFlightHandler) ReplaceFlight(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid flight ID")
+		return
+	}
+
+	var req FlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	if len(req.FareClasses) > 0 || req.AvailableSeats == nil {
+		respondBadRequest(c, "fare_classes cannot be replaced; price and available_seats are required")
+		return
+	}
+	if req.RefundRule == "" {
+		req.RefundRule = service.RefundRuleStandard
+	}
+
+	patch := service.FlightPatch{
+		FlightNumber:     &req.FlightNumber,
+		DepartureAirport: &req.DepartureAirport,
+		ArrivalAirport:   &req.ArrivalAirport,
+		DepartureTime:    &req.DepartureTime,
+		ArrivalTime:      &req.ArrivalTime,
+		Airline:          &req.Airline,
+		Price:            &req.Price,
+		AvailableSeats:   req.AvailableSeats,
+		RefundRule:       &req.RefundRule,
+		OversellPolicy:   &req.OversellPolicy,
+		OversellValue:    &req.OversellValue,
+	}
+
+	flight, err := h.FlightService.UpdateFlight(uint(id), &patch)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, flight)
+}
+
+// PatchFlight handles requests to update some fields of a flight
+func (h *AdminFlightHandler) PatchFlight(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid flight ID")
+		return
+	}
+
+	var req FlightPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	patch := service.FlightPatch(req)
+
+	flight, err := h.FlightService.UpdateFlight(uint(id), &patch)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, flight)
+}
+
+// DeleteFlight handles requests to soft delete a flight
+func (h *AdminFlightHandler) DeleteFlight(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid flight ID")
+		return
+	}
+
+	if err := h.FlightService.DeleteFlight(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// UpdateFlightStatus handles requests to change a flight's operational status
+func (h *AdminFlightHandler) UpdateFlightStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid flight ID")
+		return
+	}
+
+	var req FlightStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	update := service.FlightStatusUpdate(req)
+
+	flight, err := h.FlightService.UpdateStatus(uint(id), &update)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, flight)
+}
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockFlightService is a mock implementation of FlightService interface
+type MockFlightService struct {
+	mock.Mock
+}
+
+func (m *MockFlightService) CreateFlight(flight *models.Flight) (*models.Flight, error) {
+	args := m.Called(flight)
+	return args.Get(0).(*models.Flight), args.Error(1)
+}
+
+func (m *MockFlightService) UpdateFlight(id uint, patch *service.FlightPatch) (*models.Flight, error) {
+	args := m.Called(id, patch)
+	return args.Get(0).(*models.Flight), args.Error(1)
+}
+
+func (m *MockFlightService) DeleteFlight(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockFlightService) UpdateStatus(id uint, update *service.FlightStatusUpdate) (*models.Flight, error) {
+	args := m.Called(id, update)
+	return args.Get(0).(*models.Flight), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupAdminTestRouter(adminHandler *AdminFlightHandler) *gin.Engine {
+	r := gin.Default()
+	admin := r.Group("/admin")
+	admin.POST("/flights", adminHandler.CreateFlight)
+	admin.PUT("/flights/:id", adminHandler.ReplaceFlight)
+	admin.PATCH("/flights/:id", adminHandler.PatchFlight)
+	admin.DELETE("/flights/:id", adminHandler.DeleteFlight)
+	admin.POST("/flights/:id/status", adminHandler.UpdateFlightStatus)
+	return r
+}
+
+func validFlightRequest() map[string]interface{} {
+	return map[string]interface{}{
+		"flight_number":     "BR101",
+		"departure_airport": "TPE",
+		"arrival_airport":   "NRT",
+		"departure_time":    "2025-08-01 10:00",
+		"arrival_time":      "2025-08-01 14:00",
+		"airline":           "EVA Air",
+		"price":             500,
+		"available_seats":   100,
+	}
+}
+
+// TestCreateFlight_Success tests a successful flight creation
+func TestCreateFlight_Success(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	expectedFlight := &models.Flight{
+		Model:            gorm.Model{ID: 1},
+		FlightNumber:     "BR101",
+		DepartureAirport: "TPE",
+		ArrivalAirport:   "NRT",
+		Price:            500,
+		AvailableSeats:   100,
+	}
+
+	mockService.On("CreateFlight", mock.MatchedBy(func(f *models.Flight) bool {
+		return f.FlightNumber == "BR101" && f.AvailableSeats == 100 && f.DepartureLocalTime == "2025-08-01 10:00"
+	})).Return(expectedFlight, nil).Once()
+
+	jsonValue, _ := json.Marshal(validFlightRequest())
+	req, _ := http.NewRequest("POST", "/admin/flights", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var responseFlight models.Flight
+	json.Unmarshal(w.Body.Bytes(), &responseFlight)
+	assert.Equal(t, expectedFlight.ID, responseFlight.ID)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateFlight_MissingField tests flight creation with a missing required field
+func TestCreateFlight_MissingField(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	body := validFlightRequest()
+	delete(body, "available_seats")
+
+	jsonValue, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/admin/flights", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertNotCalled(t, "CreateFlight", mock.Anything)
+}
+
+// TestCreateFlight_ValidationError tests flight creation rejected by service validation
+func TestCreateFlight_ValidationError(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	mockService.On("CreateFlight", mock.Anything).Return((*models.Flight)(nil), service.NewValidationError(service.CodeInvalidFlight, "invalid flight: departure_airport must be a 3-letter IATA code")).Once()
+
+	jsonValue, _ := json.Marshal(validFlightRequest())
+	req, _ := http.NewRequest("POST", "/admin/flights", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "IATA code")
+
+	mockService.AssertExpectations(t)
+}
+
+// TestPatchFlight_Success tests that only the supplied fields are passed to the service
+func TestPatchFlight_Success(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	expectedFlight := &models.Flight{Model: gorm.Model{ID: 1}, Price: 650}
+
+	mockService.On("UpdateFlight", uint(1), mock.MatchedBy(func(p *service.FlightPatch) bool {
+		return p.Price != nil && *p.Price == 650 && p.AvailableSeats == nil && p.FlightNumber == nil
+	})).Return(expectedFlight, nil).Once()
+
+	req, _ := http.NewRequest("PATCH", "/admin/flights/1", bytes.NewBufferString(`{"price": 650}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestReplaceFlight_NotFound tests replacing a non-existent flight
+func TestReplaceFlight_NotFound(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	mockService.On("UpdateFlight", uint(999), mock.Anything).Return((*models.Flight)(nil), service.NewNotFoundError(service.CodeFlightNotFound, "flight not found")).Once()
+
+	jsonValue, _ := json.Marshal(validFlightRequest())
+	req, _ := http.NewRequest("PUT", "/admin/flights/999", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestDeleteFlight_Success tests a successful flight deletion
+func TestDeleteFlight_Success(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	mockService.On("DeleteFlight", uint(1)).Return(nil).Once()
+
+	req, _ := http.NewRequest("DELETE", "/admin/flights/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestDeleteFlight_ActiveBookings tests that flights with active bookings cannot be deleted
+func TestDeleteFlight_ActiveBookings(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	mockService.On("DeleteFlight", uint(1)).Return(service.NewConflictError(service.CodeFlightHasBookings, "flight has 2 active bookings")).Once()
+
+	req, _ := http.NewRequest("DELETE", "/admin/flights/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "active bookings")
+	assertErrorCode(t, w, service.CodeFlightHasBookings)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestUpdateFlightStatus_Success tests that a delay is passed to the service
+func TestUpdateFlightStatus_Success(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	expected := &service.FlightStatusUpdate{
+		Status:                 service.FlightStatusDelayed,
+		EstimatedDepartureTime: "2025-08-01 12:30",
+		Reason:                 "Weather",
+	}
+	delayed := &models.Flight{Model: gorm.Model{ID: 1}, Status: service.FlightStatusDelayed, EstimatedDepartureLocalTime: "2025-08-01 12:30"}
+	mockService.On("UpdateStatus", uint(1), expected).Return(delayed, nil).Once()
+
+	// When
+	body, _ := json.Marshal(map[string]interface{}{
+		"status":                   "Delayed",
+		"estimated_departure_time": "2025-08-01 12:30",
+		"reason":                   "Weather",
+	})
+	req, _ := http.NewRequest("POST", "/admin/flights/1/status", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"Delayed"`)
+	assert.Contains(t, w.Body.String(), `"estimated_departure_time":"2025-08-01 12:30"`)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestUpdateFlightStatus_InvalidTransition tests that a rejected transition returns 409
+func TestUpdateFlightStatus_InvalidTransition(t *testing.T) {
+	// Given
+	mockService := new(MockFlightService)
+	handler := NewAdminFlightHandler(mockService)
+
+	router := setupAdminTestRouter(handler)
+
+	mockService.On("UpdateStatus", uint(1), mock.Anything).Return((*models.Flight)(nil),
+		service.NewConflictError(service.CodeInvalidStatusTransition, "flight status cannot change from Cancelled to Scheduled")).Once()
+
+	// When
+	body, _ := json.Marshal(map[string]interface{}{"status": "Scheduled"})
+	req, _ := http.NewRequest("POST", "/admin/flights/1/status", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertErrorCode(t, w, service.CodeInvalidStatusTransition)
+
+	mockService.AssertExpectations(t)
+}
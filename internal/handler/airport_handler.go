@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"flight-booking/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxAirportSearchLimit = 50
+
+// AirportHandler handles airport reference data requests
+type AirportHandler struct {
+	ReferenceData service.ReferenceDataService
+}
+
+// NewAirportHandler creates a new AirportHandler
+func NewAirportHandler(referenceData service.ReferenceDataService) *AirportHandler {
+	return &AirportHandler{ReferenceData: referenceData}
+}
+
+// SearchAirports handles airport autocomplete requests, e.g. /airports?q=tok
+func (h *AirportHandler) SearchAirports(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		respondBadRequest(c, "q is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > maxAirportSearchLimit {
+		respondBadRequest(c, "Invalid limit parameter. Must be between 1 and 50.")
+		return
+	}
+
+	airports, err := h.ReferenceData.SearchAirports(q, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, airports)
+}
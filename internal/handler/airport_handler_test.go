@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"flight-booking/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReferenceDataService is a mock implementation of ReferenceDataService interface
+type MockReferenceDataService struct {
+	mock.Mock
+}
+
+func (m *MockReferenceDataService) SearchAirports(query string, limit int) ([]models.Airport, error) {
+	args := m.Called(query, limit)
+	return args.Get(0).([]models.Airport), args.Error(1)
+}
+
+func (m *MockReferenceDataService) ValidateAirport(code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+func (m *MockReferenceDataService) ResolveAirline(codeOrName string) (*models.Airline, error) {
+	args := m.Called(codeOrName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Airline), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupAirportTestRouter(airportHandler *AirportHandler) *gin.Engine {
+	r := gin.Default()
+	r.GET("/airports", airportHandler.SearchAirports)
+	return r
+}
+
+// TestSearchAirports_Success tests airport autocomplete
+func TestSearchAirports_Success(t *testing.T) {
+	// Given
+	mockService := new(MockReferenceDataService)
+	handler := NewAirportHandler(mockService)
+
+	router := setupAirportTestRouter(handler)
+
+	mockService.On("SearchAirports", "tok", 5).Return([]models.Airport{
+		{Code: "HND", Name: "Tokyo Haneda Airport", City: "Tokyo", Country: "JP", TimeZone: "Asia/Tokyo"},
+		{Code: "NRT", Name: "Narita International Airport", City: "Tokyo", Country: "JP", TimeZone: "Asia/Tokyo"},
+	}, nil).Once()
+
+	// When
+	req, _ := http.NewRequest("GET", "/airports?q=tok&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.Airport
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response, 2)
+	assert.Equal(t, "HND", response[0].Code)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestSearchAirports_InvalidParams tests that q is required and limit is bounded
+func TestSearchAirports_InvalidParams(t *testing.T) {
+	// Given
+	mockService := new(MockReferenceDataService)
+	handler := NewAirportHandler(mockService)
+
+	router := setupAirportTestRouter(handler)
+
+	for _, url := range []string{"/airports", "/airports?q=tok&limit=0", "/airports?q=tok&limit=51"} {
+		// When
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+
+	mockService.AssertNotCalled(t, "SearchAirports", mock.Anything, mock.Anything)
+}
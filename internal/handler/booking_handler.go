@@ -4,7 +4,6 @@ import (
 	"flight-booking/internal/models"
 	"flight-booking/internal/service"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,29 +18,103 @@ func NewBookingHandler(bookingService service.BookingService) *BookingHandler {
 	return &BookingHandler{BookingService: bookingService}
 }
 
+// BookingPassengerRequest is a traveler in a CreateBookingRequest
+type BookingPassengerRequest struct {
+	PassengerRequest
+	Seat string `json:"seat"` // optional seat to assign, e.g. "12C"
+}
+
+// CreateBookingRequest is the request body for POST /bookings. It holds only what the
+// client decides; status, price, payment and refunds are set by the server. When
+// HoldToken is set the booking is created from that seat hold and the flight and
+// quantity come from the hold.
+type CreateBookingRequest struct {
+	FlightID      uint                      `json:"flight_id"`
+	FareClass     string                    `json:"fare_class"` // defaults to the cheapest class with enough seats
+	PassengerName string                    `json:"passenger_name"`
+	ContactEmail  string                    `json:"contact_email"`
+	Quantity      int                       `json:"quantity"`
+	Passengers    []BookingPassengerRequest `json:"passengers"`
+	HoldToken     string                    `json:"hold_token"`
+}
+
+// PassengerResponse is a passenger as returned by the routes that address a booking by
+// its ID. Those IDs are sequential and need no credentials, so the travel document and
+// date of birth are only returned by the record locator lookup.
+type PassengerResponse struct {
+	ID            uint    `json:"ID"`
+	BookingID     uint    `json:"booking_id"`
+	Name          string  `json:"name"`
+	PassengerType string  `json:"passenger_type"`
+	Fare          float64 `json:"fare"`
+	Seat          string  `json:"seat,omitempty"`
+}
+
+// BookingResponse is a booking with its passengers redacted
+type BookingResponse struct {
+	*models.Booking
+	Passengers []PassengerResponse `json:"passengers"`
+}
+
+// newBookingResponse redacts the booking's passengers
+func newBookingResponse(booking *models.Booking) BookingResponse {
+	resp := BookingResponse{Booking: booking, Passengers: make([]PassengerResponse, 0, len(booking.Passengers))}
+	for _, p := range booking.Passengers {
+		resp.Passengers = append(resp.Passengers, PassengerResponse{
+			ID:            p.ID,
+			BookingID:     p.BookingID,
+			Name:          p.Name,
+			PassengerType: p.PassengerType,
+			Fare:          p.Fare,
+			Seat:          p.Seat,
+		})
+	}
+	return resp
+}
+
+// toBooking converts the request to a new booking
+func (r *CreateBookingRequest) toBooking() models.Booking {
+	booking := models.Booking{
+		FlightID:      r.FlightID,
+		FareClass:     r.FareClass,
+		PassengerName: r.PassengerName,
+		ContactEmail:  r.ContactEmail,
+		Quantity:      r.Quantity,
+	}
+	for _, p := range r.Passengers {
+		booking.Passengers = append(booking.Passengers, models.Passenger{
+			Name:           p.Name,
+			DateOfBirth:    p.DateOfBirth,
+			DocumentNumber: p.DocumentNumber,
+			PassengerType:  p.PassengerType,
+			Seat:           p.Seat,
+		})
+	}
+	return booking
+}
+
 // CreateBooking handles flight booking requests
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
-	var booking models.Booking
-	if err := c.ShouldBindJSON(&booking); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	var req CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
 		return
 	}
-
-	if booking.Quantity <= 0 {
-		c.JSON(400, gin.H{"error": "Quantity must be a positive integer"})
+	if req.Quantity < 0 || (req.Quantity == 0 && req.HoldToken == "") {
+		respondBadRequest(c, "Quantity must be a positive integer")
 		return
 	}
+	booking := req.toBooking()
 
-	createdBooking, err := h.BookingService.CreateBooking(&booking)
+	var createdBooking *models.Booking
+	var err error
+	if req.HoldToken != "" {
+		createdBooking, err = h.BookingService.CreateBookingFromHold(req.HoldToken, &booking)
+	} else {
+		createdBooking, err = h.BookingService.CreateBooking(&booking)
+	}
 	if err != nil {
-		// Handle errors from the service layer
-		if strings.Contains(err.Error(), "flight not found") {
-			c.JSON(404, gin.H{"error": err.Error()})
-		} else if strings.Contains(err.Error(), "not enough seats") {
-			c.JSON(400, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(500, gin.H{"error": "Internal Server Error: " + err.Error()})
-		}
+		respondError(c, err)
 		return
 	}
 
@@ -52,18 +125,49 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 func (h *BookingHandler) GetBooking(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid booking ID"})
+		respondBadRequest(c, "Invalid booking ID")
 		return
 	}
 
 	booking, err := h.BookingService.GetBooking(uint(id))
 	if err != nil {
-		// Handle errors from the service layer
-		if strings.Contains(err.Error(), "booking not found") {
-			c.JSON(404, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(500, gin.H{"error": "Internal Server Error: " + err.Error()})
-		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, newBookingResponse(booking))
+}
+
+// CancelBooking handles requests to cancel a booking and release its seats
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid booking ID")
+		return
+	}
+
+	booking, err := h.BookingService.CancelBooking(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, newBookingResponse(booking))
+}
+
+// GetBookingByLocator handles record locator lookups. The passenger surname is
+// required so that a locator alone does not expose the booking; this is the only
+// lookup that returns the passengers' travel documents.
+func (h *BookingHandler) GetBookingByLocator(c *gin.Context) {
+	lastName := c.Query("last_name")
+	if lastName == "" {
+		respondBadRequest(c, "last_name is required")
+		return
+	}
+
+	booking, err := h.BookingService.GetBookingByLocator(c.Param("pnr"), lastName)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
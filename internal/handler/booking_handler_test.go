@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"flight-booking/internal/models"
+	"flight-booking/internal/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,16 +26,41 @@ func (m *MockBookingService) CreateBooking(booking *models.Booking) (*models.Boo
 	return args.Get(0).(*models.Booking), args.Error(1)
 }
 
+func (m *MockBookingService) CreateBookingFromHold(token string, booking *models.Booking) (*models.Booking, error) {
+	args := m.Called(token, booking)
+	return args.Get(0).(*models.Booking), args.Error(1)
+}
+
 func (m *MockBookingService) GetBooking(id uint) (*models.Booking, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.Booking), args.Error(1)
 }
 
+func (m *MockBookingService) CancelBooking(id uint) (*models.Booking, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Booking), args.Error(1)
+}
+
+func (m *MockBookingService) GetBookingByLocator(locator, lastName string) (*models.Booking, error) {
+	args := m.Called(locator, lastName)
+	return args.Get(0).(*models.Booking), args.Error(1)
+}
+
+// assertErrorCode checks the machine-readable code in the error envelope
+func assertErrorCode(t *testing.T, w *httptest.ResponseRecorder, code string) {
+	t.Helper()
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, code, response.Error.Code)
+}
+
 // SetupRouter for testing
 func setupTestRouter(bookingHandler *BookingHandler) *gin.Engine {
 	r := gin.Default()
 	r.POST("/bookings", bookingHandler.CreateBooking)
 	r.GET("/bookings/:id", bookingHandler.GetBooking)
+	r.GET("/bookings/by-locator/:pnr", bookingHandler.GetBookingByLocator)
+	r.DELETE("/bookings/:id", bookingHandler.CancelBooking)
 	return r
 }
 
@@ -106,6 +132,7 @@ func TestCreateBooking_InvalidQuantity(t *testing.T) {
 	// Then
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "Quantity must be a positive integer")
+	assertErrorCode(t, w, service.CodeInvalidRequest)
 
 	mockService.AssertNotCalled(t, "CreateBooking", mock.Anything)
 }
@@ -124,7 +151,7 @@ func TestCreateBooking_FlightNotFound(t *testing.T) {
 		Quantity:      1,
 	}
 
-	mockService.On("CreateBooking", &bookingReq).Return((*models.Booking)(nil), errors.New("flight not found or not enough seats available")).Once()
+	mockService.On("CreateBooking", &bookingReq).Return((*models.Booking)(nil), service.NewNotFoundError(service.CodeFlightNotFound, "flight not found")).Once()
 
 	jsonValue, _ := json.Marshal(bookingReq)
 	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer(jsonValue))
@@ -136,6 +163,7 @@ func TestCreateBooking_FlightNotFound(t *testing.T) {
 	// Then
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	assert.Contains(t, w.Body.String(), "flight not found")
+	assertErrorCode(t, w, service.CodeFlightNotFound)
 
 	mockService.AssertExpectations(t)
 }
@@ -154,7 +182,7 @@ func TestCreateBooking_NotEnoughSeats(t *testing.T) {
 		Quantity:      10, // Requesting more than available (even with oversell)
 	}
 
-	mockService.On("CreateBooking", &bookingReq).Return((*models.Booking)(nil), errors.New("not enough seats available (oversell limit reached)")).Once()
+	mockService.On("CreateBooking", &bookingReq).Return((*models.Booking)(nil), service.NewInsufficientSeatsError("not enough seats: available=0, oversell limit=10")).Once()
 
 	jsonValue, _ := json.Marshal(bookingReq)
 	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer(jsonValue))
@@ -166,6 +194,7 @@ func TestCreateBooking_NotEnoughSeats(t *testing.T) {
 	// Then
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "not enough seats")
+	assertErrorCode(t, w, service.CodeInsufficientSeats)
 
 	mockService.AssertExpectations(t)
 }
@@ -196,10 +225,57 @@ func TestCreateBooking_InternalError(t *testing.T) {
 	// Then
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "Internal Server Error")
+	assertErrorCode(t, w, CodeInternalError)
+	assert.NotContains(t, w.Body.String(), "database connection error")
 
 	mockService.AssertExpectations(t)
 }
 
+// TestCreateBooking_IgnoresServerFields tests that refunds, payment fields and
+// timestamps in the request body are not passed on to the service
+func TestCreateBooking_IgnoresServerFields(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	bookingRequest := map[string]interface{}{
+		"flight_id":      1,
+		"passenger_name": "Test User",
+		"quantity":       1,
+		"passengers": []map[string]string{
+			{"name": "Test User", "date_of_birth": "1990-01-01", "document_number": "P1", "passenger_type": "Adult", "seat": "12C"},
+		},
+		"refunds":           []map[string]interface{}{{"amount": 5000, "refund_status": "Pending"}},
+		"payment_reference": "ch_someone_else",
+		"paid_at":           "2025-07-01T00:00:00Z",
+		"CreatedAt":         "2000-01-01T00:00:00Z",
+		"payment_status":    "Paid",
+	}
+	jsonValue, _ := json.Marshal(bookingRequest)
+
+	expected := models.Booking{
+		FlightID:      1,
+		PassengerName: "Test User",
+		Quantity:      1,
+		Passengers: []models.Passenger{
+			{Name: "Test User", DateOfBirth: "1990-01-01", DocumentNumber: "P1", PassengerType: "Adult", Seat: "12C"},
+		},
+	}
+	mockService.On("CreateBooking", &expected).Return(&models.Booking{Model: gorm.Model{ID: 1}}, nil).Once()
+
+	// When
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 // TestGetBooking_Success tests successful retrieval of a booking
 func TestGetBooking_Success(t *testing.T) {
 	// Given
@@ -237,6 +313,62 @@ func TestGetBooking_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+// TestGetBooking_RedactsPassengers tests that the routes taking a sequential booking ID
+// leave out the passengers' travel documents, which only the record locator lookup returns
+func TestGetBooking_RedactsPassengers(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	booking := models.Booking{
+		Model:         gorm.Model{ID: 1},
+		RecordLocator: "ABC234",
+		PassengerName: "Chen Wei",
+		Quantity:      1,
+		Passengers: []models.Passenger{
+			{Name: "Chen Wei", DateOfBirth: "1990-01-01", DocumentNumber: "X1234567", PassengerType: "Adult", Seat: "12C"},
+		},
+	}
+	mockService.On("GetBooking", uint(1)).Return(&booking, nil).Once()
+	mockService.On("CancelBooking", uint(1)).Return(&booking, nil).Once()
+	mockService.On("GetBookingByLocator", "ABC234", "Chen").Return(&booking, nil).Once()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/bookings/1", nil),
+		httptest.NewRequest("DELETE", "/bookings/1", nil),
+	} {
+		// When
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "document_number", req.Method)
+		assert.NotContains(t, w.Body.String(), "X1234567", req.Method)
+		assert.NotContains(t, w.Body.String(), "date_of_birth", req.Method)
+
+		var response models.Booking
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "ABC234", response.RecordLocator)
+		if assert.Len(t, response.Passengers, 1) {
+			assert.Equal(t, "Chen Wei", response.Passengers[0].Name)
+			assert.Equal(t, "12C", response.Passengers[0].Seat)
+		}
+	}
+
+	// When looked up by record locator and surname
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/bookings/by-locator/ABC234?last_name=Chen", nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"document_number":"X1234567"`)
+
+	mockService.AssertExpectations(t)
+}
+
 // TestGetBooking_InvalidID tests retrieval with an invalid booking ID format
 func TestGetBooking_InvalidID(t *testing.T) {
 	// Given
@@ -265,7 +397,7 @@ func TestGetBooking_NotFound(t *testing.T) {
 	router := setupTestRouter(handler)
 
 	bookingID := uint(999)
-	mockService.On("GetBooking", bookingID).Return((*models.Booking)(nil), errors.New("booking not found")).Once()
+	mockService.On("GetBooking", bookingID).Return((*models.Booking)(nil), service.NewNotFoundError(service.CodeBookingNotFound, "booking not found")).Once()
 
 	req, _ := http.NewRequest("GET", "/bookings/999", nil)
 	w := httptest.NewRecorder()
@@ -296,6 +428,242 @@ func TestGetBooking_InternalError(t *testing.T) {
 	// Then
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "Internal Server Error")
+	assertErrorCode(t, w, CodeInternalError)
+	assert.NotContains(t, w.Body.String(), "database connection error")
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCancelBooking_Success tests a successful booking cancellation
+func TestCancelBooking_Success(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	bookingID := uint(1)
+	expectedBooking := models.Booking{
+		Model:         gorm.Model{ID: bookingID},
+		FlightID:      1,
+		PassengerName: "Test User",
+		Quantity:      2,
+		TotalPrice:    200.0,
+		BookingStatus: "Cancelled",
+	}
+
+	mockService.On("CancelBooking", bookingID).Return(&expectedBooking, nil).Once()
+
+	req, _ := http.NewRequest("DELETE", "/bookings/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseBooking models.Booking
+	json.Unmarshal(w.Body.Bytes(), &responseBooking)
+	assert.Equal(t, expectedBooking.ID, responseBooking.ID)
+	assert.Equal(t, expectedBooking.BookingStatus, responseBooking.BookingStatus)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCancelBooking_InvalidID tests cancellation with an invalid booking ID format
+func TestCancelBooking_InvalidID(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("DELETE", "/bookings/abc", nil) // Invalid ID
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid booking ID")
+
+	mockService.AssertNotCalled(t, "CancelBooking", mock.Anything)
+}
+
+// TestCancelBooking_NotFound tests cancellation of a non-existent booking
+func TestCancelBooking_NotFound(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	bookingID := uint(999)
+	mockService.On("CancelBooking", bookingID).Return((*models.Booking)(nil), service.NewNotFoundError(service.CodeBookingNotFound, "booking not found")).Once()
+
+	req, _ := http.NewRequest("DELETE", "/bookings/999", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "booking not found")
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCancelBooking_AlreadyCancelled tests that cancelling a booking twice is rejected
+func TestCancelBooking_AlreadyCancelled(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	bookingID := uint(1)
+	mockService.On("CancelBooking", bookingID).Return((*models.Booking)(nil), service.NewConflictError(service.CodeBookingAlreadyCancelled, "booking already cancelled")).Once()
+
+	req, _ := http.NewRequest("DELETE", "/bookings/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "already cancelled")
+	assertErrorCode(t, w, service.CodeBookingAlreadyCancelled)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCancelBooking_InternalError tests cancellation when an internal error occurs
+func TestCancelBooking_InternalError(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	bookingID := uint(1)
+	mockService.On("CancelBooking", bookingID).Return((*models.Booking)(nil), errors.New("database connection error")).Once()
+
+	req, _ := http.NewRequest("DELETE", "/bookings/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Internal Server Error")
+	assertErrorCode(t, w, CodeInternalError)
+	assert.NotContains(t, w.Body.String(), "database connection error")
+
+	mockService.AssertExpectations(t)
+}
+
+// TestGetBookingByLocator_Success tests looking up a booking by record locator and surname
+func TestGetBookingByLocator_Success(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	expectedBooking := models.Booking{
+		Model:         gorm.Model{ID: 1},
+		RecordLocator: "ABC234",
+		FlightID:      1,
+		PassengerName: "Chen Wei",
+		Quantity:      1,
+		BookingStatus: "Confirmed",
+	}
+
+	mockService.On("GetBookingByLocator", "ABC234", "Chen").Return(&expectedBooking, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/bookings/by-locator/ABC234?last_name=Chen", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseBooking models.Booking
+	json.Unmarshal(w.Body.Bytes(), &responseBooking)
+	assert.Equal(t, expectedBooking.RecordLocator, responseBooking.RecordLocator)
+	assert.Equal(t, expectedBooking.ID, responseBooking.ID)
 
 	mockService.AssertExpectations(t)
 }
+
+// TestGetBookingByLocator_MissingLastName tests that the surname is required
+func TestGetBookingByLocator_MissingLastName(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/bookings/by-locator/ABC234", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "last_name is required")
+
+	mockService.AssertNotCalled(t, "GetBookingByLocator", mock.Anything, mock.Anything)
+}
+
+// TestGetBookingByLocator_NotFound tests that an unknown locator or wrong surname returns 404
+func TestGetBookingByLocator_NotFound(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	mockService.On("GetBookingByLocator", "ABC234", "Lin").Return((*models.Booking)(nil), service.NewNotFoundError(service.CodeBookingNotFound, "booking not found")).Once()
+
+	req, _ := http.NewRequest("GET", "/bookings/by-locator/ABC234?last_name=Lin", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertErrorCode(t, w, service.CodeBookingNotFound)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateBooking_FromHold tests that a hold token books the held seats
+func TestCreateBooking_FromHold(t *testing.T) {
+	// Given
+	mockService := new(MockBookingService)
+	handler := NewBookingHandler(mockService)
+
+	router := setupTestRouter(handler)
+
+	bookingRequest := map[string]interface{}{
+		"hold_token":     "0123456789abcdef0123456789abcdef",
+		"passenger_name": "Test User",
+		"passengers": []map[string]string{
+			{"name": "Test User", "date_of_birth": "1990-01-01", "document_number": "P1", "passenger_type": "Adult"},
+		},
+	}
+	jsonValue, _ := json.Marshal(bookingRequest)
+
+	expectedBooking := models.Booking{
+		Model:         gorm.Model{ID: 1},
+		FlightID:      1,
+		PassengerName: "Test User",
+		Quantity:      1,
+		BookingStatus: "Confirmed",
+	}
+
+	mockService.On("CreateBookingFromHold", "0123456789abcdef0123456789abcdef", mock.AnythingOfType("*models.Booking")).Return(&expectedBooking, nil).Once()
+
+	// When
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "CreateBooking", mock.Anything)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CodeInternalError is returned for any error that is not a domain error
+const CodeInternalError = "internal_error"
+
+// ErrorBody carries a machine-readable code and a human-readable message
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the JSON envelope for every error returned by the API
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// respondError maps an error to an HTTP status and writes the error envelope.
+// Errors that are not *service.Error are treated as internal errors and their
+// details are not exposed to the client.
+func respondError(c *gin.Context, err error) {
+	var domainErr *service.Error
+	if !errors.As(err, &domainErr) {
+		_ = c.Error(err) // recorded for the request logger
+		c.JSON(500, ErrorResponse{Error: ErrorBody{Code: CodeInternalError, Message: "Internal Server Error"}})
+		return
+	}
+
+	status := 500
+	switch {
+	case errors.Is(domainErr, service.ErrNotFound):
+		status = 404
+	case errors.Is(domainErr, service.ErrValidation), errors.Is(domainErr, service.ErrInsufficientSeats):
+		status = 400
+	case errors.Is(domainErr, service.ErrPaymentDeclined):
+		status = 402
+	case errors.Is(domainErr, service.ErrConflict):
+		status = 409
+	}
+
+	c.JSON(status, ErrorResponse{Error: ErrorBody{Code: domainErr.Code, Message: domainErr.Message}})
+}
+
+// respondBadRequest writes a validation error for invalid request input
+func respondBadRequest(c *gin.Context, message string) {
+	respondError(c, service.NewValidationError(service.CodeInvalidRequest, "%s", message))
+}
@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"flight-booking/internal/models"
 	"flight-booking/internal/repository"
+	"flight-booking/internal/service"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,13 +16,19 @@ import (
 
 // FlightSearchItem represents a flight item in the search results
 type FlightSearchItem struct {
-	ID               uint    `json:"id"`
-	DepartureAirport string  `json:"departure_airport"`
-	ArrivalAirport   string  `json:"arrival_airport"`
-	DepartureTime    string  `json:"departure_time"`
-	ArrivalTime      string  `json:"arrival_time"`
-	Airline          string  `json:"airline"`
-	Price            float64 `json:"price"`
+	ID               uint      `json:"id"`
+	DepartureAirport string    `json:"departure_airport"`
+	ArrivalAirport   string    `json:"arrival_airport"`
+	DepartureTime    string    `json:"departure_time"` // local time at the departure airport
+	ArrivalTime      string    `json:"arrival_time"`   // local time at the arrival airport
+	DepartureTimeUTC time.Time `json:"departure_time_utc"`
+	ArrivalTimeUTC   time.Time `json:"arrival_time_utc"`
+	DurationMinutes  int       `json:"duration_minutes"`
+	Airline          string    `json:"airline"`
+	Price            float64   `json:"price"`
+	FareClass        string    `json:"fare_class,omitempty"` // cheapest class with a free seat; Price is its fare
+	Status           string    `json:"status"`
+	EstimatedTime    string    `json:"estimated_departure_time,omitempty"` // local time a delayed flight is expected to leave
 	// FlightNumber and AvailableSeats are intentionally omitted
 }
 
@@ -29,78 +40,157 @@ type SearchFlightsResponse struct {
 	Data     []FlightSearchItem `json:"data"`
 }
 
+// flightSortColumns maps the allowed sort_by keys to their ORDER BY expressions
+var flightSortColumns = map[string]string{
+	"price":          "price",
+	"departure_time": "departure_time", // UTC, so flights from different time zones interleave correctly
+	"arrival_time":   "arrival_time",
+	"duration":       "duration_minutes",
+}
+
 // FlightHandler handles flight-related HTTP requests
 type FlightHandler struct {
-	FlightRepo repository.FlightRepository
-	db         *gorm.DB // Still need db for query building
+	FlightRepo    repository.FlightRepository
+	ReferenceData service.ReferenceDataService
+	db            *gorm.DB // Still need db for query building
 }
 
 // NewFlightHandler creates a new FlightHandler
-func NewFlightHandler(flightRepo repository.FlightRepository, db *gorm.DB) *FlightHandler {
-	return &FlightHandler{FlightRepo: flightRepo, db: db}
+func NewFlightHandler(flightRepo repository.FlightRepository, db *gorm.DB, referenceData service.ReferenceDataService) *FlightHandler {
+	return &FlightHandler{FlightRepo: flightRepo, ReferenceData: referenceData, db: db}
 }
 
 // SearchFlights handles flight search requests
 func (h *FlightHandler) SearchFlights(c *gin.Context) {
 	query := h.db // Use the injected db for query building
 
+	// Unknown codes are rejected rather than silently matching nothing
 	if departure := c.Query("departure"); departure != "" {
+		if err := h.ReferenceData.ValidateAirport(departure); err != nil {
+			respondError(c, err)
+			return
+		}
 		query = query.Where("departure_airport = ?", departure)
 	}
 
 	if arrival := c.Query("arrival"); arrival != "" {
+		if err := h.ReferenceData.ValidateAirport(arrival); err != nil {
+			respondError(c, err)
+			return
+		}
 		query = query.Where("arrival_airport = ?", arrival)
 	}
 
-	if airline := c.Query("airline"); airline != "" {
-		query = query.Where("airline = ?", airline)
+	// Flights store the airline's name; the filter also accepts its IATA or ICAO code
+	if airlineParam := c.Query("airline"); airlineParam != "" {
+		airline, err := h.ReferenceData.ResolveAirline(airlineParam)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		query = query.Where("airline = ?", airline.Name)
 	}
 
+	// The date is the local date at the departure airport. Local times are stored as
+	// "YYYY-MM-DD HH:MM", so a day is a string range that can use the search index.
 	dateStr := c.Query("date")
 	if dateStr != "" {
-		_, err := time.Parse("2006-01-02", dateStr)
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			respondBadRequest(c, "Invalid date format. Expected YYYY-MM-DD")
+			return
+		}
+		query = query.Where("departure_local_time >= ? AND departure_local_time < ?", dateStr, date.AddDate(0, 0, 1).Format("2006-01-02"))
+	}
+
+	minPrice, hasMinPrice, err := parseFloatQuery(c, "min_price")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if hasMinPrice {
+		query = query.Where("price >= ?", minPrice)
+	}
+
+	maxPrice, hasMaxPrice, err := parseFloatQuery(c, "max_price")
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	if hasMaxPrice {
+		if hasMinPrice && maxPrice < minPrice {
+			respondBadRequest(c, "Invalid price range. max_price must not be less than min_price.")
+			return
+		}
+		query = query.Where("price <= ?", maxPrice)
+	}
+
+	// Local time-of-day window at departure, e.g. departure_after=06:00&departure_before=12:00.
+	// The time of departure_local_time is characters 12-16 in every supported database.
+	if after := c.Query("departure_after"); after != "" {
+		if _, err := time.Parse("15:04", after); err != nil {
+			respondBadRequest(c, "Invalid departure_after parameter. Expected HH:MM")
+			return
+		}
+		query = query.Where("SUBSTR(departure_local_time, 12, 5) >= ?", after)
+	}
+
+	if before := c.Query("departure_before"); before != "" {
+		if _, err := time.Parse("15:04", before); err != nil {
+			respondBadRequest(c, "Invalid departure_before parameter. Expected HH:MM")
+			return
+		}
+		query = query.Where("SUBSTR(departure_local_time, 12, 5) <= ?", before)
+	}
+
+	if minSeatsStr := c.Query("min_seats"); minSeatsStr != "" {
+		minSeats, err := strconv.Atoi(minSeatsStr)
+		if err != nil || minSeats < 1 {
+			respondBadRequest(c, "Invalid min_seats parameter. Must be a positive integer.")
+			return
+		}
+		query = query.Where("available_seats >= ?", minSeats)
+	}
+
+	if sortBy := c.Query("sort_by"); sortBy != "" {
+		orderBy, err := buildFlightOrder(sortBy, c.Query("order"))
 		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid date format. Expected YYYY-MM-DD"})
+			respondBadRequest(c, err.Error())
 			return
 		}
-		query = query.Where("DATE(departure_time) = ?", dateStr)
+		query = query.Order(orderBy)
 	}
 
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
 	if err != nil || page < 1 {
-		c.JSON(400, gin.H{"error": "Invalid page parameter. Must be a positive integer."})
+		respondBadRequest(c, "Invalid page parameter. Must be a positive integer.")
 		return
 	}
 
 	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 	if err != nil || pageSize < 1 {
-		c.JSON(400, gin.H{"error": "Invalid page_size parameter. Must be a positive integer."})
+		respondBadRequest(c, "Invalid page_size parameter. Must be a positive integer.")
 		return
 	}
 
 	// TODO: 設定分頁參數的預設值與最大值，避免過大查詢影響效能。
 	// TODO: 可以考慮限制 page_size 最大值，例如 100
-	// TODO: 支援排序參數 sort_by（如 departure_time, price）與 order（asc/desc）
-	// TODO: 若未來有新需求，可支援多欄位排序或複合查詢
 
 	flights, total, err := h.FlightRepo.FindAll(query, page, pageSize)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Internal Server Error"})
+		respondError(c, err)
 		return
 	}
 
 	// Convert models.Flight to FlightSearchItem to exclude specific fields
 	var searchItems []FlightSearchItem
 	for _, flight := range flights {
-		searchItems = append(searchItems, FlightSearchItem{
-			ID:               flight.ID,
-			DepartureAirport: flight.DepartureAirport,
-			ArrivalAirport:   flight.ArrivalAirport,
-			DepartureTime:    flight.DepartureTime,
-			ArrivalTime:      flight.ArrivalTime,
-			Airline:          flight.Airline,
-			Price:            flight.Price,
-		})
+		item := newFlightSearchItem(flight)
+		if class := service.CheapestAvailableFareClass(flight.FareClasses); class != nil {
+			item.FareClass = class.Name
+			item.Price = class.Price
+		}
+		searchItems = append(searchItems, item)
 	}
 
 	c.JSON(200, SearchFlightsResponse{
@@ -111,19 +201,92 @@ func (h *FlightHandler) SearchFlights(c *gin.Context) {
 	})
 }
 
+// newFlightSearchItem converts a flight to its search result
+func newFlightSearchItem(flight models.Flight) FlightSearchItem {
+	return FlightSearchItem{
+		ID:               flight.ID,
+		DepartureAirport: flight.DepartureAirport,
+		ArrivalAirport:   flight.ArrivalAirport,
+		DepartureTime:    flight.DepartureLocalTime,
+		ArrivalTime:      flight.ArrivalLocalTime,
+		DepartureTimeUTC: flight.DepartureTime,
+		ArrivalTimeUTC:   flight.ArrivalTime,
+		DurationMinutes:  flight.DurationMinutes,
+		Airline:          flight.Airline,
+		Price:            flight.Price,
+		Status:           flight.Status,
+		EstimatedTime:    flight.EstimatedDepartureLocalTime,
+	}
+}
+
 // GetFlight handles requests to get a single flight by ID
 func (h *FlightHandler) GetFlight(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid flight ID"})
+		respondBadRequest(c, "Invalid flight ID")
 		return
 	}
 
 	flight, err := h.FlightRepo.FindByID(uint(id))
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Flight not found"})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = service.NewNotFoundError(service.CodeFlightNotFound, "Flight not found")
+		}
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(200, flight)
 }
+
+// parseFloatQuery parses an optional non-negative float query parameter
+func parseFloatQuery(c *gin.Context, key string) (float64, bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, false, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 {
+		return 0, false, fmt.Errorf("Invalid %s parameter. Must be a non-negative number.", key)
+	}
+	return value, true, nil
+}
+
+// buildFlightOrder turns comma separated sort_by and order values into an ORDER BY clause.
+// A single order value applies to every key; otherwise there must be one per key.
+func buildFlightOrder(sortBy, order string) (string, error) {
+	keys := strings.Split(sortBy, ",")
+
+	var directions []string
+	if order != "" {
+		directions = strings.Split(order, ",")
+	}
+	if len(directions) > 1 && len(directions) != len(keys) {
+		return "", fmt.Errorf("Invalid order parameter. Provide one direction or one per sort_by key.")
+	}
+
+	clauses := make([]string, 0, len(keys)+1)
+	for i, key := range keys {
+		column, ok := flightSortColumns[strings.TrimSpace(key)]
+		if !ok {
+			return "", fmt.Errorf("Invalid sort_by parameter. Allowed values: price, departure_time, arrival_time, duration.")
+		}
+
+		direction := "asc"
+		if len(directions) == 1 {
+			direction = directions[0]
+		} else if len(directions) > 1 {
+			direction = directions[i]
+		}
+		direction = strings.ToLower(strings.TrimSpace(direction))
+		if direction != "asc" && direction != "desc" {
+			return "", fmt.Errorf("Invalid order parameter. Allowed values: asc, desc.")
+		}
+
+		clauses = append(clauses, column+" "+strings.ToUpper(direction))
+	}
+
+	// Tie-break on id so pagination is stable
+	clauses = append(clauses, "id ASC")
+	return strings.Join(clauses, ", "), nil
+}
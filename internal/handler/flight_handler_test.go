@@ -4,13 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"flight-booking/internal/models"
+	"flight-booking/internal/repository"
+	"flight-booking/internal/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -51,15 +55,15 @@ func setupFlightTestRouter(flightHandler *FlightHandler) *gin.Engine {
 func successFindAll(query *gorm.DB, page, pageSize int) ([]models.Flight, int64, error) {
 	return []models.Flight{
 		{
-			Model:            gorm.Model{ID: 1},
-			DepartureAirport: "Taipei",
-			ArrivalAirport:   "Tokyo",
-			DepartureTime:    "2025-08-01 10:00",
-			ArrivalTime:      "2025-08-01 14:00",
-			Airline:          "EVA Air",
-			FlightNumber:     "BR101",
-			Price:            500,
-			AvailableSeats:   100,
+			Model:              gorm.Model{ID: 1},
+			DepartureAirport:   "TPE",
+			ArrivalAirport:     "NRT",
+			DepartureLocalTime: "2025-08-01 10:00",
+			ArrivalLocalTime:   "2025-08-01 14:00",
+			Airline:            "EVA Air",
+			FlightNumber:       "BR101",
+			Price:              500,
+			AvailableSeats:     100,
 		},
 	}, 1, nil
 }
@@ -68,30 +72,32 @@ func successFindAll(query *gorm.DB, page, pageSize int) ([]models.Flight, int64,
 func TestSearchFlights_Success(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
+	mockReferenceData := new(MockReferenceDataService)
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	handler := NewFlightHandler(mockRepo, db) // Pass a dummy DB instance for handler's internal query building
+	handler := NewFlightHandler(mockRepo, db, mockReferenceData) // Pass a dummy DB instance for handler's internal query building
 
 	router := setupFlightTestRouter(handler)
 
 	expectedFlights := []models.Flight{
 		{
-			Model:            gorm.Model{ID: 1},
-			DepartureAirport: "Taipei",
-			ArrivalAirport:   "Tokyo",
-			DepartureTime:    "2025-08-01 10:00",
-			ArrivalTime:      "2025-08-01 14:00",
-			Airline:          "EVA Air",
-			FlightNumber:     "BR101",
-			Price:            500,
-			AvailableSeats:   100,
+			Model:              gorm.Model{ID: 1},
+			DepartureAirport:   "TPE",
+			ArrivalAirport:     "NRT",
+			DepartureLocalTime: "2025-08-01 10:00",
+			ArrivalLocalTime:   "2025-08-01 14:00",
+			Airline:            "EVA Air",
+			FlightNumber:       "BR101",
+			Price:              500,
+			AvailableSeats:     100,
 		},
 	}
 	expectedTotal := int64(1)
 
 	// Mock the FindAll method. The first argument (query *gorm.DB) is hard to match precisely,
 	mockRepo.On("FindAll", mock.Anything, mock.Anything, mock.Anything).Return(successFindAll(db, 1, 10)).Once()
+	mockReferenceData.On("ValidateAirport", "TPE").Return(nil).Once()
 
-	req, _ := http.NewRequest("GET", "/flights?departure=Taipei&date=2025-08-01&page=1&page_size=10", nil)
+	req, _ := http.NewRequest("GET", "/flights?departure=TPE&date=2025-08-01&page=1&page_size=10", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -107,6 +113,81 @@ func TestSearchFlights_Success(t *testing.T) {
 	assert.Equal(t, expectedFlights[0].DepartureAirport, response.Data[0].DepartureAirport)
 	assert.Equal(t, expectedFlights[0].Price, response.Data[0].Price)
 
+	mockRepo.AssertExpectations(t)
+	mockReferenceData.AssertExpectations(t)
+}
+
+// TestSearchFlights_UnknownCodes tests that unknown airports and airlines are rejected
+func TestSearchFlights_UnknownCodes(t *testing.T) {
+	// Given
+	mockRepo := new(MockFlightRepository)
+	mockReferenceData := new(MockReferenceDataService)
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, mockReferenceData)
+
+	router := setupFlightTestRouter(handler)
+
+	mockReferenceData.On("ValidateAirport", "XXX").
+		Return(service.NewValidationError(service.CodeUnknownAirport, "unknown airport XXX")).Once()
+	mockReferenceData.On("ResolveAirline", "ZZ").
+		Return(nil, service.NewValidationError(service.CodeUnknownAirline, "unknown airline ZZ")).Once()
+
+	cases := map[string]string{
+		"/flights?departure=XXX": service.CodeUnknownAirport,
+		"/flights?airline=ZZ":    service.CodeUnknownAirline,
+	}
+
+	for url, code := range cases {
+		// When
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+		assertErrorCode(t, w, code)
+	}
+
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything, mock.Anything)
+	mockReferenceData.AssertExpectations(t)
+}
+
+// TestSearchFlights_CheapestFareClass tests that results show the cheapest class with a free seat
+func TestSearchFlights_CheapestFareClass(t *testing.T) {
+	// Given
+	mockRepo := new(MockFlightRepository)
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	handler := NewFlightHandler(mockRepo, db, new(MockReferenceDataService))
+
+	router := setupFlightTestRouter(handler)
+
+	flights := []models.Flight{
+		{
+			Model:          gorm.Model{ID: 1},
+			FlightNumber:   "BR101",
+			Price:          800,
+			AvailableSeats: 4,
+			FareClasses: []models.FareClass{
+				{Name: "Economy", Price: 300, AvailableSeats: 0},
+				{Name: "Premium", Price: 800, AvailableSeats: 4},
+				{Name: "Business", Price: 1500, AvailableSeats: 0},
+			},
+		},
+	}
+	mockRepo.On("FindAll", mock.Anything, 1, 10).Return(flights, int64(1), nil).Once()
+
+	// When
+	req, _ := http.NewRequest("GET", "/flights", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response SearchFlightsResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, "Premium", response.Data[0].FareClass)
+	assert.Equal(t, 800.0, response.Data[0].Price)
+
 	mockRepo.AssertExpectations(t)
 }
 
@@ -114,7 +195,7 @@ func TestSearchFlights_Success(t *testing.T) {
 func TestSearchFlights_InvalidDate(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
-	handler := NewFlightHandler(mockRepo, &gorm.DB{})
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
 
 	router := setupFlightTestRouter(handler)
 
@@ -133,7 +214,7 @@ func TestSearchFlights_InvalidDate(t *testing.T) {
 func TestSearchFlights_InvalidPageParams(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
-	handler := NewFlightHandler(mockRepo, &gorm.DB{})
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
 
 	router := setupFlightTestRouter(handler)
 
@@ -158,7 +239,7 @@ func TestSearchFlights_InvalidPageParams(t *testing.T) {
 func TestSearchFlights_InternalError(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
-	handler := NewFlightHandler(mockRepo, &gorm.DB{})
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
 
 	router := setupFlightTestRouter(handler)
 
@@ -179,7 +260,7 @@ func TestSearchFlights_InternalError(t *testing.T) {
 func TestGetFlight_Success(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
-	handler := NewFlightHandler(mockRepo, &gorm.DB{})
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
 
 	router := setupFlightTestRouter(handler)
 
@@ -213,7 +294,7 @@ func TestGetFlight_Success(t *testing.T) {
 func TestGetFlight_InvalidID(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
-	handler := NewFlightHandler(mockRepo, &gorm.DB{})
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
 
 	router := setupFlightTestRouter(handler)
 
@@ -232,7 +313,7 @@ func TestGetFlight_InvalidID(t *testing.T) {
 func TestGetFlight_NotFound(t *testing.T) {
 	// Given
 	mockRepo := new(MockFlightRepository)
-	handler := NewFlightHandler(mockRepo, &gorm.DB{})
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
 
 	router := setupFlightTestRouter(handler)
 
@@ -246,6 +327,130 @@ func TestGetFlight_NotFound(t *testing.T) {
 	// Then
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	assert.Contains(t, w.Body.String(), "Flight not found")
+	assertErrorCode(t, w, service.CodeFlightNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetFlight_InternalError tests that repository failures other than not found are not reported as 404
+func TestGetFlight_InternalError(t *testing.T) {
+	// Given
+	mockRepo := new(MockFlightRepository)
+	handler := NewFlightHandler(mockRepo, &gorm.DB{}, new(MockReferenceDataService))
+
+	router := setupFlightTestRouter(handler)
+
+	flightID := uint(1)
+	mockRepo.On("FindByID", flightID).Return((*models.Flight)(nil), errors.New("database error")).Once()
+
+	req, _ := http.NewRequest("GET", "/flights/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assertErrorCode(t, w, CodeInternalError)
 
 	mockRepo.AssertExpectations(t)
 }
+
+// TestSearchFlights_SortAndFilter tests sorting and range filters against a real database
+func TestSearchFlights_SortAndFilter(t *testing.T) {
+	// Given
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // An in-memory database only lives as long as its connection
+	db.AutoMigrate(&models.Flight{}, &models.FareClass{})
+
+	db.Create(&[]models.Flight{
+		{FlightNumber: "A", DepartureLocalTime: "2025-08-01 07:00", ArrivalLocalTime: "2025-08-01 12:00", DurationMinutes: 300, Price: 300, AvailableSeats: 10},
+		{FlightNumber: "B", DepartureLocalTime: "2025-08-01 09:00", ArrivalLocalTime: "2025-08-01 11:00", DurationMinutes: 120, Price: 300, AvailableSeats: 10},
+		{FlightNumber: "C", DepartureLocalTime: "2025-08-01 10:00", ArrivalLocalTime: "2025-08-01 13:00", DurationMinutes: 180, Price: 200, AvailableSeats: 10},
+		{FlightNumber: "D", DepartureLocalTime: "2025-08-01 05:00", ArrivalLocalTime: "2025-08-01 07:00", DurationMinutes: 120, Price: 100, AvailableSeats: 10},
+		{FlightNumber: "E", DepartureLocalTime: "2025-08-01 08:00", ArrivalLocalTime: "2025-08-01 10:00", DurationMinutes: 120, Price: 900, AvailableSeats: 10},
+		{FlightNumber: "F", DepartureLocalTime: "2025-08-01 08:00", ArrivalLocalTime: "2025-08-01 10:00", DurationMinutes: 120, Price: 250, AvailableSeats: 1},
+	})
+
+	handler := NewFlightHandler(repository.NewGORMFlightRepository(db), db, new(MockReferenceDataService))
+	router := setupFlightTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/flights?min_price=150&max_price=500&departure_after=06:00&departure_before=12:00&min_seats=2&sort_by=price,duration&order=desc,asc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response SearchFlightsResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(3), response.Total)
+
+	var order []uint
+	for _, item := range response.Data {
+		order = append(order, item.ID)
+	}
+	// B and A share a price, so the shorter B comes first; then the cheaper C
+	assert.Equal(t, []uint{2, 1, 3}, order)
+}
+
+// TestSearchFlights_LocalDate tests that the date filter uses the local date at the
+// departure airport rather than the UTC date
+func TestSearchFlights_LocalDate(t *testing.T) {
+	// Given two flights departing on August 2 in UTC; only the first is on August 2 in Taipei
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	db.AutoMigrate(&models.Flight{}, &models.FareClass{})
+
+	db.Create(&[]models.Flight{
+		{FlightNumber: "A", DepartureTime: time.Date(2025, 8, 2, 1, 0, 0, 0, time.UTC), DepartureLocalTime: "2025-08-02 09:00", AvailableSeats: 10},
+		{FlightNumber: "B", DepartureTime: time.Date(2025, 8, 2, 17, 0, 0, 0, time.UTC), DepartureLocalTime: "2025-08-03 01:00", AvailableSeats: 10},
+	})
+
+	handler := NewFlightHandler(repository.NewGORMFlightRepository(db), db, new(MockReferenceDataService))
+	router := setupFlightTestRouter(handler)
+
+	// When
+	req, _ := http.NewRequest("GET", "/flights?date=2025-08-02", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response SearchFlightsResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "2025-08-02 09:00", response.Data[0].DepartureTime)
+	assert.True(t, time.Date(2025, 8, 2, 1, 0, 0, 0, time.UTC).Equal(response.Data[0].DepartureTimeUTC))
+}
+
+// TestSearchFlights_InvalidSortAndFilters tests that sort and filter parameters are validated
+func TestSearchFlights_InvalidSortAndFilters(t *testing.T) {
+	// Given
+	mockRepo := new(MockFlightRepository)
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	handler := NewFlightHandler(mockRepo, db, new(MockReferenceDataService))
+
+	router := setupFlightTestRouter(handler)
+
+	cases := map[string]string{
+		"/flights?sort_by=id":                         "Invalid sort_by parameter",
+		"/flights?sort_by=price%20desc":               "Invalid sort_by parameter",
+		"/flights?sort_by=price&order=up":             "Invalid order parameter",
+		"/flights?sort_by=price,duration&order=a,b,c": "Invalid order parameter",
+		"/flights?min_price=abc":                      "Invalid min_price parameter",
+		"/flights?min_price=500&max_price=100":        "Invalid price range",
+		"/flights?departure_after=25:00":              "Invalid departure_after parameter",
+		"/flights?min_seats=0":                        "Invalid min_seats parameter",
+	}
+
+	for url, message := range cases {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+		assert.Contains(t, w.Body.String(), message, url)
+	}
+
+	mockRepo.AssertNotCalled(t, "FindAll", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"flight-booking/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateHoldRequest is the request body for reserving seats before checkout
+type CreateHoldRequest struct {
+	FlightID  uint   `json:"flight_id" binding:"required"`
+	FareClass string `json:"fare_class"` // defaults to the cheapest class with enough seats
+	Quantity  int    `json:"quantity"`
+}
+
+// HoldHandler handles seat hold HTTP requests
+type HoldHandler struct {
+	HoldService service.HoldService
+}
+
+// NewHoldHandler creates a new HoldHandler
+func NewHoldHandler(holdService service.HoldService) *HoldHandler {
+	return &HoldHandler{HoldService: holdService}
+}
+
+// CreateHold reserves seats on a flight and returns the hold token to book with
+func (h *HoldHandler) CreateHold(c *gin.Context) {
+	var req CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	if req.Quantity <= 0 {
+		respondBadRequest(c, "Quantity must be a positive integer")
+		return
+	}
+
+	hold, err := h.HoldService.CreateHold(req.FlightID, req.FareClass, req.Quantity)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, hold)
+}
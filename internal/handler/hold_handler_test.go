@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHoldService is a mock implementation of HoldService interface
+type MockHoldService struct {
+	mock.Mock
+}
+
+func (m *MockHoldService) CreateHold(flightID uint, fareClass string, quantity int) (*models.SeatHold, error) {
+	args := m.Called(flightID, fareClass, quantity)
+	return args.Get(0).(*models.SeatHold), args.Error(1)
+}
+
+func (m *MockHoldService) ReleaseExpiredHolds(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupHoldTestRouter(holdHandler *HoldHandler) *gin.Engine {
+	r := gin.Default()
+	r.POST("/holds", holdHandler.CreateHold)
+	return r
+}
+
+// TestCreateHold_Success tests a successful seat hold
+func TestCreateHold_Success(t *testing.T) {
+	// Given
+	mockService := new(MockHoldService)
+	handler := NewHoldHandler(mockService)
+
+	router := setupHoldTestRouter(handler)
+
+	expectedHold := models.SeatHold{
+		Token:      "0123456789abcdef0123456789abcdef",
+		FlightID:   1,
+		Quantity:   2,
+		HoldStatus: service.HoldStatusActive,
+		ExpiresAt:  time.Now().Add(15 * time.Minute),
+	}
+	mockService.On("CreateHold", uint(1), "", 2).Return(&expectedHold, nil).Once()
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{"flight_id": 1, "quantity": 2})
+
+	// When
+	req, _ := http.NewRequest("POST", "/holds", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseHold models.SeatHold
+	json.Unmarshal(w.Body.Bytes(), &responseHold)
+	assert.Equal(t, expectedHold.Token, responseHold.Token)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateHold_InsufficientSeats tests holding more seats than are free
+func TestCreateHold_InsufficientSeats(t *testing.T) {
+	// Given
+	mockService := new(MockHoldService)
+	handler := NewHoldHandler(mockService)
+
+	router := setupHoldTestRouter(handler)
+
+	mockService.On("CreateHold", uint(1), "", 5).Return((*models.SeatHold)(nil), service.NewInsufficientSeatsError("not enough seats to hold: available=2")).Once()
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{"flight_id": 1, "quantity": 5})
+
+	// When
+	req, _ := http.NewRequest("POST", "/holds", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrorCode(t, w, service.CodeInsufficientSeats)
+
+	mockService.AssertExpectations(t)
+}
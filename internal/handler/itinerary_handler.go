@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"flight-booking/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ItineraryItem represents a connecting itinerary in the search results
+type ItineraryItem struct {
+	Stops                int                `json:"stops"`
+	TotalPrice           float64            `json:"total_price"`
+	TotalDurationMinutes int                `json:"total_duration_minutes"`
+	DepartureTime        string             `json:"departure_time"` // local time at the origin
+	ArrivalTime          string             `json:"arrival_time"`   // local time at the destination
+	DepartureTimeUTC     time.Time          `json:"departure_time_utc"`
+	ArrivalTimeUTC       time.Time          `json:"arrival_time_utc"`
+	Segments             []FlightSearchItem `json:"segments"`
+}
+
+// SearchItinerariesResponse is the full response structure for itinerary search
+type SearchItinerariesResponse struct {
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Data     []ItineraryItem `json:"data"`
+}
+
+// ItineraryHandler handles connecting-itinerary HTTP requests
+type ItineraryHandler struct {
+	ItineraryService service.ItineraryService
+}
+
+// NewItineraryHandler creates a new ItineraryHandler
+func NewItineraryHandler(itineraryService service.ItineraryService) *ItineraryHandler {
+	return &ItineraryHandler{ItineraryService: itineraryService}
+}
+
+// SearchItineraries handles requests for direct and connecting itineraries
+func (h *ItineraryHandler) SearchItineraries(c *gin.Context) {
+	query := service.ItineraryQuery{
+		DepartureAirport: c.Query("departure"),
+		ArrivalAirport:   c.Query("arrival"),
+		SortBy:           c.DefaultQuery("sort_by", service.ItinerarySortDuration),
+	}
+
+	if query.DepartureAirport == "" || query.ArrivalAirport == "" {
+		respondBadRequest(c, "departure and arrival are required")
+		return
+	}
+	if query.DepartureAirport == query.ArrivalAirport {
+		respondBadRequest(c, "departure and arrival must differ")
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		respondBadRequest(c, "Invalid date format. Expected YYYY-MM-DD")
+		return
+	}
+	query.Date = date
+
+	maxStops, err := strconv.Atoi(c.DefaultQuery("max_stops", "2"))
+	if err != nil || maxStops < 0 || maxStops > 2 {
+		respondBadRequest(c, "Invalid max_stops parameter. Must be 0, 1 or 2.")
+		return
+	}
+	query.MaxStops = maxStops
+
+	minLayover, err := strconv.Atoi(c.DefaultQuery("min_layover", "45"))
+	if err != nil || minLayover < 0 {
+		respondBadRequest(c, "Invalid min_layover parameter. Must be a non-negative number of minutes.")
+		return
+	}
+	maxLayover, err := strconv.Atoi(c.DefaultQuery("max_layover", "360"))
+	if err != nil || maxLayover < minLayover || maxLayover > 24*60 {
+		respondBadRequest(c, "Invalid max_layover parameter. Must be between min_layover and 1440 minutes.")
+		return
+	}
+	query.MinLayover = time.Duration(minLayover) * time.Minute
+	query.MaxLayover = time.Duration(maxLayover) * time.Minute
+
+	passengers, err := strconv.Atoi(c.DefaultQuery("passengers", "1"))
+	if err != nil || passengers < 1 {
+		respondBadRequest(c, "Invalid passengers parameter. Must be a positive integer.")
+		return
+	}
+	query.Passengers = passengers
+
+	if query.SortBy != service.ItinerarySortDuration && query.SortBy != service.ItinerarySortPrice {
+		respondBadRequest(c, "Invalid sort_by parameter. Allowed values: duration, price.")
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		respondBadRequest(c, "Invalid page parameter. Must be a positive integer.")
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 {
+		respondBadRequest(c, "Invalid page_size parameter. Must be a positive integer.")
+		return
+	}
+
+	itineraries, err := h.ItineraryService.SearchItineraries(query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Itineraries are built in memory, so pagination is applied to the sorted result
+	start := (page - 1) * pageSize
+	if start > len(itineraries) {
+		start = len(itineraries)
+	}
+	end := start + pageSize
+	if end > len(itineraries) {
+		end = len(itineraries)
+	}
+
+	items := []ItineraryItem{}
+	for _, itinerary := range itineraries[start:end] {
+		item := ItineraryItem{
+			Stops:                itinerary.Stops,
+			TotalPrice:           itinerary.TotalPrice,
+			TotalDurationMinutes: int(itinerary.TotalDuration.Minutes()),
+			DepartureTime:        itinerary.Segments[0].DepartureLocalTime,
+			ArrivalTime:          itinerary.Segments[len(itinerary.Segments)-1].ArrivalLocalTime,
+			DepartureTimeUTC:     itinerary.DepartureTime,
+			ArrivalTimeUTC:       itinerary.ArrivalTime,
+		}
+		for _, flight := range itinerary.Segments {
+			item.Segments = append(item.Segments, newFlightSearchItem(flight))
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(200, SearchItinerariesResponse{
+		Total:    int64(len(itineraries)),
+		Page:     page,
+		PageSize: pageSize,
+		Data:     items,
+	})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockItineraryService is a mock implementation of ItineraryService interface
+type MockItineraryService struct {
+	mock.Mock
+}
+
+func (m *MockItineraryService) SearchItineraries(query service.ItineraryQuery) ([]service.Itinerary, error) {
+	args := m.Called(query)
+	return args.Get(0).([]service.Itinerary), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupItineraryTestRouter(itineraryHandler *ItineraryHandler) *gin.Engine {
+	r := gin.Default()
+	r.GET("/itineraries", itineraryHandler.SearchItineraries)
+	return r
+}
+
+// TestSearchItineraries_Success tests a successful itinerary search
+func TestSearchItineraries_Success(t *testing.T) {
+	// Given
+	mockService := new(MockItineraryService)
+	handler := NewItineraryHandler(mockService)
+
+	router := setupItineraryTestRouter(handler)
+
+	departure := time.Date(2025, 7, 31, 23, 0, 0, 0, time.UTC) // 07:00 in Kaohsiung
+	itinerary := service.Itinerary{
+		Segments: []models.Flight{
+			{Model: gorm.Model{ID: 1}, DepartureAirport: "KHH", ArrivalAirport: "TPE", DepartureLocalTime: "2025-08-01 07:00", Price: 100},
+			{Model: gorm.Model{ID: 2}, DepartureAirport: "TPE", ArrivalAirport: "CTS", ArrivalLocalTime: "2025-08-01 14:00", Price: 300},
+		},
+		Stops:         1,
+		TotalPrice:    400,
+		TotalDuration: 6 * time.Hour,
+		DepartureTime: departure,
+		ArrivalTime:   departure.Add(6 * time.Hour),
+	}
+
+	mockService.On("SearchItineraries", mock.MatchedBy(func(q service.ItineraryQuery) bool {
+		return q.DepartureAirport == "KHH" && q.ArrivalAirport == "CTS" &&
+			q.MaxStops == 1 && q.MinLayover == 60*time.Minute && q.SortBy == service.ItinerarySortPrice
+	})).Return([]service.Itinerary{itinerary}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/itineraries?departure=KHH&arrival=CTS&date=2025-08-01&max_stops=1&min_layover=60&sort_by=price", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response SearchItinerariesResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, 360, response.Data[0].TotalDurationMinutes)
+	assert.Equal(t, "2025-08-01 07:00", response.Data[0].DepartureTime)
+	assert.Equal(t, "2025-08-01 14:00", response.Data[0].ArrivalTime)
+	assert.True(t, departure.Equal(response.Data[0].DepartureTimeUTC))
+	assert.Len(t, response.Data[0].Segments, 2)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestSearchItineraries_InvalidParams tests that itinerary search parameters are validated
+func TestSearchItineraries_InvalidParams(t *testing.T) {
+	// Given
+	mockService := new(MockItineraryService)
+	handler := NewItineraryHandler(mockService)
+
+	router := setupItineraryTestRouter(handler)
+
+	cases := map[string]string{
+		"/itineraries?arrival=CTS&date=2025-08-01":                              "departure and arrival are required",
+		"/itineraries?departure=KHH&arrival=CTS":                                "Invalid date format",
+		"/itineraries?departure=KHH&arrival=CTS&date=2025-08-01&max_stops=3":    "Invalid max_stops parameter",
+		"/itineraries?departure=KHH&arrival=CTS&date=2025-08-01&max_layover=30": "Invalid max_layover parameter",
+		"/itineraries?departure=KHH&arrival=CTS&date=2025-08-01&sort_by=stops":  "Invalid sort_by parameter",
+		"/itineraries?departure=KHH&arrival=CTS&date=2025-08-01&passengers=0":   "Invalid passengers parameter",
+	}
+
+	for url, message := range cases {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+		assert.Contains(t, w.Body.String(), message, url)
+	}
+
+	mockService.AssertNotCalled(t, "SearchItineraries", mock.Anything)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderSegmentRequest is one flight in a CreateOrderRequest
+type OrderSegmentRequest struct {
+	FlightID  uint   `json:"flight_id" binding:"required"`
+	FareClass string `json:"fare_class"` // defaults to the cheapest class with enough seats
+}
+
+// PassengerRequest is a traveler in a CreateOrderRequest or CreateBookingRequest
+type PassengerRequest struct {
+	Name           string `json:"name"`
+	DateOfBirth    string `json:"date_of_birth"`
+	DocumentNumber string `json:"document_number"`
+	PassengerType  string `json:"passenger_type"`
+}
+
+// CreateOrderRequest is the request body for booking several flights as one order.
+// The same passengers travel on every segment.
+type CreateOrderRequest struct {
+	PassengerName string                `json:"passenger_name" binding:"required"`
+	ContactEmail  string                `json:"contact_email"`
+	Quantity      int                   `json:"quantity"`
+	Passengers    []PassengerRequest    `json:"passengers"`
+	Segments      []OrderSegmentRequest `json:"segments" binding:"required,dive"`
+}
+
+// OrderResponse is an order with the passengers of its bookings redacted, as
+// returned by GET /orders/:id
+type OrderResponse struct {
+	*models.Order
+	Bookings []BookingResponse `json:"bookings"`
+}
+
+// newOrderResponse redacts the passengers of the order's bookings
+func newOrderResponse(order *models.Order) OrderResponse {
+	resp := OrderResponse{Order: order, Bookings: make([]BookingResponse, 0, len(order.Bookings))}
+	for i := range order.Bookings {
+		resp.Bookings = append(resp.Bookings, newBookingResponse(&order.Bookings[i]))
+	}
+	return resp
+}
+
+// OrderHandler handles order-related HTTP requests
+type OrderHandler struct {
+	OrderService service.OrderService
+}
+
+// NewOrderHandler creates a new OrderHandler
+func NewOrderHandler(orderService service.OrderService) *OrderHandler {
+	return &OrderHandler{OrderService: orderService}
+}
+
+// CreateOrder handles round-trip and multi-city booking requests
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	if req.Quantity <= 0 {
+		respondBadRequest(c, "Quantity must be a positive integer")
+		return
+	}
+
+	order := models.Order{
+		PassengerName: req.PassengerName,
+		Quantity:      req.Quantity,
+	}
+	for _, segment := range req.Segments {
+		// Each segment gets its own passenger records so fares are priced per flight
+		booking := models.Booking{FlightID: segment.FlightID, FareClass: segment.FareClass, ContactEmail: req.ContactEmail}
+		for _, p := range req.Passengers {
+			booking.Passengers = append(booking.Passengers, models.Passenger{
+				Name:           p.Name,
+				DateOfBirth:    p.DateOfBirth,
+				DocumentNumber: p.DocumentNumber,
+				PassengerType:  p.PassengerType,
+			})
+		}
+		order.Bookings = append(order.Bookings, booking)
+	}
+
+	createdOrder, err := h.OrderService.CreateOrder(&order)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, createdOrder)
+}
+
+// GetOrder handles requests to get a single order by ID
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid order ID")
+		return
+	}
+
+	order, err := h.OrderService.GetOrder(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, newOrderResponse(order))
+}
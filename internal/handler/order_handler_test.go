@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockOrderService is a mock implementation of OrderService interface
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) CreateOrder(order *models.Order) (*models.Order, error) {
+	args := m.Called(order)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrder(id uint) (*models.Order, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupOrderTestRouter(orderHandler *OrderHandler) *gin.Engine {
+	r := gin.Default()
+	r.POST("/orders", orderHandler.CreateOrder)
+	r.GET("/orders/:id", orderHandler.GetOrder)
+	return r
+}
+
+// TestCreateOrder_Success tests a successful round-trip order
+func TestCreateOrder_Success(t *testing.T) {
+	// Given
+	mockService := new(MockOrderService)
+	handler := NewOrderHandler(mockService)
+
+	router := setupOrderTestRouter(handler)
+
+	expectedOrder := models.Order{
+		Model:         gorm.Model{ID: 1},
+		PassengerName: "Test User",
+		Quantity:      2,
+		TotalPrice:    1100,
+		OrderStatus:   "Confirmed",
+	}
+
+	mockService.On("CreateOrder", mock.MatchedBy(func(o *models.Order) bool {
+		return len(o.Bookings) == 2 && o.Bookings[0].FlightID == 1 && o.Bookings[1].FlightID == 2
+	})).Return(&expectedOrder, nil).Once()
+
+	body := `{"passenger_name": "Test User", "quantity": 2, "segments": [{"flight_id": 1}, {"flight_id": 2}]}`
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseOrder models.Order
+	json.Unmarshal(w.Body.Bytes(), &responseOrder)
+	assert.Equal(t, expectedOrder.ID, responseOrder.ID)
+	assert.Equal(t, expectedOrder.TotalPrice, responseOrder.TotalPrice)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateOrder_InvalidQuantity tests order creation with invalid quantity
+func TestCreateOrder_InvalidQuantity(t *testing.T) {
+	// Given
+	mockService := new(MockOrderService)
+	handler := NewOrderHandler(mockService)
+
+	router := setupOrderTestRouter(handler)
+
+	body := `{"passenger_name": "Test User", "quantity": 0, "segments": [{"flight_id": 1}]}`
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Quantity must be a positive integer")
+
+	mockService.AssertNotCalled(t, "CreateOrder", mock.Anything)
+}
+
+// TestCreateOrder_NotEnoughSeats tests that a segment without seats fails the whole order
+func TestCreateOrder_NotEnoughSeats(t *testing.T) {
+	// Given
+	mockService := new(MockOrderService)
+	handler := NewOrderHandler(mockService)
+
+	router := setupOrderTestRouter(handler)
+
+	mockService.On("CreateOrder", mock.Anything).Return((*models.Order)(nil), service.NewInsufficientSeatsError("not enough seats: available=0, oversell limit=10")).Once()
+
+	body := `{"passenger_name": "Test User", "quantity": 1, "segments": [{"flight_id": 1}, {"flight_id": 2}]}`
+	req, _ := http.NewRequest("POST", "/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrorCode(t, w, service.CodeInsufficientSeats)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestGetOrder_RedactsPassengers tests that an order fetched by its sequential ID leaves
+// out the passengers' travel documents
+func TestGetOrder_RedactsPassengers(t *testing.T) {
+	// Given
+	mockService := new(MockOrderService)
+	handler := NewOrderHandler(mockService)
+
+	router := setupOrderTestRouter(handler)
+
+	passenger := models.Passenger{Name: "Chen Wei", DateOfBirth: "1990-01-01", DocumentNumber: "X1234567", PassengerType: "Adult"}
+	order := models.Order{
+		Model:         gorm.Model{ID: 1},
+		PassengerName: "Chen Wei",
+		OrderStatus:   "Confirmed",
+		Bookings: []models.Booking{
+			{FlightID: 1, Passengers: []models.Passenger{passenger}},
+			{FlightID: 2, Passengers: []models.Passenger{passenger}},
+		},
+	}
+	mockService.On("GetOrder", uint(1)).Return(&order, nil).Once()
+
+	// When
+	req, _ := http.NewRequest("GET", "/orders/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "document_number")
+	assert.NotContains(t, w.Body.String(), "date_of_birth")
+
+	var response models.Order
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Confirmed", response.OrderStatus)
+	if assert.Len(t, response.Bookings, 2) {
+		assert.Equal(t, uint(2), response.Bookings[1].FlightID)
+		assert.Equal(t, "Chen Wei", response.Bookings[1].Passengers[0].Name)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+// TestGetOrder_NotFound tests retrieval of a non-existent order
+func TestGetOrder_NotFound(t *testing.T) {
+	// Given
+	mockService := new(MockOrderService)
+	handler := NewOrderHandler(mockService)
+
+	router := setupOrderTestRouter(handler)
+
+	mockService.On("GetOrder", uint(999)).Return((*models.Order)(nil), service.NewNotFoundError(service.CodeOrderNotFound, "order not found")).Once()
+
+	req, _ := http.NewRequest("GET", "/orders/999", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertErrorCode(t, w, service.CodeOrderNotFound)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OversellRuleRequest is the request body for creating an oversell override rule.
+// Give an airline, a route or both.
+type OversellRuleRequest struct {
+	Airline          string  `json:"airline"`
+	DepartureAirport string  `json:"departure_airport"`
+	ArrivalAirport   string  `json:"arrival_airport"`
+	Policy           string  `json:"policy" binding:"required"` // Fixed, Percentage or None
+	Value            float64 `json:"value"`                     // seats for Fixed, percent of capacity for Percentage
+}
+
+// OversellRuleHandler handles oversell rule management requests from administrators
+type OversellRuleHandler struct {
+	OversellRuleService service.OversellRuleService
+}
+
+// NewOversellRuleHandler creates a new OversellRuleHandler
+func NewOversellRuleHandler(oversellRuleService service.OversellRuleService) *OversellRuleHandler {
+	return &OversellRuleHandler{OversellRuleService: oversellRuleService}
+}
+
+// CreateRule handles requests to add an oversell rule
+func (h *OversellRuleHandler) CreateRule(c *gin.Context) {
+	var req OversellRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	rule := models.OversellRule{
+		Airline:          req.Airline,
+		DepartureAirport: req.DepartureAirport,
+		ArrivalAirport:   req.ArrivalAirport,
+		Policy:           req.Policy,
+		Value:            req.Value,
+	}
+
+	created, err := h.OversellRuleService.CreateRule(&rule)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, created)
+}
+
+// ListRules handles requests to list every oversell rule
+func (h *OversellRuleHandler) ListRules(c *gin.Context) {
+	rules, err := h.OversellRuleService.ListRules()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, rules)
+}
+
+// DeleteRule handles requests to remove an oversell rule
+func (h *OversellRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid oversell rule ID")
+		return
+	}
+
+	if err := h.OversellRuleService.DeleteRule(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOversellRuleService is a mock implementation of OversellRuleService interface
+type MockOversellRuleService struct {
+	mock.Mock
+}
+
+func (m *MockOversellRuleService) CreateRule(rule *models.OversellRule) (*models.OversellRule, error) {
+	args := m.Called(rule)
+	return args.Get(0).(*models.OversellRule), args.Error(1)
+}
+
+func (m *MockOversellRuleService) ListRules() ([]models.OversellRule, error) {
+	args := m.Called()
+	return args.Get(0).([]models.OversellRule), args.Error(1)
+}
+
+func (m *MockOversellRuleService) DeleteRule(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// SetupRouter for testing
+func setupOversellRuleTestRouter(oversellRuleHandler *OversellRuleHandler) *gin.Engine {
+	r := gin.Default()
+	r.POST("/admin/oversell-rules", oversellRuleHandler.CreateRule)
+	r.GET("/admin/oversell-rules", oversellRuleHandler.ListRules)
+	r.DELETE("/admin/oversell-rules/:id", oversellRuleHandler.DeleteRule)
+	return r
+}
+
+// TestCreateOversellRule_Success tests adding a route oversell rule
+func TestCreateOversellRule_Success(t *testing.T) {
+	// Given
+	mockService := new(MockOversellRuleService)
+	handler := NewOversellRuleHandler(mockService)
+
+	router := setupOversellRuleTestRouter(handler)
+
+	rule := models.OversellRule{DepartureAirport: "TPE", ArrivalAirport: "NRT", Policy: service.OversellPolicyPercentage, Value: 5}
+	mockService.On("CreateRule", &rule).Return(&rule, nil).Once()
+
+	// When
+	body, _ := json.Marshal(OversellRuleRequest{DepartureAirport: "TPE", ArrivalAirport: "NRT", Policy: service.OversellPolicyPercentage, Value: 5})
+	req, _ := http.NewRequest("POST", "/admin/oversell-rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response models.OversellRule
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, service.OversellPolicyPercentage, response.Policy)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateOversellRule_Invalid tests that an invalid rule returns 400
+func TestCreateOversellRule_Invalid(t *testing.T) {
+	// Given
+	mockService := new(MockOversellRuleService)
+	handler := NewOversellRuleHandler(mockService)
+
+	router := setupOversellRuleTestRouter(handler)
+
+	mockService.On("CreateRule", mock.Anything).
+		Return((*models.OversellRule)(nil), service.NewValidationError(service.CodeInvalidOversellRule, "invalid oversell rule: an airline or a route is required")).Once()
+
+	// When
+	body, _ := json.Marshal(OversellRuleRequest{Policy: service.OversellPolicyNone})
+	req, _ := http.NewRequest("POST", "/admin/oversell-rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrorCode(t, w, service.CodeInvalidOversellRule)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestDeleteOversellRule_NotFound tests that deleting an unknown rule returns 404
+func TestDeleteOversellRule_NotFound(t *testing.T) {
+	// Given
+	mockService := new(MockOversellRuleService)
+	handler := NewOversellRuleHandler(mockService)
+
+	router := setupOversellRuleTestRouter(handler)
+
+	mockService.On("DeleteRule", uint(7)).Return(service.NewNotFoundError(service.CodeOversellRuleNotFound, "oversell rule not found")).Once()
+
+	// When
+	req, _ := http.NewRequest("DELETE", "/admin/oversell-rules/7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertErrorCode(t, w, service.CodeOversellRuleNotFound)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"flight-booking/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PayBookingRequest is the request body for paying a booking
+type PayBookingRequest struct {
+	// PaymentMethod is the token the client received from the payment provider
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
+
+// PaymentHandler handles payment HTTP requests
+type PaymentHandler struct {
+	PaymentService service.PaymentService
+}
+
+// NewPaymentHandler creates a new PaymentHandler
+func NewPaymentHandler(paymentService service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{PaymentService: paymentService}
+}
+
+// PayBooking handles requests to pay for a booking before its payment deadline
+func (h *PaymentHandler) PayBooking(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid booking ID")
+		return
+	}
+
+	var req PayBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	booking, err := h.PaymentService.PayBooking(uint(id), req.PaymentMethod)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, newBookingResponse(booking))
+}
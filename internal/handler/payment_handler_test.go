@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockPaymentService is a mock implementation of PaymentService interface
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) PayBooking(id uint, paymentMethod string) (*models.Booking, error) {
+	args := m.Called(id, paymentMethod)
+	return args.Get(0).(*models.Booking), args.Error(1)
+}
+
+func (m *MockPaymentService) ReleaseUnpaidBookings(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupPaymentTestRouter(paymentHandler *PaymentHandler) *gin.Engine {
+	r := gin.Default()
+	r.POST("/bookings/:id/pay", paymentHandler.PayBooking)
+	return r
+}
+
+// TestPayBooking_Success tests a successful payment
+func TestPayBooking_Success(t *testing.T) {
+	// Given
+	mockService := new(MockPaymentService)
+	handler := NewPaymentHandler(mockService)
+
+	router := setupPaymentTestRouter(handler)
+
+	expectedBooking := models.Booking{
+		Model:            gorm.Model{ID: 1},
+		BookingStatus:    "Confirmed",
+		PaymentStatus:    service.PaymentStatusPaid,
+		PaymentReference: "ch_fake_1",
+	}
+	mockService.On("PayBooking", uint(1), "tok_visa").Return(&expectedBooking, nil).Once()
+
+	jsonValue, _ := json.Marshal(map[string]string{"payment_method": "tok_visa"})
+
+	// When
+	req, _ := http.NewRequest("POST", "/bookings/1/pay", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseBooking models.Booking
+	json.Unmarshal(w.Body.Bytes(), &responseBooking)
+	assert.Equal(t, service.PaymentStatusPaid, responseBooking.PaymentStatus)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestPayBooking_Declined tests that a declined payment returns 402
+func TestPayBooking_Declined(t *testing.T) {
+	// Given
+	mockService := new(MockPaymentService)
+	handler := NewPaymentHandler(mockService)
+
+	router := setupPaymentTestRouter(handler)
+
+	mockService.On("PayBooking", uint(1), "tok_declined").Return((*models.Booking)(nil), service.NewPaymentDeclinedError("payment declined")).Once()
+
+	jsonValue, _ := json.Marshal(map[string]string{"payment_method": "tok_declined"})
+
+	// When
+	req, _ := http.NewRequest("POST", "/bookings/1/pay", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	assertErrorCode(t, w, service.CodePaymentDeclined)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestPayBooking_MissingMethod tests that a payment method is required
+func TestPayBooking_MissingMethod(t *testing.T) {
+	// Given
+	mockService := new(MockPaymentService)
+	handler := NewPaymentHandler(mockService)
+
+	router := setupPaymentTestRouter(handler)
+
+	// When
+	req, _ := http.NewRequest("POST", "/bookings/1/pay", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "PayBooking", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleRequest is the request body for creating or replacing a recurring flight schedule
+type ScheduleRequest struct {
+	FlightNumber     string  `json:"flight_number" binding:"required"`
+	Airline          string  `json:"airline" binding:"required"` // name, IATA or ICAO code
+	DepartureAirport string  `json:"departure_airport" binding:"required"`
+	ArrivalAirport   string  `json:"arrival_airport" binding:"required"`
+	DaysOfWeek       []int   `json:"days_of_week" binding:"required"`   // ISO weekdays, 1 = Monday to 7 = Sunday
+	DepartureTime    string  `json:"departure_time" binding:"required"` // HH:MM at the departure airport
+	DurationMinutes  int     `json:"duration_minutes" binding:"required"`
+	EffectiveFrom    string  `json:"effective_from" binding:"required"` // YYYY-MM-DD
+	EffectiveTo      string  `json:"effective_to"`                      // YYYY-MM-DD; empty for no end
+	Capacity         int     `json:"capacity" binding:"required"`
+	Price            float64 `json:"price" binding:"required"`
+	RefundRule       string  `json:"refund_rule"` // defaults to Standard
+}
+
+// ScheduleResponse is a schedule with what saving it did to its flights
+type ScheduleResponse struct {
+	Schedule *models.Schedule      `json:"schedule"`
+	Flights  *service.ScheduleSync `json:"flights"`
+}
+
+// ScheduleHandler handles recurring schedule requests from administrators
+type ScheduleHandler struct {
+	ScheduleService service.ScheduleService
+}
+
+// NewScheduleHandler creates a new ScheduleHandler
+func NewScheduleHandler(scheduleService service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{ScheduleService: scheduleService}
+}
+
+// CreateSchedule handles requests to add a schedule and generate its flights
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	schedule := req.toSchedule()
+	created, sync, err := h.ScheduleService.CreateSchedule(&schedule)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, ScheduleResponse{Schedule: created, Flights: sync})
+}
+
+// ListSchedules handles requests to list every schedule
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.ScheduleService.ListSchedules()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, schedules)
+}
+
+// ReplaceSchedule handles requests to change a schedule and its future flights
+func (h *ScheduleHandler) ReplaceSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid schedule ID")
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	schedule := req.toSchedule()
+	updated, sync, err := h.ScheduleService.UpdateSchedule(uint(id), &schedule)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, ScheduleResponse{Schedule: updated, Flights: sync})
+}
+
+// DeleteSchedule handles requests to end a schedule. The response lists the booked
+// flights that keep operating.
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid schedule ID")
+		return
+	}
+
+	sync, err := h.ScheduleService.DeleteSchedule(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, sync)
+}
+
+// toSchedule converts the request to a schedule, with the weekdays comma-separated
+func (req *ScheduleRequest) toSchedule() models.Schedule {
+	days := make([]string, 0, len(req.DaysOfWeek))
+	for _, day := range req.DaysOfWeek {
+		days = append(days, strconv.Itoa(day))
+	}
+	return models.Schedule{
+		FlightNumber:     req.FlightNumber,
+		Airline:          req.Airline,
+		DepartureAirport: req.DepartureAirport,
+		ArrivalAirport:   req.ArrivalAirport,
+		DaysOfWeek:       strings.Join(days, ","),
+		DepartureTime:    req.DepartureTime,
+		DurationMinutes:  req.DurationMinutes,
+		EffectiveFrom:    req.EffectiveFrom,
+		EffectiveTo:      req.EffectiveTo,
+		Capacity:         req.Capacity,
+		Price:            req.Price,
+		RefundRule:       req.RefundRule,
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockScheduleService is a mock implementation of ScheduleService interface
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) CreateSchedule(schedule *models.Schedule) (*models.Schedule, *service.ScheduleSync, error) {
+	args := m.Called(schedule)
+	return args.Get(0).(*models.Schedule), args.Get(1).(*service.ScheduleSync), args.Error(2)
+}
+
+func (m *MockScheduleService) ListSchedules() ([]models.Schedule, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Schedule), args.Error(1)
+}
+
+func (m *MockScheduleService) UpdateSchedule(id uint, schedule *models.Schedule) (*models.Schedule, *service.ScheduleSync, error) {
+	args := m.Called(id, schedule)
+	return args.Get(0).(*models.Schedule), args.Get(1).(*service.ScheduleSync), args.Error(2)
+}
+
+func (m *MockScheduleService) DeleteSchedule(id uint) (*service.ScheduleSync, error) {
+	args := m.Called(id)
+	return args.Get(0).(*service.ScheduleSync), args.Error(1)
+}
+
+func (m *MockScheduleService) GenerateFlights(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupScheduleTestRouter(scheduleHandler *ScheduleHandler) *gin.Engine {
+	r := gin.Default()
+	r.POST("/admin/schedules", scheduleHandler.CreateSchedule)
+	r.GET("/admin/schedules", scheduleHandler.ListSchedules)
+	r.PUT("/admin/schedules/:id", scheduleHandler.ReplaceSchedule)
+	r.DELETE("/admin/schedules/:id", scheduleHandler.DeleteSchedule)
+	return r
+}
+
+// newTestScheduleRequest creates a request for a Monday, Wednesday and Friday schedule
+func newTestScheduleRequest() ScheduleRequest {
+	return ScheduleRequest{
+		FlightNumber:     "BR198",
+		Airline:          "EVA Air",
+		DepartureAirport: "TPE",
+		ArrivalAirport:   "NRT",
+		DaysOfWeek:       []int{1, 3, 5},
+		DepartureTime:    "08:30",
+		DurationMinutes:  195,
+		EffectiveFrom:    "2025-08-01",
+		Capacity:         180,
+		Price:            520,
+	}
+}
+
+// TestCreateSchedule_Success tests adding a schedule and reporting the generated flights
+func TestCreateSchedule_Success(t *testing.T) {
+	// Given
+	mockService := new(MockScheduleService)
+	handler := NewScheduleHandler(mockService)
+
+	router := setupScheduleTestRouter(handler)
+
+	mockService.On("CreateSchedule", mock.MatchedBy(func(s *models.Schedule) bool {
+		return s.DaysOfWeek == "1,3,5" && s.DepartureTime == "08:30" && s.Capacity == 180
+	})).Return(&models.Schedule{FlightNumber: "BR198", DaysOfWeek: "1,3,5"}, &service.ScheduleSync{Created: 39}, nil).Once()
+
+	// When
+	body, _ := json.Marshal(newTestScheduleRequest())
+	req, _ := http.NewRequest("POST", "/admin/schedules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response ScheduleResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "BR198", response.Schedule.FlightNumber)
+	assert.Equal(t, 39, response.Flights.Created)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestReplaceSchedule_KeptFlights tests that flights kept for their bookings are reported
+func TestReplaceSchedule_KeptFlights(t *testing.T) {
+	// Given
+	mockService := new(MockScheduleService)
+	handler := NewScheduleHandler(mockService)
+
+	router := setupScheduleTestRouter(handler)
+
+	mockService.On("UpdateSchedule", uint(3), mock.Anything).
+		Return(&models.Schedule{FlightNumber: "BR198"}, &service.ScheduleSync{Updated: 10, KeptFlightIDs: []uint{42}}, nil).Once()
+
+	// When
+	body, _ := json.Marshal(newTestScheduleRequest())
+	req, _ := http.NewRequest("PUT", "/admin/schedules/3", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response ScheduleResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, []uint{42}, response.Flights.KeptFlightIDs)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateSchedule_Invalid tests that missing fields and invalid schedules return 400
+func TestCreateSchedule_Invalid(t *testing.T) {
+	// Given
+	mockService := new(MockScheduleService)
+	handler := NewScheduleHandler(mockService)
+
+	router := setupScheduleTestRouter(handler)
+
+	mockService.On("CreateSchedule", mock.Anything).
+		Return((*models.Schedule)(nil), (*service.ScheduleSync)(nil), service.NewValidationError(service.CodeInvalidSchedule, "invalid schedule: days_of_week must be ISO weekdays from 1 (Monday) to 7 (Sunday)")).Once()
+
+	// When the days are out of range
+	invalid := newTestScheduleRequest()
+	invalid.DaysOfWeek = []int{0}
+	body, _ := json.Marshal(invalid)
+	req, _ := http.NewRequest("POST", "/admin/schedules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrorCode(t, w, service.CodeInvalidSchedule)
+
+	// When the capacity is missing
+	incomplete := newTestScheduleRequest()
+	incomplete.Capacity = 0
+	body, _ = json.Marshal(incomplete)
+	req, _ = http.NewRequest("POST", "/admin/schedules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then the service is not called
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrorCode(t, w, service.CodeInvalidRequest)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestDeleteSchedule_NotFound tests that deleting an unknown schedule returns 404
+func TestDeleteSchedule_NotFound(t *testing.T) {
+	// Given
+	mockService := new(MockScheduleService)
+	handler := NewScheduleHandler(mockService)
+
+	router := setupScheduleTestRouter(handler)
+
+	mockService.On("DeleteSchedule", uint(7)).
+		Return((*service.ScheduleSync)(nil), service.NewNotFoundError(service.CodeScheduleNotFound, "schedule not found")).Once()
+
+	// When
+	req, _ := http.NewRequest("DELETE", "/admin/schedules/7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assertErrorCode(t, w, service.CodeScheduleNotFound)
+
+	mockService.AssertExpectations(t)
+}
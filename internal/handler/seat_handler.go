@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeatMapCabinRequest is a block of rows in a SeatMapRequest
+type SeatMapCabinRequest struct {
+	Name     string `json:"name" binding:"required"`
+	FirstRow int    `json:"first_row" binding:"required"`
+	LastRow  int    `json:"last_row" binding:"required"`
+}
+
+// SeatMapRequest is the request body for setting a flight's seat map
+type SeatMapRequest struct {
+	Rows         int                   `json:"rows" binding:"required"`
+	Columns      string                `json:"columns" binding:"required"` // e.g. "ABCDEF"
+	Cabins       []SeatMapCabinRequest `json:"cabins" binding:"required,dive"`
+	ExitRows     []int                 `json:"exit_rows"`
+	BlockedSeats []string              `json:"blocked_seats"` // e.g. ["1B", "1E"]
+}
+
+// SeatAssignmentRequest picks a seat for one passenger
+type SeatAssignmentRequest struct {
+	PassengerID uint   `json:"passenger_id" binding:"required"`
+	Seat        string `json:"seat" binding:"required"`
+}
+
+// AssignSeatsRequest is the request body for choosing seats on a booking
+type AssignSeatsRequest struct {
+	Seats []SeatAssignmentRequest `json:"seats" binding:"required,dive"`
+}
+
+// SeatHandler handles seat map and seat assignment HTTP requests
+type SeatHandler struct {
+	SeatService service.SeatService
+}
+
+// NewSeatHandler creates a new SeatHandler
+func NewSeatHandler(seatService service.SeatService) *SeatHandler {
+	return &SeatHandler{SeatService: seatService}
+}
+
+// GetSeatMap handles requests for a flight's seat map and occupancy
+func (h *SeatHandler) GetSeatMap(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid flight ID")
+		return
+	}
+
+	view, err := h.SeatService.GetSeatMap(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, view)
+}
+
+// SetSeatMap handles admin requests to create or replace a flight's seat map
+func (h *SeatHandler) SetSeatMap(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid flight ID")
+		return
+	}
+
+	var req SeatMapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	exitRows := make([]string, 0, len(req.ExitRows))
+	for _, row := range req.ExitRows {
+		exitRows = append(exitRows, strconv.Itoa(row))
+	}
+	seatMap := models.SeatMap{
+		Rows:         req.Rows,
+		Columns:      req.Columns,
+		ExitRows:     strings.Join(exitRows, ","),
+		BlockedSeats: strings.Join(req.BlockedSeats, ","),
+	}
+	for _, cabin := range req.Cabins {
+		seatMap.Cabins = append(seatMap.Cabins, models.SeatMapCabin{
+			Name:     cabin.Name,
+			FirstRow: cabin.FirstRow,
+			LastRow:  cabin.LastRow,
+		})
+	}
+
+	view, err := h.SeatService.SetSeatMap(uint(id), &seatMap)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, view)
+}
+
+// AssignSeats handles requests to choose or change seats on a booking
+func (h *SeatHandler) AssignSeats(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid booking ID")
+		return
+	}
+
+	var req AssignSeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	seats := make([]service.SeatRequest, 0, len(req.Seats))
+	for _, s := range req.Seats {
+		seats = append(seats, service.SeatRequest{PassengerID: s.PassengerID, Seat: s.Seat})
+	}
+
+	booking, err := h.SeatService.AssignSeats(uint(id), seats)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, newBookingResponse(booking))
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSeatService is a mock implementation of SeatService interface
+type MockSeatService struct {
+	mock.Mock
+}
+
+func (m *MockSeatService) GetSeatMap(flightID uint) (*service.SeatMapView, error) {
+	args := m.Called(flightID)
+	return args.Get(0).(*service.SeatMapView), args.Error(1)
+}
+
+func (m *MockSeatService) SetSeatMap(flightID uint, seatMap *models.SeatMap) (*service.SeatMapView, error) {
+	args := m.Called(flightID, seatMap)
+	return args.Get(0).(*service.SeatMapView), args.Error(1)
+}
+
+func (m *MockSeatService) AssignSeats(bookingID uint, seats []service.SeatRequest) (*models.Booking, error) {
+	args := m.Called(bookingID, seats)
+	return args.Get(0).(*models.Booking), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupSeatTestRouter(seatHandler *SeatHandler) *gin.Engine {
+	r := gin.Default()
+	r.GET("/flights/:id/seatmap", seatHandler.GetSeatMap)
+	r.PUT("/bookings/:id/seats", seatHandler.AssignSeats)
+	r.PUT("/admin/flights/:id/seatmap", seatHandler.SetSeatMap)
+	return r
+}
+
+// TestGetSeatMap_Success tests getting a seat map with occupancy
+func TestGetSeatMap_Success(t *testing.T) {
+	// Given
+	mockService := new(MockSeatService)
+	handler := NewSeatHandler(mockService)
+
+	router := setupSeatTestRouter(handler)
+
+	view := service.SeatMapView{
+		FlightID:  1,
+		Columns:   "AB",
+		Available: 1,
+		Rows: []service.SeatRowView{
+			{Row: 1, Cabin: "Economy", Seats: []service.SeatView{
+				{Seat: "1A", Status: service.SeatStatusAvailable},
+				{Seat: "1B", Status: service.SeatStatusOccupied},
+			}},
+		},
+	}
+	mockService.On("GetSeatMap", uint(1)).Return(&view, nil).Once()
+
+	// When
+	req, _ := http.NewRequest("GET", "/flights/1/seatmap", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response service.SeatMapView
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 1, response.Available)
+	assert.Equal(t, service.SeatStatusOccupied, response.Rows[0].Seats[1].Status)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestAssignSeats_SeatTaken tests that a seat taken by another booking returns 409
+func TestAssignSeats_SeatTaken(t *testing.T) {
+	// Given
+	mockService := new(MockSeatService)
+	handler := NewSeatHandler(mockService)
+
+	router := setupSeatTestRouter(handler)
+
+	seats := []service.SeatRequest{{PassengerID: 7, Seat: "12C"}}
+	mockService.On("AssignSeats", uint(1), seats).Return((*models.Booking)(nil), service.NewConflictError(service.CodeSeatTaken, "seat 12C is already taken")).Once()
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"seats": []map[string]interface{}{{"passenger_id": 7, "seat": "12C"}},
+	})
+
+	// When
+	req, _ := http.NewRequest("PUT", "/bookings/1/seats", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assertErrorCode(t, w, service.CodeSeatTaken)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestSetSeatMap_Success tests that the admin request is converted to a seat map
+func TestSetSeatMap_Success(t *testing.T) {
+	// Given
+	mockService := new(MockSeatService)
+	handler := NewSeatHandler(mockService)
+
+	router := setupSeatTestRouter(handler)
+
+	expected := &models.SeatMap{
+		Rows:         30,
+		Columns:      "ABCDEF",
+		ExitRows:     "12,13",
+		BlockedSeats: "1B,1E",
+		Cabins: []models.SeatMapCabin{
+			{Name: "Business", FirstRow: 1, LastRow: 3},
+			{Name: "Economy", FirstRow: 4, LastRow: 30},
+		},
+	}
+	mockService.On("SetSeatMap", uint(1), expected).Return(&service.SeatMapView{FlightID: 1}, nil).Once()
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"rows":          30,
+		"columns":       "ABCDEF",
+		"exit_rows":     []int{12, 13},
+		"blocked_seats": []string{"1B", "1E"},
+		"cabins": []map[string]interface{}{
+			{"name": "Business", "first_row": 1, "last_row": 3},
+			{"name": "Economy", "first_row": 4, "last_row": 30},
+		},
+	})
+
+	// When
+	req, _ := http.NewRequest("PUT", "/admin/flights/1/seatmap", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestAssignSeats_InvalidBookingID tests that a non-numeric booking ID is rejected
+func TestAssignSeats_InvalidBookingID(t *testing.T) {
+	// Given
+	mockService := new(MockSeatService)
+	handler := NewSeatHandler(mockService)
+
+	router := setupSeatTestRouter(handler)
+
+	// When
+	req, _ := http.NewRequest("PUT", "/bookings/abc/seats", bytes.NewBufferString(`{"seats":[{"passenger_id":1,"seat":"1A"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "AssignSeats", mock.Anything, mock.Anything)
+}
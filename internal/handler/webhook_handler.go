@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhookRequest is the request body for subscribing to booking events
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	Secret     string   `json:"secret" binding:"required"` // used to sign every payload with HMAC-SHA256
+	EventTypes []string `json:"event_types" binding:"required"`
+}
+
+// WebhookSubscriptionResponse is a subscription as returned by the API. The secret is never returned.
+type WebhookSubscriptionResponse struct {
+	ID         uint      `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookHandler handles webhook subscription HTTP requests
+type WebhookHandler struct {
+	WebhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: webhookService}
+}
+
+// CreateWebhook handles requests to subscribe a URL to booking events
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+
+	subscription := models.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: strings.Join(req.EventTypes, ","),
+	}
+
+	created, err := h.WebhookService.CreateSubscription(&subscription)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, toWebhookResponse(created))
+}
+
+// ListWebhooks handles requests to list active subscriptions
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subscriptions, err := h.WebhookService.ListSubscriptions()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]WebhookSubscriptionResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		response = append(response, toWebhookResponse(&subscriptions[i]))
+	}
+	c.JSON(200, response)
+}
+
+// DeleteWebhook handles requests to unsubscribe
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.WebhookService.DeleteSubscription(uint(id)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// ListDeliveries handles requests for a subscription's delivery log
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid webhook ID")
+		return
+	}
+
+	deliveries, err := h.WebhookService.ListDeliveries(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, deliveries)
+}
+
+// ListDeadLetters handles requests for deliveries that ran out of attempts
+func (h *WebhookHandler) ListDeadLetters(c *gin.Context) {
+	deliveries, err := h.WebhookService.ListDeadLetters()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, deliveries)
+}
+
+// RetryDeadLetter handles requests to send a dead-lettered delivery again
+func (h *WebhookHandler) RetryDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondBadRequest(c, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.WebhookService.RetryDelivery(uint(id))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, delivery)
+}
+
+func toWebhookResponse(subscription *models.WebhookSubscription) WebhookSubscriptionResponse {
+	return WebhookSubscriptionResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: strings.Split(subscription.EventTypes, ","),
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"flight-booking/internal/models"
+	"flight-booking/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockWebhookService is a mock implementation of WebhookService interface
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(subscription)
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	args := m.Called()
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteSubscription(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(subscriptionID uint) ([]models.WebhookDelivery, error) {
+	args := m.Called(subscriptionID)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) ListDeadLetters() ([]models.WebhookDelivery, error) {
+	args := m.Called()
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) RetryDelivery(id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) DispatchPending(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+// SetupRouter for testing
+func setupWebhookTestRouter(webhookHandler *WebhookHandler) *gin.Engine {
+	r := gin.Default()
+	admin := r.Group("/admin")
+	admin.POST("/webhooks", webhookHandler.CreateWebhook)
+	admin.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+	admin.GET("/webhooks/dead-letters", webhookHandler.ListDeadLetters)
+	return r
+}
+
+// TestCreateWebhook_Success tests subscribing to booking events without echoing the secret
+func TestCreateWebhook_Success(t *testing.T) {
+	// Given
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	router := setupWebhookTestRouter(handler)
+
+	mockService.On("CreateSubscription", mock.MatchedBy(func(s *models.WebhookSubscription) bool {
+		return s.EventTypes == "booking.confirmed,booking.cancelled" && s.Secret == "partner-secret-0123"
+	})).Return(&models.WebhookSubscription{
+		Model:      gorm.Model{ID: 1},
+		URL:        "https://partner.example.com/hooks",
+		Secret:     "partner-secret-0123",
+		EventTypes: "booking.confirmed,booking.cancelled",
+		Active:     true,
+	}, nil).Once()
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"url":         "https://partner.example.com/hooks",
+		"secret":      "partner-secret-0123",
+		"event_types": []string{"booking.confirmed", "booking.cancelled"},
+	})
+
+	// When
+	req, _ := http.NewRequest("POST", "/admin/webhooks", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "partner-secret-0123")
+	var response WebhookSubscriptionResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, []string{"booking.confirmed", "booking.cancelled"}, response.EventTypes)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestCreateWebhook_Invalid tests that validation errors are returned as 400
+func TestCreateWebhook_Invalid(t *testing.T) {
+	// Given
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	router := setupWebhookTestRouter(handler)
+
+	mockService.On("CreateSubscription", mock.Anything).Return((*models.WebhookSubscription)(nil), service.NewValidationError(service.CodeInvalidWebhook, "invalid webhook: unknown event type")).Once()
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"url":         "https://partner.example.com/hooks",
+		"secret":      "partner-secret-0123",
+		"event_types": []string{"flight.delayed"},
+	})
+
+	// When
+	req, _ := http.NewRequest("POST", "/admin/webhooks", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrorCode(t, w, service.CodeInvalidWebhook)
+}
+
+// TestListDeadLetters tests the dead-letter view
+func TestListDeadLetters(t *testing.T) {
+	// Given
+	mockService := new(MockWebhookService)
+	handler := NewWebhookHandler(mockService)
+
+	router := setupWebhookTestRouter(handler)
+
+	mockService.On("ListDeadLetters").Return([]models.WebhookDelivery{
+		{Model: gorm.Model{ID: 4}, SubscriptionID: 1, EventType: "booking.confirmed", DeliveryStatus: service.DeliveryStatusDeadLetter, Attempts: 6},
+	}, nil).Once()
+
+	// When
+	req, _ := http.NewRequest("GET", "/admin/webhooks/dead-letters", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var deliveries []models.WebhookDelivery
+	json.Unmarshal(w.Body.Bytes(), &deliveries)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, service.DeliveryStatusDeadLetter, deliveries[0].DeliveryStatus)
+	}
+
+	mockService.AssertExpectations(t)
+}
@@ -1,30 +1,275 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Flight represents a flight in the system
 type Flight struct {
+	gorm.Model
+	FlightNumber     string `json:"flight_number" gorm:"index"`
+	DepartureAirport string `json:"departure_airport" gorm:"index:idx_flight_search"`
+	ArrivalAirport   string `json:"arrival_airport" gorm:"index:idx_flight_search"`
+	// Times are stored in UTC. The local times at each airport are derived from them
+	// when the flight is saved, so searching by local date is a plain string range.
+	DepartureTime      time.Time `json:"departure_time_utc" gorm:"index"`
+	ArrivalTime        time.Time `json:"arrival_time_utc"`
+	DepartureLocalTime string    `json:"departure_time" gorm:"index:idx_flight_search"` // "YYYY-MM-DD HH:MM" at the departure airport
+	ArrivalLocalTime   string    `json:"arrival_time"`                                  // "YYYY-MM-DD HH:MM" at the arrival airport
+	DepartureTimeZone  string    `json:"departure_time_zone"`                           // IANA name, e.g., "Asia/Taipei"
+	ArrivalTimeZone    string    `json:"arrival_time_zone"`
+	DurationMinutes    int       `json:"duration_minutes"`
+	Airline            string    `json:"airline" gorm:"index"`
+	Price              float64   `json:"price" gorm:"index"`
+	AvailableSeats     int       `json:"available_seats"`
+	Capacity           int       `json:"capacity"`                            // seats on the aircraft; percentage oversell policies are based on it
+	RefundRule         string    `json:"refund_rule" gorm:"default:Standard"` // e.g., "Flexible", "Standard", "NonRefundable"
+	// An empty OversellPolicy falls back to the matching OversellRule, then to the service default
+	OversellPolicy string  `json:"oversell_policy,omitempty"` // e.g., "Fixed", "Percentage", "None"
+	OversellValue  float64 `json:"oversell_value,omitempty"`  // seats for Fixed, percent of capacity for Percentage
+	// Status is the operational status. The estimated times are set while the flight is
+	// delayed or under way and cleared when it is back on schedule.
+	Status                      string     `json:"status" gorm:"default:Scheduled"` // e.g., "Scheduled", "Delayed", "Cancelled"
+	EstimatedDepartureTime      *time.Time `json:"estimated_departure_time_utc,omitempty"`
+	EstimatedArrivalTime        *time.Time `json:"estimated_arrival_time_utc,omitempty"`
+	EstimatedDepartureLocalTime string     `json:"estimated_departure_time,omitempty"` // "YYYY-MM-DD HH:MM" at the departure airport
+	EstimatedArrivalLocalTime   string     `json:"estimated_arrival_time,omitempty"`   // "YYYY-MM-DD HH:MM" at the arrival airport
+	// Flights generated from a Schedule keep its ID and their operating date, so each
+	// date is generated only once
+	ScheduleID   *uint  `json:"schedule_id,omitempty" gorm:"uniqueIndex:idx_flight_schedule"`
+	ScheduleDate string `json:"schedule_date,omitempty" gorm:"size:10;uniqueIndex:idx_flight_schedule"` // "YYYY-MM-DD" at the departure airport
+	// Flights with fare classes keep Price and AvailableSeats in sync with them: the
+	// price of the cheapest class that has seats and the sum of every class's seats
+	FareClasses []FareClass `json:"fare_classes,omitempty"`
+	SeatMap     *SeatMap    `json:"seat_map,omitempty"` // loaded only by the seat map endpoints
+	// StatusHistory is loaded only for a single flight, oldest first
+	StatusHistory []FlightStatusEvent `json:"status_history,omitempty"`
+	// Version is bumped on every write so concurrent inventory updates can detect each other
+	Version int `json:"-" gorm:"not null;default:0"`
+}
+
+// FareClass is a cabin on a flight with its own price and seat inventory
+type FareClass struct {
+	gorm.Model
+	FlightID       uint    `json:"flight_id" gorm:"uniqueIndex:idx_fare_class"`
+	Name           string  `json:"name" gorm:"uniqueIndex:idx_fare_class"` // e.g., "Economy", "Premium", "Business"
+	Price          float64 `json:"price"`
+	Capacity       int     `json:"capacity"`
+	AvailableSeats int     `json:"available_seats"` // can go negative due to oversell, like Flight.AvailableSeats
+	OversellLimit  int     `json:"oversell_limit"`  // used when neither the flight's policy nor an OversellRule applies
+}
+
+// FlightStatusEvent records a change of a flight's operational status
+type FlightStatusEvent struct {
+	gorm.Model
+	FlightID               uint       `json:"flight_id" gorm:"index"`
+	Status                 string     `json:"status"`
+	EstimatedDepartureTime *time.Time `json:"estimated_departure_time_utc,omitempty"`
+	EstimatedArrivalTime   *time.Time `json:"estimated_arrival_time_utc,omitempty"`
+	Reason                 string     `json:"reason,omitempty"` // e.g., "Weather at NRT"
+}
+
+// Schedule is a recurring flight. A Flight is generated for every operating date up to
+// the booking horizon; dates are local at the departure airport.
+type Schedule struct {
 	gorm.Model
 	FlightNumber     string  `json:"flight_number" gorm:"index"`
-	DepartureAirport string  `json:"departure_airport" gorm:"index:idx_flight_search"`
-	ArrivalAirport   string  `json:"arrival_airport" gorm:"index:idx_flight_search"`
-	DepartureTime    string  `json:"departure_time" gorm:"index:idx_flight_search"`
-	ArrivalTime      string  `json:"arrival_time"`
-	Airline          string  `json:"airline" gorm:"index"`
-	Price            float64 `json:"price" gorm:"index"`
-	AvailableSeats   int     `json:"available_seats"`
+	Airline          string  `json:"airline"`
+	DepartureAirport string  `json:"departure_airport"`
+	ArrivalAirport   string  `json:"arrival_airport"`
+	DaysOfWeek       string  `json:"days_of_week"`           // comma-separated ISO weekdays, 1 = Monday to 7 = Sunday, e.g. "1,3,5"
+	DepartureTime    string  `json:"departure_time"`         // "HH:MM" at the departure airport
+	DurationMinutes  int     `json:"duration_minutes"`       // the arrival time follows from it
+	EffectiveFrom    string  `json:"effective_from"`         // first operating date, "YYYY-MM-DD"
+	EffectiveTo      string  `json:"effective_to,omitempty"` // last operating date; empty when the schedule has no end
+	Capacity         int     `json:"capacity"`               // seats on the aircraft
+	Price            float64 `json:"price"`
+	RefundRule       string  `json:"refund_rule" gorm:"default:Standard"`
+}
+
+// Airport is reference data for an airport, loaded from the bundled airports.csv
+type Airport struct {
+	gorm.Model
+	Code      string  `json:"code" gorm:"size:3;uniqueIndex"` // IATA code, e.g., "TPE"
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Country   string  `json:"country"`   // ISO 3166 alpha-2 code, e.g., "TW"
+	TimeZone  string  `json:"time_zone"` // IANA name, e.g., "Asia/Taipei"
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Airline is reference data for an airline, loaded from the bundled airlines.csv.
+// Flight.Airline holds its name.
+type Airline struct {
+	gorm.Model
+	IATACode string `json:"iata_code" gorm:"size:2;uniqueIndex"` // e.g., "BR"
+	ICAOCode string `json:"icao_code" gorm:"size:3;uniqueIndex"` // e.g., "EVA"
+	Name     string `json:"name" gorm:"uniqueIndex"`             // e.g., "EVA Air"
+}
+
+// OversellRule sets the oversell policy for every flight of an airline, of a route, or
+// of an airline on a route. The most specific matching rule wins; flights with their
+// own OversellPolicy ignore the rules.
+type OversellRule struct {
+	gorm.Model
+	Airline          string  `json:"airline,omitempty" gorm:"index"`
+	DepartureAirport string  `json:"departure_airport,omitempty"`
+	ArrivalAirport   string  `json:"arrival_airport,omitempty"`
+	Policy           string  `json:"policy"` // e.g., "Fixed", "Percentage", "None"
+	Value            float64 `json:"value"`  // seats for Fixed, percent of capacity for Percentage
 }
 
 // Booking represents a booking made by a user
-// TODO: 若未來需支援付款流程，可新增付款相關欄位（如 payment_status, payment_time 等）
-// TODO: 若需通知用戶，可考慮加上 email 或 notification 欄位
 type Booking struct {
 	gorm.Model
-	FlightID      uint    `json:"flight_id" gorm:"index:idx_booking_search"`
-	PassengerName string  `json:"passenger_name" gorm:"index:idx_booking_search"`
-	Quantity      int     `json:"quantity"`
-	TotalPrice    float64 `json:"total_price"`
-	BookingStatus string  `json:"booking_status" gorm:"index"` // e.g., "Confirmed", "Waitlisted"
-	// PaymentStatus string // TODO: 付款狀態（如 unpaid, paid, refunded）
-	// NotificationSent bool // TODO: 是否已通知用戶
+	RecordLocator string      `json:"record_locator" gorm:"size:6;uniqueIndex"` // PNR shown to the customer
+	FlightID      uint        `json:"flight_id" gorm:"index:idx_booking_search"`
+	FareClass     string      `json:"fare_class,omitempty"`            // empty on flights without fare classes
+	OrderID       *uint       `json:"order_id,omitempty" gorm:"index"` // set when the booking is one segment of an Order
+	PassengerName string      `json:"passenger_name" gorm:"index:idx_booking_search"`
+	ContactEmail  string      `json:"contact_email"` // booking notifications are emailed here
+	Quantity      int         `json:"quantity"`
+	TotalPrice    float64     `json:"total_price"`
+	BookingStatus string      `json:"booking_status" gorm:"index"` // e.g., "Confirmed", "Waitlisted", "Cancelled", "Disrupted"
+	Passengers    []Passenger `json:"passengers"`                  // one passenger per seat
+	// Seats are released if the booking is not paid before PaymentDeadline
+	PaymentStatus    string     `json:"payment_status" gorm:"index:idx_booking_payment"` // e.g., "PendingPayment", "Paid", "PaymentFailed"
+	PaymentDeadline  *time.Time `json:"payment_deadline,omitempty" gorm:"index:idx_booking_payment"`
+	PaymentReference string     `json:"payment_reference,omitempty"` // gateway charge ID
+	PaidAt           *time.Time `json:"paid_at,omitempty"`
+	Refunds          []Refund   `json:"refunds,omitempty"`
+	// NotificationSent is true once the latest booking event has been delivered
+	NotificationSent bool `json:"notification_sent"`
+}
+
+// Passenger is a traveler on a booking. Airline check-in needs every traveler's details.
+type Passenger struct {
+	gorm.Model
+	BookingID      uint    `json:"booking_id" gorm:"index"`
+	Name           string  `json:"name"`
+	DateOfBirth    string  `json:"date_of_birth"`   // YYYY-MM-DD
+	DocumentNumber string  `json:"document_number"` // passport or ID number
+	PassengerType  string  `json:"passenger_type"`  // e.g., "Adult", "Child", "Infant"
+	Fare           float64 `json:"fare"`
+	Seat           string  `json:"seat,omitempty"` // e.g., "12C"; SeatAssignment guards against double assignment
+	// DroppedSeat is the seat requested for a booking that was waitlisted, so it was
+	// not assigned. It is only set on the response to the booking request.
+	DroppedSeat string `json:"dropped_seat,omitempty" gorm:"-"`
+}
+
+// SeatMap is the cabin layout of a flight. Seats are named by row number and column
+// letter, e.g. "12C"; rows are numbered from 1.
+type SeatMap struct {
+	gorm.Model
+	FlightID     uint           `json:"flight_id" gorm:"uniqueIndex"`
+	Rows         int            `json:"rows"`
+	Columns      string         `json:"columns"` // seat letters across a row, e.g. "ABCDEF"
+	Cabins       []SeatMapCabin `json:"cabins"`
+	ExitRows     string         `json:"exit_rows"`                      // comma-separated row numbers, e.g. "12,13"
+	BlockedSeats string         `json:"blocked_seats" gorm:"type:text"` // comma-separated seats that are never sold, e.g. "1B,1E"
+}
+
+// SeatMapCabin is a block of rows in one cabin. On flights with fare classes the cabin
+// name is a fare class name, and passengers may only sit in their booking's class.
+type SeatMapCabin struct {
+	gorm.Model
+	SeatMapID uint   `json:"seat_map_id" gorm:"index"`
+	Name      string `json:"name"` // e.g., "Business", "Economy"
+	FirstRow  int    `json:"first_row"`
+	LastRow   int    `json:"last_row"`
+}
+
+// SeatAssignment is a seat taken by a passenger. The unique index on (flight_id, seat)
+// keeps two passengers out of the same seat even when requests race. Released
+// assignments are deleted for good so the seat can be taken again.
+type SeatAssignment struct {
+	gorm.Model
+	FlightID    uint   `json:"flight_id" gorm:"uniqueIndex:idx_seat_assignment"`
+	Seat        string `json:"seat" gorm:"size:4;uniqueIndex:idx_seat_assignment"`
+	BookingID   uint   `json:"booking_id" gorm:"index"`
+	PassengerID uint   `json:"passenger_id" gorm:"uniqueIndex"`
+}
+
+// Order groups the bookings for every segment of a round-trip or multi-city trip.
+// All segments are reserved in one transaction, so either every leg is booked or none is.
+type Order struct {
+	gorm.Model
+	PassengerName string    `json:"passenger_name"`
+	Quantity      int       `json:"quantity"`
+	TotalPrice    float64   `json:"total_price"`
+	OrderStatus   string    `json:"order_status" gorm:"index"` // from its bookings, e.g., "Confirmed", "Waitlisted", "PartiallyCancelled"
+	Bookings      []Booking `json:"bookings"`                  // one booking per segment, in travel order
+}
+
+// SeatHold reserves seats on a flight for a short time while the customer fills in
+// passenger details. The seats are deducted when the hold is created and are either
+// turned into a booking or returned to the flight when the hold expires.
+type SeatHold struct {
+	gorm.Model
+	Token      string    `json:"token" gorm:"size:32;uniqueIndex"`
+	FlightID   uint      `json:"flight_id" gorm:"index"`
+	FareClass  string    `json:"fare_class,omitempty"`
+	Quantity   int       `json:"quantity"`
+	HoldStatus string    `json:"hold_status" gorm:"index:idx_hold_expiry"` // e.g., "Active", "Converted", "Expired"
+	ExpiresAt  time.Time `json:"expires_at" gorm:"index:idx_hold_expiry"`
+	BookingID  *uint     `json:"booking_id,omitempty"` // set once the hold is converted
+}
+
+// Refund is money returned to the customer for a cancelled, paid booking. The amount
+// follows the flight's refund rule at the time of cancellation.
+type Refund struct {
+	gorm.Model
+	BookingID        uint      `json:"booking_id" gorm:"index"`
+	RefundRule       string    `json:"refund_rule"`
+	Amount           float64   `json:"amount"`
+	Fee              float64   `json:"fee"`                         // cancellation fee kept from the paid amount
+	RefundStatus     string    `json:"refund_status" gorm:"index"`  // e.g., "Pending", "Succeeded", "Failed", "DeadLetter", "NotRefundable"
+	GatewayReference string    `json:"gateway_reference,omitempty"` // gateway refund ID
+	Attempts         int       `json:"attempts"`
+	NextAttemptAt    time.Time `json:"next_attempt_at" gorm:"index"`
+	LastError        string    `json:"last_error,omitempty" gorm:"type:text"`
+}
+
+// OutboxEvent is a booking notification waiting to be delivered. It is written in the
+// same transaction as the booking change, so an event exists if and only if the change
+// was committed.
+type OutboxEvent struct {
+	gorm.Model
+	EventType      string     `json:"event_type"`
+	BookingID      uint       `json:"booking_id" gorm:"index"`
+	Payload        string     `json:"payload" gorm:"type:text"`                  // JSON-encoded notification.Event
+	OutboxStatus   string     `json:"outbox_status" gorm:"index:idx_outbox_due"` // e.g., "Pending", "Delivered", "Failed"
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_outbox_due"`
+	LastError      string     `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	DeliveredSinks string     `json:"delivered_sinks,omitempty" gorm:"type:text"` // comma-separated names of the sinks that accepted the event
+}
+
+// WebhookSubscription is a partner endpoint that receives signed booking events
+type WebhookSubscription struct {
+	gorm.Model
+	URL        string `json:"url" gorm:"type:text"`
+	Secret     string `json:"-"`                            // HMAC-SHA256 signing key, never returned by the API
+	EventTypes string `json:"event_types" gorm:"type:text"` // comma-separated, e.g. "booking.confirmed,booking.cancelled"
+	Active     bool   `json:"active" gorm:"index"`
+}
+
+// WebhookDelivery is one event sent to one subscription, kept as a delivery log.
+// Deliveries that run out of attempts stay in the DeadLetter status for inspection.
+type WebhookDelivery struct {
+	gorm.Model
+	SubscriptionID uint       `json:"subscription_id" gorm:"index"`
+	OutboxEventID  uint       `json:"outbox_event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	DeliveryStatus string     `json:"delivery_status" gorm:"index:idx_webhook_due"` // e.g., "Pending", "Delivered", "DeadLetter"
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_due"`
+	ResponseStatus int        `json:"response_status,omitempty"` // HTTP status of the last attempt
+	LastError      string     `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
 }
@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"context"
+	"log"
+)
+
+// LogSink writes events to a logger. It is the default sink for local development.
+type LogSink struct {
+	Logger *log.Logger
+}
+
+// NewLogSink creates a LogSink that writes to the standard logger
+func NewLogSink() *LogSink {
+	return &LogSink{Logger: log.Default()}
+}
+
+// Name implements Sink.Name
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+// Send implements Sink.Send
+func (s *LogSink) Send(ctx context.Context, event Event) error {
+	s.Logger.Printf("notification %d: %s booking=%d locator=%s status=%s",
+		event.ID, event.Type, event.BookingID, event.RecordLocator, event.BookingStatus)
+	return nil
+}
@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"context"
+	"time"
+)
+
+// Event types published for the booking lifecycle
+const (
+	EventBookingConfirmed  = "booking.confirmed"
+	EventBookingWaitlisted = "booking.waitlisted"
+	EventBookingCancelled  = "booking.cancelled"
+	EventBookingPromoted   = "booking.promoted"
+	EventBookingDelayed    = "booking.delayed"   // the booking's flight is delayed
+	EventBookingDisrupted  = "booking.disrupted" // the booking's flight is cancelled
+)
+
+// Event is a booking notification. ID is unique per event, so sinks can use it to
+// drop duplicates when a delivery is retried.
+type Event struct {
+	ID            uint      `json:"id"`
+	Type          string    `json:"type"`
+	BookingID     uint      `json:"booking_id"`
+	RecordLocator string    `json:"record_locator"`
+	FlightID      uint      `json:"flight_id"`
+	PassengerName string    `json:"passenger_name"`
+	ContactEmail  string    `json:"contact_email,omitempty"`
+	BookingStatus string    `json:"booking_status"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	// Set on flight status events
+	FlightStatus           string     `json:"flight_status,omitempty"`
+	EstimatedDepartureTime *time.Time `json:"estimated_departure_time_utc,omitempty"`
+}
+
+// Sink delivers events to one destination
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
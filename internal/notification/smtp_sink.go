@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPSink emails events to the booking's contact address. Events without a contact
+// email are skipped.
+type SMTPSink struct {
+	Addr string // host:port of the SMTP server
+	From string
+	Auth smtp.Auth // nil for servers that do not require authentication
+}
+
+// NewSMTPSink creates a new SMTPSink
+func NewSMTPSink(addr, from string, auth smtp.Auth) *SMTPSink {
+	return &SMTPSink{Addr: addr, From: from, Auth: auth}
+}
+
+// Name implements Sink.Name
+func (s *SMTPSink) Name() string {
+	return "smtp"
+}
+
+// Send implements Sink.Send
+func (s *SMTPSink) Send(ctx context.Context, event Event) error {
+	if event.ContactEmail == "" {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, []string{event.ContactEmail}, s.message(event)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message builds a plain-text email for the event
+func (s *SMTPSink) message(event Event) []byte {
+	subjects := map[string]string{
+		EventBookingConfirmed:  "Your booking is confirmed",
+		EventBookingWaitlisted: "Your booking is on the waitlist",
+		EventBookingCancelled:  "Your booking has been cancelled",
+		EventBookingPromoted:   "Your waitlisted booking is now confirmed",
+		EventBookingDelayed:    "Your flight is delayed",
+		EventBookingDisrupted:  "Your flight has been cancelled",
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", event.ContactEmail)
+	fmt.Fprintf(&b, "Subject: %s (%s)\r\n", subjects[event.Type], event.RecordLocator)
+	fmt.Fprintf(&b, "Message-ID: <booking-event-%d@flight-booking>\r\n", event.ID)
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&b, "Dear %s,\r\n\r\n", event.PassengerName)
+	fmt.Fprintf(&b, "Booking %s on flight %d is now %s.\r\n", event.RecordLocator, event.FlightID, event.BookingStatus)
+	if event.EstimatedDepartureTime != nil {
+		fmt.Fprintf(&b, "The flight is now expected to depart at %s UTC.\r\n", event.EstimatedDepartureTime.UTC().Format("2006-01-02 15:04"))
+	}
+	return []byte(b.String())
+}
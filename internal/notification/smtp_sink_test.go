@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one mail transaction and returns the recipients and message it received
+func fakeSMTPServer(t *testing.T) (addr string, received chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received = make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var mail strings.Builder
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "MAIL FROM"), strings.HasPrefix(cmd, "RCPT TO"):
+				mail.WriteString(line)
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					mail.WriteString(dataLine)
+				}
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				received <- mail.String()
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+// TestSMTPSink_Send tests that an event is emailed to the booking's contact address
+func TestSMTPSink_Send(t *testing.T) {
+	// Given
+	addr, received := fakeSMTPServer(t)
+	sink := NewSMTPSink(addr, "no-reply@example.com", nil)
+
+	event := Event{
+		ID:            7,
+		Type:          EventBookingConfirmed,
+		BookingID:     1,
+		RecordLocator: "ABC234",
+		PassengerName: "Chen Wei",
+		ContactEmail:  "chen@example.com",
+		BookingStatus: "Confirmed",
+	}
+
+	// When
+	err := sink.Send(context.Background(), event)
+
+	// Then
+	require.NoError(t, err)
+	mail := <-received
+	assert.Contains(t, mail, "RCPT TO:<chen@example.com>")
+	assert.Contains(t, mail, "Subject: Your booking is confirmed (ABC234)")
+	assert.Contains(t, mail, "Dear Chen Wei")
+}
+
+// TestSMTPSink_Delay tests that a delay email gives the estimated departure
+func TestSMTPSink_Delay(t *testing.T) {
+	// Given
+	addr, received := fakeSMTPServer(t)
+	sink := NewSMTPSink(addr, "no-reply@example.com", nil)
+
+	estimated := time.Date(2025, 8, 1, 4, 30, 0, 0, time.UTC)
+	event := Event{
+		ID:                     8,
+		Type:                   EventBookingDelayed,
+		RecordLocator:          "ABC234",
+		PassengerName:          "Chen Wei",
+		ContactEmail:           "chen@example.com",
+		BookingStatus:          "Confirmed",
+		FlightStatus:           "Delayed",
+		EstimatedDepartureTime: &estimated,
+	}
+
+	// When
+	err := sink.Send(context.Background(), event)
+
+	// Then
+	require.NoError(t, err)
+	mail := <-received
+	assert.Contains(t, mail, "Subject: Your flight is delayed (ABC234)")
+	assert.Contains(t, mail, "expected to depart at 2025-08-01 04:30 UTC")
+}
+
+// TestSMTPSink_NoContactEmail tests that events without a contact address are skipped
+func TestSMTPSink_NoContactEmail(t *testing.T) {
+	sink := NewSMTPSink("127.0.0.1:1", "no-reply@example.com", nil)
+
+	err := sink.Send(context.Background(), Event{ID: 1, Type: EventBookingCancelled})
+
+	assert.NoError(t, err)
+}
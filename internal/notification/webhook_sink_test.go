@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookSink_Send tests that events are posted as JSON and non-2xx responses fail
+func TestWebhookSink_Send(t *testing.T) {
+	// Given
+	var got Event
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	event := Event{ID: 3, Type: EventBookingPromoted, BookingID: 9}
+
+	// When / Then
+	require.NoError(t, sink.Send(context.Background(), event))
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, EventBookingPromoted, got.Type)
+
+	status = http.StatusInternalServerError
+	assert.Error(t, sink.Send(context.Background(), event))
+}
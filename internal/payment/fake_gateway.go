@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeDeclinedMethod is a payment method the fake gateway always declines
+const FakeDeclinedMethod = "tok_declined"
+
+// FakeGateway is an in-process PaymentGateway for tests and local development.
+// Every payment method except FakeDeclinedMethod is accepted.
+type FakeGateway struct {
+	mu       sync.Mutex
+	charges  map[string]*Charge       // by idempotency key
+	refunds  map[string]*RefundResult // by idempotency key
+	refunded map[string]float64       // total refunded per charge ID
+}
+
+// NewFakeGateway creates a new FakeGateway
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		charges:  make(map[string]*Charge),
+		refunds:  make(map[string]*RefundResult),
+		refunded: make(map[string]float64),
+	}
+}
+
+// Charge implements PaymentGateway.Charge
+func (g *FakeGateway) Charge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if charge, ok := g.charges[req.IdempotencyKey]; ok {
+		return charge, nil
+	}
+	if req.PaymentMethod == FakeDeclinedMethod {
+		return nil, ErrDeclined
+	}
+
+	charge := &Charge{
+		ID:     fmt.Sprintf("ch_fake_%d", len(g.charges)+1),
+		Amount: req.Amount,
+	}
+	g.charges[req.IdempotencyKey] = charge
+	return charge, nil
+}
+
+// Refund implements PaymentGateway.Refund. Charges made by this process cannot be
+// refunded beyond their amount; charges it does not know, such as those made before
+// a restart, are accepted as is.
+func (g *FakeGateway) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if refund, ok := g.refunds[req.IdempotencyKey]; ok {
+		return refund, nil
+	}
+
+	for _, c := range g.charges {
+		if c.ID == req.ChargeID && g.refunded[c.ID]+req.Amount > c.Amount {
+			return nil, fmt.Errorf("refund of %.2f exceeds the remaining amount of charge %s", req.Amount, c.ID)
+		}
+	}
+
+	refund := &RefundResult{
+		ID:     fmt.Sprintf("re_fake_%d", len(g.refunds)+1),
+		Amount: req.Amount,
+	}
+	g.refunds[req.IdempotencyKey] = refund
+	g.refunded[req.ChargeID] += req.Amount
+	return refund, nil
+}
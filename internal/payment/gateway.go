@@ -0,0 +1,45 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDeclined is returned by a gateway when the payment method was refused
+var ErrDeclined = errors.New("payment declined")
+
+// ChargeRequest describes a payment to collect
+type ChargeRequest struct {
+	// IdempotencyKey makes retries safe: charging the same key twice returns the first charge
+	IdempotencyKey string
+	Amount         float64
+	// PaymentMethod is an opaque token issued to the client by the payment provider
+	PaymentMethod string
+	Description   string
+}
+
+// Charge is a payment collected by a gateway
+type Charge struct {
+	ID     string
+	Amount float64
+}
+
+// PaymentGateway collects payments from an external provider
+type PaymentGateway interface {
+	Charge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+}
+
+// RefundRequest describes money to return for an earlier charge
+type RefundRequest struct {
+	// IdempotencyKey makes retries safe: refunding the same key twice returns the first refund
+	IdempotencyKey string
+	ChargeID       string
+	Amount         float64
+}
+
+// RefundResult is a refund issued by a gateway
+type RefundResult struct {
+	ID     string
+	Amount float64
+}
@@ -0,0 +1,189 @@
+// Package refdata holds the airport and airline reference data bundled with the
+// application. The same data is loaded into the airports and airlines tables.
+package refdata
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"flight-booking/internal/models"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	// Embed the IANA database so time zones resolve on hosts without zoneinfo files
+	_ "time/tzdata"
+)
+
+var (
+	//go:embed data/airports.csv
+	airportsCSV []byte
+	//go:embed data/airlines.csv
+	airlinesCSV []byte
+)
+
+var (
+	airportCodePattern     = regexp.MustCompile(`^[A-Z]{3}$`)
+	countryCodePattern     = regexp.MustCompile(`^[A-Z]{2}$`)
+	airlineIATACodePattern = regexp.MustCompile(`^[A-Z0-9]{2}$`)
+	airlineICAOCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+var (
+	airportHeader = []string{"iata_code", "name", "city", "country", "time_zone", "latitude", "longitude"}
+	airlineHeader = []string{"iata_code", "icao_code", "name"}
+)
+
+// The bundled files are parsed once, on first use
+var (
+	bundledAirports = sync.OnceValues(func() ([]models.Airport, error) {
+		return parseAirports(bytes.NewReader(airportsCSV))
+	})
+	bundledAirlines = sync.OnceValues(func() ([]models.Airline, error) {
+		return parseAirlines(bytes.NewReader(airlinesCSV))
+	})
+	timeZones = sync.OnceValues(func() (map[string]*time.Location, error) {
+		airports, err := bundledAirports()
+		if err != nil {
+			return nil, err
+		}
+		zones := make(map[string]*time.Location, len(airports))
+		for _, a := range airports {
+			// parseAirports has checked that every zone loads
+			zones[a.Code], _ = time.LoadLocation(a.TimeZone)
+		}
+		return zones, nil
+	})
+)
+
+// Airports returns the bundled airports, ordered by code
+func Airports() ([]models.Airport, error) {
+	airports, err := bundledAirports()
+	return slices.Clone(airports), err
+}
+
+// Airlines returns the bundled airlines, ordered as in the file
+func Airlines() ([]models.Airline, error) {
+	airlines, err := bundledAirlines()
+	return slices.Clone(airlines), err
+}
+
+// Location returns the time zone of the airport with the given IATA code; its
+// String method gives the IANA name
+func Location(code string) (*time.Location, error) {
+	zones, err := timeZones()
+	if err != nil {
+		return nil, err
+	}
+	zone, ok := zones[code]
+	if !ok {
+		return nil, fmt.Errorf("unknown airport %q", code)
+	}
+	return zone, nil
+}
+
+// parseAirports reads and checks airports in the airports.csv format
+func parseAirports(r io.Reader) ([]models.Airport, error) {
+	rows, err := readCSV(r, "airports.csv", airportHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	airports := make([]models.Airport, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for i, row := range rows {
+		line := i + 2 // after the header, counting from 1
+		airport := models.Airport{Code: row[0], Name: row[1], City: row[2], Country: row[3], TimeZone: row[4]}
+
+		if !airportCodePattern.MatchString(airport.Code) {
+			return nil, fmt.Errorf("airports.csv line %d: iata_code %q must be 3 uppercase letters", line, airport.Code)
+		}
+		if seen[airport.Code] {
+			return nil, fmt.Errorf("airports.csv line %d: duplicate iata_code %s", line, airport.Code)
+		}
+		seen[airport.Code] = true
+		if airport.Name == "" || airport.City == "" {
+			return nil, fmt.Errorf("airports.csv line %d: name and city are required", line)
+		}
+		if !countryCodePattern.MatchString(airport.Country) {
+			return nil, fmt.Errorf("airports.csv line %d: country %q must be an ISO 3166 alpha-2 code", line, airport.Country)
+		}
+		if _, err := time.LoadLocation(airport.TimeZone); err != nil || airport.TimeZone == "" {
+			return nil, fmt.Errorf("airports.csv line %d: unknown time_zone %q", line, airport.TimeZone)
+		}
+		if airport.Latitude, err = strconv.ParseFloat(row[5], 64); err != nil || airport.Latitude < -90 || airport.Latitude > 90 {
+			return nil, fmt.Errorf("airports.csv line %d: latitude %q must be between -90 and 90", line, row[5])
+		}
+		if airport.Longitude, err = strconv.ParseFloat(row[6], 64); err != nil || airport.Longitude < -180 || airport.Longitude > 180 {
+			return nil, fmt.Errorf("airports.csv line %d: longitude %q must be between -180 and 180", line, row[6])
+		}
+		airports = append(airports, airport)
+	}
+
+	slices.SortFunc(airports, func(a, b models.Airport) int { return cmp.Compare(a.Code, b.Code) })
+	return airports, nil
+}
+
+// parseAirlines reads and checks airlines in the airlines.csv format
+func parseAirlines(r io.Reader) ([]models.Airline, error) {
+	rows, err := readCSV(r, "airlines.csv", airlineHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	airlines := make([]models.Airline, 0, len(rows))
+	seen := make(map[string]bool, 3*len(rows))
+	for i, row := range rows {
+		line := i + 2
+		airline := models.Airline{IATACode: row[0], ICAOCode: row[1], Name: row[2]}
+
+		if !airlineIATACodePattern.MatchString(airline.IATACode) {
+			return nil, fmt.Errorf("airlines.csv line %d: iata_code %q must be 2 uppercase letters or digits", line, airline.IATACode)
+		}
+		if !airlineICAOCodePattern.MatchString(airline.ICAOCode) {
+			return nil, fmt.Errorf("airlines.csv line %d: icao_code %q must be 3 uppercase letters", line, airline.ICAOCode)
+		}
+		if airline.Name == "" {
+			return nil, fmt.Errorf("airlines.csv line %d: name is required", line)
+		}
+		// Flights name their airline by code or by name, so none of them may repeat
+		for _, key := range []string{"iata:" + airline.IATACode, "icao:" + airline.ICAOCode, "name:" + airline.Name} {
+			if seen[key] {
+				return nil, fmt.Errorf("airlines.csv line %d: duplicate %s", line, key)
+			}
+			seen[key] = true
+		}
+		airlines = append(airlines, airline)
+	}
+	return airlines, nil
+}
+
+// readCSV reads every record after checking the header
+func readCSV(r io.Reader, name string, header []string) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(header)
+	reader.TrimLeadingSpace = true
+
+	got, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if !slices.Equal(got, header) {
+		return nil, fmt.Errorf("%s: header must be %v, got %v", name, header, got)
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return rows, nil
+}
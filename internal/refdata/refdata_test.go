@@ -0,0 +1,63 @@
+package refdata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBundledData tests that the bundled files parse, so a bad edit fails the build
+func TestBundledData(t *testing.T) {
+	airports, err := Airports()
+	require.NoError(t, err)
+	assert.NotEmpty(t, airports)
+
+	airlines, err := Airlines()
+	require.NoError(t, err)
+	assert.NotEmpty(t, airlines)
+
+	zone, err := Location("NRT")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", zone.String())
+
+	_, err = Location("XXX")
+	assert.Error(t, err)
+}
+
+// TestParseAirports_Invalid tests that malformed airport rows are rejected with their line
+func TestParseAirports_Invalid(t *testing.T) {
+	const header = "iata_code,name,city,country,time_zone,latitude,longitude\n"
+	cases := map[string]string{
+		"wrong header":      "code,name\nTPE,Taoyuan\n",
+		"lowercase code":    header + "tpe,Taoyuan,Taipei,TW,Asia/Taipei,25,121\n",
+		"duplicate code":    header + "TPE,Taoyuan,Taipei,TW,Asia/Taipei,25,121\nTPE,Taoyuan,Taipei,TW,Asia/Taipei,25,121\n",
+		"bad country":       header + "TPE,Taoyuan,Taipei,Taiwan,Asia/Taipei,25,121\n",
+		"unknown time zone": header + "TPE,Taoyuan,Taipei,TW,Asia/Nowhere,25,121\n",
+		"bad latitude":      header + "TPE,Taoyuan,Taipei,TW,Asia/Taipei,95,121\n",
+		"missing column":    header + "TPE,Taoyuan,Taipei,TW,Asia/Taipei,25\n",
+	}
+	for name, input := range cases {
+		_, err := parseAirports(strings.NewReader(input))
+		assert.Error(t, err, name)
+	}
+
+	_, err := parseAirports(strings.NewReader(header + "TPE,Taoyuan,Taipei,TW,Asia/Taipei,25,121\nNRT,Narita,Tokyo,JP,Asia/Tokyo,abc,140\n"))
+	assert.ErrorContains(t, err, "line 3")
+}
+
+// TestParseAirlines_Invalid tests that malformed or repeated airlines are rejected
+func TestParseAirlines_Invalid(t *testing.T) {
+	const header = "iata_code,icao_code,name\n"
+	cases := map[string]string{
+		"bad iata code":  header + "EVA,EVA,EVA Air\n",
+		"bad icao code":  header + "BR,EV,EVA Air\n",
+		"missing name":   header + "BR,EVA,\n",
+		"duplicate name": header + "BR,EVA,EVA Air\nB7,UIA,EVA Air\n",
+	}
+	for name, input := range cases {
+		_, err := parseAirlines(strings.NewReader(input))
+		assert.Error(t, err, name)
+	}
+}
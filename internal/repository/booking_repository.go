@@ -10,6 +10,7 @@ import (
 type BookingRepository interface {
 	Create(booking *models.Booking) error
 	FindByID(id uint) (*models.Booking, error)
+	FindByRecordLocator(locator string) (*models.Booking, error)
 	Update(booking *models.Booking) error
 }
 
@@ -31,7 +32,16 @@ func (r *GORMBookingRepository) Create(booking *models.Booking) error {
 // FindByID implements BookingRepository.FindByID
 func (r *GORMBookingRepository) FindByID(id uint) (*models.Booking, error) {
 	var booking models.Booking
-	if err := r.db.First(&booking, id).Error; err != nil {
+	if err := r.db.Preload("Passengers").Preload("Refunds").First(&booking, id).Error; err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// FindByRecordLocator implements BookingRepository.FindByRecordLocator
+func (r *GORMBookingRepository) FindByRecordLocator(locator string) (*models.Booking, error) {
+	var booking models.Booking
+	if err := r.db.Preload("Passengers").Preload("Refunds").Where("record_locator = ?", locator).First(&booking).Error; err != nil {
 		return nil, err
 	}
 	return &booking, nil
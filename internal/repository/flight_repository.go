@@ -24,28 +24,34 @@ func NewGORMFlightRepository(db *gorm.DB) *GORMFlightRepository {
 	return &GORMFlightRepository{db: db}
 }
 
-// FindAll implements FlightRepository.FindAll
+// FindAll implements FlightRepository.FindAll. Cancelled flights cannot be booked, so
+// they are left out.
 func (r *GORMFlightRepository) FindAll(query *gorm.DB, page, pageSize int) ([]models.Flight, int64, error) {
 	var flights []models.Flight
 	var total int64
 
+	query = query.Where("status <> ?", "Cancelled")
+
 	// Count total records
 	if err := query.Model(&models.Flight{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Apply pagination and find records
-	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&flights).Error; err != nil {
+	if err := query.Preload("FareClasses", orderFareClasses).
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&flights).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return flights, total, nil
 }
 
-// FindByID implements FlightRepository.FindByID
+// FindByID implements FlightRepository.FindByID. The flight comes with its status history.
 func (r *GORMFlightRepository) FindByID(id uint) (*models.Flight, error) {
 	var flight models.Flight
-	if err := r.db.First(&flight, id).Error; err != nil {
+	if err := r.db.Preload("FareClasses", orderFareClasses).
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&flight, id).Error; err != nil {
 		return nil, err
 	}
 	return &flight, nil
@@ -60,3 +66,8 @@ func (r *GORMFlightRepository) Create(flight *models.Flight) error {
 func (r *GORMFlightRepository) Update(flight *models.Flight) error {
 	return r.db.Save(flight).Error
 }
+
+// orderFareClasses lists a flight's fare classes cheapest first
+func orderFareClasses(db *gorm.DB) *gorm.DB {
+	return db.Order("price ASC, id ASC")
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"flight-booking/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OrderRepository defines the interface for order data operations
+type OrderRepository interface {
+	FindByID(id uint) (*models.Order, error)
+}
+
+// GORMOrderRepository is a concrete implementation of OrderRepository using GORM
+type GORMOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMOrderRepository creates a new GORMOrderRepository
+func NewGORMOrderRepository(db *gorm.DB) *GORMOrderRepository {
+	return &GORMOrderRepository{db: db}
+}
+
+// FindByID implements OrderRepository.FindByID
+func (r *GORMOrderRepository) FindByID(id uint) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.Preload("Bookings", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Bookings.Passengers").First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -2,27 +2,51 @@ package router
 
 import (
 	"flight-booking/internal/handler"
+	"flight-booking/internal/payment"
 	"flight-booking/internal/repository"
 	"flight-booking/internal/service"
+	"flight-booking/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 // SetupRouter sets up all the API routes
-func SetupRouter(db *gorm.DB) *gin.Engine {
+func SetupRouter(db *gorm.DB, gateway payment.PaymentGateway) *gin.Engine {
 	r := gin.Default()
 
 	// Initialize repositories
 	flightRepo := repository.NewGORMFlightRepository(db)
 	bookingRepo := repository.NewGORMBookingRepository(db)
+	orderRepo := repository.NewGORMOrderRepository(db)
 
 	// Initialize services
-	bookingService := service.NewBookingService(bookingRepo, db, 10) // 設定超賣上限為 10 張
+	refundService := service.NewRefundService(db, gateway)
+	bookingService := service.NewBookingService(bookingRepo, db, service.DefaultOversellPolicy, refundService)
+	orderService := service.NewOrderService(orderRepo, db, service.DefaultOversellPolicy)
+	flightService := service.NewFlightService(flightRepo, db)
+	itineraryService := service.NewItineraryService(db)
+	holdService := service.NewHoldService(db, service.DefaultHoldTTL)
+	paymentService := service.NewPaymentService(db, gateway)
+	webhookService := service.NewWebhookService(db, webhook.NewSender())
+	seatService := service.NewSeatService(db)
+	oversellRuleService := service.NewOversellRuleService(db)
+	referenceDataService := service.NewReferenceDataService(db)
+	scheduleService := service.NewScheduleService(db, service.DefaultScheduleHorizonDays)
 
 	// Initialize handlers with their respective repositories/services
-	flightHandler := handler.NewFlightHandler(flightRepo, db)
+	flightHandler := handler.NewFlightHandler(flightRepo, db, referenceDataService)
 	bookingHandler := handler.NewBookingHandler(bookingService)
+	adminFlightHandler := handler.NewAdminFlightHandler(flightService)
+	itineraryHandler := handler.NewItineraryHandler(itineraryService)
+	orderHandler := handler.NewOrderHandler(orderService)
+	holdHandler := handler.NewHoldHandler(holdService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	seatHandler := handler.NewSeatHandler(seatService)
+	oversellRuleHandler := handler.NewOversellRuleHandler(oversellRuleService)
+	airportHandler := handler.NewAirportHandler(referenceDataService)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
 
 	// Public routes
 	r.GET("/ping", func(c *gin.Context) {
@@ -34,10 +58,54 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 	// Flight routes
 	r.GET("/flights", flightHandler.SearchFlights)
 	r.GET("/flights/:id", flightHandler.GetFlight)
+	r.GET("/flights/:id/seatmap", seatHandler.GetSeatMap)
+
+	// Airport routes
+	r.GET("/airports", airportHandler.SearchAirports)
+
+	// Itinerary routes
+	r.GET("/itineraries", itineraryHandler.SearchItineraries)
+
+	// Seat hold routes
+	r.POST("/holds", holdHandler.CreateHold)
 
 	// Booking routes
 	r.POST("/bookings", bookingHandler.CreateBooking)
 	r.GET("/bookings/:id", bookingHandler.GetBooking)
+	r.GET("/bookings/by-locator/:pnr", bookingHandler.GetBookingByLocator)
+	r.DELETE("/bookings/:id", bookingHandler.CancelBooking)
+	r.POST("/bookings/:id/pay", paymentHandler.PayBooking)
+	r.PUT("/bookings/:id/seats", seatHandler.AssignSeats)
+
+	// Order routes
+	r.POST("/orders", orderHandler.CreateOrder)
+	r.GET("/orders/:id", orderHandler.GetOrder)
+
+	// Admin routes
+	// TODO: 目前沒有身分驗證，正式環境需加上 admin 權限的 middleware
+	admin := r.Group("/admin")
+	{
+		admin.POST("/flights", adminFlightHandler.CreateFlight)
+		admin.PUT("/flights/:id", adminFlightHandler.ReplaceFlight)
+		admin.PATCH("/flights/:id", adminFlightHandler.PatchFlight)
+		admin.DELETE("/flights/:id", adminFlightHandler.DeleteFlight)
+		admin.POST("/flights/:id/status", adminFlightHandler.UpdateFlightStatus)
+		admin.PUT("/flights/:id/seatmap", seatHandler.SetSeatMap)
+		admin.POST("/oversell-rules", oversellRuleHandler.CreateRule)
+		admin.GET("/oversell-rules", oversellRuleHandler.ListRules)
+		admin.DELETE("/oversell-rules/:id", oversellRuleHandler.DeleteRule)
+		admin.POST("/schedules", scheduleHandler.CreateSchedule)
+		admin.GET("/schedules", scheduleHandler.ListSchedules)
+		admin.PUT("/schedules/:id", scheduleHandler.ReplaceSchedule)
+		admin.DELETE("/schedules/:id", scheduleHandler.DeleteSchedule)
+		// Subscriptions receive passenger names and record locators, so only admins manage them
+		admin.POST("/webhooks", webhookHandler.CreateWebhook)
+		admin.GET("/webhooks", webhookHandler.ListWebhooks)
+		admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		admin.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+		admin.GET("/webhooks/dead-letters", webhookHandler.ListDeadLetters)
+		admin.POST("/webhooks/dead-letters/:id/retry", webhookHandler.RetryDeadLetter)
+	}
 
 	return r
 }
@@ -3,8 +3,11 @@ package service
 import (
 	"errors"
 	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
 	"flight-booking/internal/repository"
 	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -13,77 +16,228 @@ import (
 const (
 	BookingStatusConfirmed  = "Confirmed"
 	BookingStatusWaitlisted = "Waitlisted"
+	BookingStatusCancelled  = "Cancelled"
+	BookingStatusDisrupted  = "Disrupted" // the airline cancelled the booking's flight
 )
 
 type BookingService interface {
 	CreateBooking(booking *models.Booking) (*models.Booking, error)
+	CreateBookingFromHold(token string, booking *models.Booking) (*models.Booking, error)
 	GetBooking(id uint) (*models.Booking, error)
+	CancelBooking(id uint) (*models.Booking, error)
+	GetBookingByLocator(locator, lastName string) (*models.Booking, error)
 }
 
 type BookingServiceImpl struct {
 	BookingRepo   repository.BookingRepository
 	DB            *gorm.DB
-	OversellLimit int
+	Oversell      OversellPolicy // used when neither the flight nor an OversellRule sets one
+	Waitlist      WaitlistEngine
+	PaymentWindow time.Duration
+	Refunds       RefundService
 }
 
-func NewBookingService(bookingRepo repository.BookingRepository, db *gorm.DB, oversellLimit int) BookingService {
+func NewBookingService(bookingRepo repository.BookingRepository, db *gorm.DB, oversell OversellPolicy, refunds RefundService) BookingService {
 	return &BookingServiceImpl{
 		BookingRepo:   bookingRepo,
 		DB:            db,
-		OversellLimit: oversellLimit,
+		Oversell:      oversell,
+		Waitlist:      NewWaitlistEngine(),
+		PaymentWindow: DefaultPaymentWindow,
+		Refunds:       refunds,
 	}
 }
 
 func (s *BookingServiceImpl) CreateBooking(booking *models.Booking) (*models.Booking, error) {
-	oversellLimit := s.OversellLimit
-
 	// Start a transaction
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		resetNewBooking(booking)
+		return reserveSeats(tx, booking, s.Oversell, s.PaymentWindow)
+	})
+
+	if err != nil {
+		// TODO: 訂單建立失敗，可設定發信通知用戶
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+// reserveSeats locks the booking's flight, applies its oversell policy, deducts the
+// seats and creates the booking, which a confirmed booking must pay for within
+// paymentWindow. fallback is the policy for flights that have none and match no
+// OversellRule. It must run inside a transaction.
+func reserveSeats(tx *gorm.DB, booking *models.Booking, fallback OversellPolicy, paymentWindow time.Duration) error {
+	var flight models.Flight
+	// Select flight with pessimistic lock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", booking.FlightID).
+		First(&flight).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NewNotFoundError(CodeFlightNotFound, "flight not found")
+		}
+		return fmt.Errorf("failed to lock flight: %w", err)
+	}
+	if err := checkFlightOpen(&flight); err != nil {
+		return err
+	}
+
+	// Flights with fare classes sell seats from the chosen class's bucket
+	classes, err := lockFareClasses(tx, flight.ID)
+	if err != nil {
+		return err
+	}
+	class, err := selectFareClass(classes, booking.FareClass, booking.Quantity)
+	if err != nil {
+		return err
+	}
+
+	// A fare class sells from its own seats, and its oversell limit stands in for the
+	// fallback: the flight's policy and the oversell rules still come first
+	fare, available, capacity := flight.Price, flight.AvailableSeats, flight.Capacity
+	if class != nil {
+		booking.FareClass = class.Name
+		fare, available, capacity = class.Price, class.AvailableSeats, class.Capacity
+		fallback = FixedOversell{Seats: class.OversellLimit}
+	}
+	policy, err := resolveOversellPolicy(tx, &flight, fallback)
+	if err != nil {
+		return err
+	}
+	oversellLimit := policy.Limit(capacity)
+
+	// Validate passengers and price each one before touching inventory
+	if err := priceBooking(booking, &flight, fare); err != nil {
+		return err
+	}
+
+	// Check available seats with oversell logic
+	if available >= booking.Quantity {
+		booking.BookingStatus = BookingStatusConfirmed
+	} else if available+oversellLimit >= booking.Quantity {
+		booking.BookingStatus = BookingStatusWaitlisted
+	} else {
+		return NewInsufficientSeatsError("not enough seats: available=%d, oversell limit=%d", available, oversellLimit)
+	}
+	awaitPayment(booking, paymentWindow)
+
+	// Deduct seats (can go negative due to oversell)
+	if class != nil {
+		class.AvailableSeats -= booking.Quantity
+		if err := tx.Save(class).Error; err != nil {
+			return fmt.Errorf("failed to update fare class seats: %w", err)
+		}
+		syncFlightInventory(&flight, classes)
+	} else {
+		flight.AvailableSeats -= booking.Quantity
+	}
+
+	// Update flight within the transaction; fails if another booking got there first
+	if err := saveFlight(tx, &flight); err != nil {
+		return err
+	}
+
+	// A waitlisted booking has no seats to choose from yet: it is accepted without the
+	// requested seats, which the passengers can pick once it is confirmed
+	if booking.BookingStatus == BookingStatusWaitlisted {
+		dropRequestedSeats(booking)
+	}
+
+	// Create booking and its passengers within the transaction
+	if err := createWithRecordLocator(tx, booking); err != nil {
+		return fmt.Errorf("failed to create booking: %w", err)
+	}
+	if err := assignRequestedSeats(tx, booking); err != nil {
+		return err
+	}
+
+	eventType := notification.EventBookingConfirmed
+	if booking.BookingStatus == BookingStatusWaitlisted {
+		eventType = notification.EventBookingWaitlisted
+	}
+	if err := enqueueBookingEvent(tx, eventType, booking); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateBookingFromHold turns an active seat hold into a confirmed booking. The seats
+// were deducted when the hold was created, so inventory is not touched again.
+func (s *BookingServiceImpl) CreateBookingFromHold(token string, booking *models.Booking) (*models.Booking, error) {
 	err := s.DB.Transaction(func(tx *gorm.DB) error {
-		var flight models.Flight
-		// Select flight with pessimistic lock
+		resetNewBooking(booking)
+
+		var hold models.SeatHold
+		// Lock the hold so it cannot be converted twice or expire mid-conversion
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ?", booking.FlightID).
-			First(&flight).Error; err != nil {
+			Where("token = ?", token).
+			First(&hold).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("flight not found")
+				return NewNotFoundError(CodeHoldNotFound, "hold not found")
 			}
-			return fmt.Errorf("failed to lock flight: %w", err)
+			return fmt.Errorf("failed to lock hold: %w", err)
 		}
 
-		// Check available seats with oversell logic
-		// TODO: 超賣邏輯需要再優化，這裡只是做個簡單的範例
-		if flight.AvailableSeats >= booking.Quantity {
-			booking.BookingStatus = BookingStatusConfirmed
-		} else if flight.AvailableSeats+oversellLimit >= booking.Quantity {
-			booking.BookingStatus = BookingStatusWaitlisted
-		} else {
-			return fmt.Errorf("not enough seats: available=%d, oversell limit=%d", flight.AvailableSeats, oversellLimit)
+		if hold.HoldStatus != HoldStatusActive || !time.Now().Before(hold.ExpiresAt) {
+			return NewConflictError(CodeHoldExpired, "hold has expired or was already used")
 		}
 
-		// Deduct seats (can go negative due to oversell)
-		flight.AvailableSeats -= booking.Quantity
+		if booking.Quantity == 0 {
+			booking.Quantity = hold.Quantity
+		}
+		if booking.Quantity != hold.Quantity {
+			return NewValidationError(CodeInvalidRequest, "quantity %d does not match the %d held seats", booking.Quantity, hold.Quantity)
+		}
+		booking.FlightID = hold.FlightID
+		booking.FareClass = hold.FareClass
 
-		// Update flight within the transaction
-		if err := tx.Save(&flight).Error; err != nil {
-			return fmt.Errorf("failed to update flight seats: %w", err)
+		var flight models.Flight
+		if err := tx.Where("id = ?", hold.FlightID).First(&flight).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeFlightNotFound, "flight not found")
+			}
+			return fmt.Errorf("failed to load flight: %w", err)
+		}
+		if err := checkFlightOpen(&flight); err != nil {
+			return err
 		}
 
-		// Calculate total price
-		booking.TotalPrice = float64(booking.Quantity) * flight.Price
+		fare := flight.Price
+		if hold.FareClass != "" {
+			var class models.FareClass
+			if err := tx.Where("flight_id = ? AND name = ?", hold.FlightID, hold.FareClass).
+				First(&class).Error; err != nil {
+				return fmt.Errorf("failed to load fare class: %w", err)
+			}
+			fare = class.Price
+		}
+
+		if err := priceBooking(booking, &flight, fare); err != nil {
+			return err
+		}
 
-		// Create booking within the transaction
-		if err := tx.Create(&booking).Error; err != nil {
+		booking.BookingStatus = BookingStatusConfirmed
+		awaitPayment(booking, s.PaymentWindow)
+		if err := createWithRecordLocator(tx, booking); err != nil {
 			return fmt.Errorf("failed to create booking: %w", err)
 		}
+		if err := assignRequestedSeats(tx, booking); err != nil {
+			return err
+		}
+		if err := enqueueBookingEvent(tx, notification.EventBookingConfirmed, booking); err != nil {
+			return err
+		}
 
-		return nil // Commit transaction
+		hold.HoldStatus = HoldStatusConverted
+		hold.BookingID = &booking.ID
+		if err := tx.Save(&hold).Error; err != nil {
+			return fmt.Errorf("failed to convert hold: %w", err)
+		}
+		return nil
 	})
 
-	// TODO: 若需付款，這裡可串接金流並更新訂單狀態
-	// TODO: 訂單建立成功後可透過 Queue 發送 email 或通知用戶
-
 	if err != nil {
-		// TODO: 訂單建立失敗，可設定發信通知用戶
 		return nil, err
 	}
 
@@ -94,9 +248,149 @@ func (s *BookingServiceImpl) GetBooking(id uint) (*models.Booking, error) {
 	booking, err := s.BookingRepo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("booking not found")
+			return nil, NewNotFoundError(CodeBookingNotFound, "booking not found")
 		}
 		return nil, fmt.Errorf("failed to get booking: %w", err)
 	}
 	return booking, nil
 }
+
+func (s *BookingServiceImpl) CancelBooking(id uint) (*models.Booking, error) {
+	var booking models.Booking
+	var refund *models.Refund
+
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		// Lock the booking so concurrent cancellations cannot both succeed
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&booking, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeBookingNotFound, "booking not found")
+			}
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+
+		if booking.BookingStatus == BookingStatusCancelled {
+			return NewConflictError(CodeBookingAlreadyCancelled, "booking already cancelled")
+		}
+
+		flight, err := releaseBooking(tx, &booking, s.Waitlist)
+		if err != nil {
+			return err
+		}
+
+		// Only money that was collected is refunded
+		if booking.PaymentStatus == PaymentStatusPaid {
+			if refund, err = s.Refunds.CreateRefund(tx, &booking, flight); err != nil {
+				return err
+			}
+		}
+
+		return nil // Commit transaction
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The gateway is called after commit; a failed refund is retried by the refund sweeper
+	if refund != nil && refund.RefundStatus == RefundStatusPending {
+		if refund, err = s.Refunds.DispatchRefund(refund.ID); err != nil {
+			return nil, err
+		}
+	}
+	if refund != nil {
+		booking.Refunds = append(booking.Refunds, *refund)
+	}
+
+	return &booking, nil
+}
+
+// releaseBooking cancels a locked booking, frees its assigned seats, returns its
+// seats to the flight and promotes the waitlist into them. It returns the locked
+// flight and must run inside a transaction.
+func releaseBooking(tx *gorm.DB, booking *models.Booking, waitlist WaitlistEngine) (*models.Flight, error) {
+	var flight models.Flight
+	// Select flight with pessimistic lock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", booking.FlightID).
+		First(&flight).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError(CodeFlightNotFound, "flight not found")
+		}
+		return nil, fmt.Errorf("failed to lock flight: %w", err)
+	}
+
+	booking.BookingStatus = BookingStatusCancelled
+	if err := tx.Save(booking).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel booking: %w", err)
+	}
+	if err := refreshOrderStatus(tx, booking); err != nil {
+		return nil, err
+	}
+	if err := enqueueBookingEvent(tx, notification.EventBookingCancelled, booking); err != nil {
+		return nil, err
+	}
+	if err := releaseSeatAssignments(tx, booking); err != nil {
+		return nil, err
+	}
+
+	// Return seats to inventory. Waitlisted bookings were deducted as well,
+	// so the full quantity is always given back.
+	if err := returnSeats(tx, &flight, booking.FareClass, booking.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := saveFlight(tx, &flight); err != nil {
+		return nil, err
+	}
+
+	// Freed seats go to the waitlist before the transaction commits
+	if err := promoteWaitlist(tx, waitlist, &flight); err != nil {
+		return nil, err
+	}
+	return &flight, nil
+}
+
+// GetBookingByLocator finds a booking by record locator. The caller must also know a
+// passenger's surname; a wrong surname is reported as not found so locators cannot be probed.
+func (s *BookingServiceImpl) GetBookingByLocator(locator, lastName string) (*models.Booking, error) {
+	locator = strings.ToUpper(strings.TrimSpace(locator))
+	lastName = strings.TrimSpace(lastName)
+	if locator == "" || lastName == "" {
+		return nil, NewValidationError(CodeInvalidRequest, "record locator and last name are required")
+	}
+
+	booking, err := s.BookingRepo.FindByRecordLocator(locator)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError(CodeBookingNotFound, "booking not found")
+		}
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	if !bookingHasSurname(booking, lastName) {
+		return nil, NewNotFoundError(CodeBookingNotFound, "booking not found")
+	}
+	return booking, nil
+}
+
+// bookingHasSurname reports whether lastName matches the surname of the lead
+// passenger or any traveler. Names are stored as free text in either order
+// ("Chen Wei" or "Wei Chen"), so the first and last words are both accepted.
+func bookingHasSurname(booking *models.Booking, lastName string) bool {
+	names := []string{booking.PassengerName}
+	for _, p := range booking.Passengers {
+		names = append(names, p.Name)
+	}
+
+	for _, name := range names {
+		words := strings.Fields(name)
+		if len(words) == 0 {
+			continue
+		}
+		if strings.EqualFold(words[0], lastName) || strings.EqualFold(words[len(words)-1], lastName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateBooking_PassengerPricing tests that children and infants are priced at their own rates
+func TestCreateBooking_PassengerPricing(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight() // departs 2025-08-01, price 100
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	booking := &models.Booking{
+		FlightID:      flight.ID,
+		PassengerName: "Family",
+		Quantity:      3,
+		Passengers: []models.Passenger{
+			newTestPassenger(PassengerTypeAdult),
+			newTestPassenger(PassengerTypeChild),
+			newTestPassenger(PassengerTypeInfant),
+		},
+	}
+
+	// When
+	created, err := svc.CreateBooking(booking)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 185.0, created.TotalPrice)
+
+	got, err := svc.GetBooking(created.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Passengers, 3)
+	assert.Equal(t, 75.0, got.Passengers[1].Fare)
+}
+
+// TestCreateBooking_InvalidPassengers tests passenger validation
+func TestCreateBooking_InvalidPassengers(t *testing.T) {
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	cases := map[string]func(b *models.Booking){
+		"fewer passengers than seats": func(b *models.Booking) { b.Quantity = 2 },
+		"missing document":            func(b *models.Booking) { b.Passengers[0].DocumentNumber = "" },
+		"bad date of birth":           func(b *models.Booking) { b.Passengers[0].DateOfBirth = "01/01/1990" },
+		"unknown type":                func(b *models.Booking) { b.Passengers[0].PassengerType = "Senior" },
+		"adult priced as child":       func(b *models.Booking) { b.Passengers[0].PassengerType = PassengerTypeChild },
+	}
+
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			booking := newTestBooking(flight.ID, "A", 1)
+			mutate(booking)
+			_, err := svc.CreateBooking(booking)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, 5, reloaded.AvailableSeats, "rejected bookings must not touch inventory")
+}
+
+// TestCreateBooking_ServerFields tests that refunds and payment details on a new booking
+// are not saved with it
+func TestCreateBooking_ServerFields(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	booking := newTestBooking(flight.ID, "A", 1)
+	booking.Refunds = []models.Refund{{Amount: 5000, RefundStatus: RefundStatusPending}}
+	paidAt := time.Now()
+	booking.PaymentReference, booking.PaidAt = "ch_someone_else", &paidAt
+
+	// When
+	created, err := svc.CreateBooking(booking)
+
+	// Then
+	require.NoError(t, err)
+	var refunds int64
+	require.NoError(t, db.Model(&models.Refund{}).Count(&refunds).Error)
+	assert.Zero(t, refunds)
+
+	got, err := svc.GetBooking(created.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Passengers, 1)
+	assert.Equal(t, created.Passengers[0].ID, got.Passengers[0].ID)
+	assert.Equal(t, PaymentStatusPending, got.PaymentStatus)
+	assert.Empty(t, got.PaymentReference)
+	assert.Nil(t, got.PaidAt)
+}
+
+// TestGetBookingByLocator tests that bookings get a record locator that only opens with the right surname
+func TestGetBookingByLocator(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	created, err := svc.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-HJ-NP-Z2-9]{6}$`, created.RecordLocator)
+
+	// When / Then
+	got, err := svc.GetBookingByLocator(strings.ToLower(created.RecordLocator), "chen")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+
+	_, err = svc.GetBookingByLocator(created.RecordLocator, "Lin")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.GetBookingByLocator("ZZZZZZ", "Chen")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
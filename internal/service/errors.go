@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors describing the kind of a domain error. Use errors.Is to check them.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrInsufficientSeats = errors.New("insufficient seats")
+	ErrConflict          = errors.New("conflict")
+	ErrValidation        = errors.New("validation failed")
+	ErrPaymentDeclined   = errors.New("payment declined")
+)
+
+// Machine-readable error codes returned to API clients
+const (
+	CodeFlightNotFound          = "flight_not_found"
+	CodeBookingNotFound         = "booking_not_found"
+	CodeOrderNotFound           = "order_not_found"
+	CodeHoldNotFound            = "hold_not_found"
+	CodeWebhookNotFound         = "webhook_not_found"
+	CodeWebhookDeliveryNotFound = "webhook_delivery_not_found"
+	CodeSeatMapNotFound         = "seat_map_not_found"
+	CodeOversellRuleNotFound    = "oversell_rule_not_found"
+	CodeScheduleNotFound        = "schedule_not_found"
+	CodeInsufficientSeats       = "insufficient_seats"
+	CodeBookingAlreadyCancelled = "booking_already_cancelled"
+	CodeFlightHasBookings       = "flight_has_active_bookings"
+	CodeHoldExpired             = "hold_expired"
+	CodeFlightCancelled         = "flight_cancelled"
+	CodeInvalidStatusTransition = "invalid_status_transition"
+	CodeBookingAlreadyPaid      = "booking_already_paid"
+	CodePaymentDeadlinePassed   = "payment_deadline_passed"
+	CodePaymentDeclined         = "payment_declined"
+	CodeWebhookDeliveryNotDead  = "webhook_delivery_not_dead_lettered"
+	CodeSeatTaken               = "seat_taken"
+	CodeSeatMapInUse            = "seat_map_in_use"
+	CodeBookingNotConfirmed     = "booking_not_confirmed"
+	CodeInventoryBusy           = "inventory_busy"
+	CodeInvalidFlight           = "invalid_flight"
+	CodeInvalidFlightStatus     = "invalid_flight_status"
+	CodeInvalidFareClass        = "invalid_fare_class"
+	CodeInvalidOrder            = "invalid_order"
+	CodeInvalidPassengers       = "invalid_passengers"
+	CodeInvalidSeat             = "invalid_seat"
+	CodeInvalidSeatMap          = "invalid_seat_map"
+	CodeInvalidOversellRule     = "invalid_oversell_rule"
+	CodeInvalidSchedule         = "invalid_schedule"
+	CodeInvalidWebhook          = "invalid_webhook"
+	CodeInvalidRequest          = "invalid_request"
+	CodeUnknownAirport          = "unknown_airport"
+	CodeUnknownAirline          = "unknown_airline"
+)
+
+// Error is a domain error with a kind, a machine-readable code and a message
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap lets errors.Is match the error against its kind
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NewNotFoundError creates an error of kind ErrNotFound
+func NewNotFoundError(code, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewInsufficientSeatsError creates an error of kind ErrInsufficientSeats
+func NewInsufficientSeatsError(format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrInsufficientSeats, Code: CodeInsufficientSeats, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewConflictError creates an error of kind ErrConflict
+func NewConflictError(code, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewValidationError creates an error of kind ErrValidation
+func NewValidationError(code, format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewPaymentDeclinedError creates an error of kind ErrPaymentDeclined
+func NewPaymentDeclinedError(format string, args ...interface{}) *Error {
+	return &Error{Kind: ErrPaymentDeclined, Code: CodePaymentDeclined, Message: fmt.Sprintf(format, args...)}
+}
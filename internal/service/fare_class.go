@@ -0,0 +1,158 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	FareClassEconomy  = "Economy"
+	FareClassPremium  = "Premium"
+	FareClassBusiness = "Business"
+)
+
+// CheapestAvailableFareClass returns the cheapest class that still has a free seat,
+// or nil when every class is sold out
+func CheapestAvailableFareClass(classes []models.FareClass) *models.FareClass {
+	var cheapest *models.FareClass
+	for i := range classes {
+		c := &classes[i]
+		if c.AvailableSeats <= 0 {
+			continue
+		}
+		if cheapest == nil || c.Price < cheapest.Price {
+			cheapest = c
+		}
+	}
+	return cheapest
+}
+
+// lockFareClasses locks the flight's fare classes and returns them cheapest first.
+// Callers lock the flight before its classes so the two are always taken in the same order.
+func lockFareClasses(tx *gorm.DB, flightID uint) ([]models.FareClass, error) {
+	var classes []models.FareClass
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("flight_id = ?", flightID).
+		Order("price ASC, id ASC").
+		Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to lock fare classes: %w", err)
+	}
+	return classes, nil
+}
+
+// selectFareClass picks the class a reservation draws from. An empty name picks the
+// cheapest class with enough free seats, falling back to the cheapest class so the
+// oversell rules decide. It returns nil for flights without fare classes.
+func selectFareClass(classes []models.FareClass, name string, quantity int) (*models.FareClass, error) {
+	if len(classes) == 0 {
+		if name != "" {
+			return nil, NewValidationError(CodeInvalidFareClass, "flight has no fare class %q", name)
+		}
+		return nil, nil
+	}
+
+	if name == "" {
+		for i := range classes {
+			if classes[i].AvailableSeats >= quantity {
+				return &classes[i], nil
+			}
+		}
+		return &classes[0], nil
+	}
+
+	if class := findFareClass(classes, name); class != nil {
+		return class, nil
+	}
+	return nil, NewValidationError(CodeInvalidFareClass, "flight has no fare class %q", name)
+}
+
+// findFareClass returns the class with the given name, or nil
+func findFareClass(classes []models.FareClass, name string) *models.FareClass {
+	for i := range classes {
+		if classes[i].Name == name {
+			return &classes[i]
+		}
+	}
+	return nil
+}
+
+// syncFlightInventory sets the flight's seats to the sum of its classes and its price
+// to the cheapest class that has seats, so search and itineraries need not join the classes
+func syncFlightInventory(flight *models.Flight, classes []models.FareClass) {
+	if len(classes) == 0 {
+		return
+	}
+
+	seats := 0
+	for _, c := range classes {
+		seats += c.AvailableSeats
+	}
+	flight.AvailableSeats = seats
+
+	if cheapest := CheapestAvailableFareClass(classes); cheapest != nil {
+		flight.Price = cheapest.Price
+		return
+	}
+	// Sold out: show the lowest fare
+	flight.Price = classes[0].Price
+	for _, c := range classes[1:] {
+		if c.Price < flight.Price {
+			flight.Price = c.Price
+		}
+	}
+}
+
+// returnSeats gives seats back to the booking's fare class, or to the flight when the
+// booking has none. The flight must be locked; the caller saves it.
+func returnSeats(tx *gorm.DB, flight *models.Flight, fareClass string, quantity int) error {
+	if fareClass == "" {
+		flight.AvailableSeats += quantity
+		return nil
+	}
+
+	classes, err := lockFareClasses(tx, flight.ID)
+	if err != nil {
+		return err
+	}
+	class := findFareClass(classes, fareClass)
+	if class == nil {
+		return fmt.Errorf("fare class %q not found on flight %d", fareClass, flight.ID)
+	}
+
+	class.AvailableSeats += quantity
+	if err := tx.Save(class).Error; err != nil {
+		return fmt.Errorf("failed to update fare class seats: %w", err)
+	}
+	syncFlightInventory(flight, classes)
+	return nil
+}
+
+// validateFareClasses checks the classes given for a new flight and opens their inventory
+func validateFareClasses(classes []models.FareClass) error {
+	seen := make(map[string]bool, len(classes))
+	for i := range classes {
+		c := &classes[i]
+		if c.Name == "" {
+			return NewValidationError(CodeInvalidFareClass, "invalid fare class: name is required")
+		}
+		if seen[c.Name] {
+			return NewValidationError(CodeInvalidFareClass, "invalid fare class: %q is listed twice", c.Name)
+		}
+		seen[c.Name] = true
+
+		if c.Price <= 0 {
+			return NewValidationError(CodeInvalidFareClass, "invalid fare class %q: price must be positive", c.Name)
+		}
+		if c.Capacity < 0 {
+			return NewValidationError(CodeInvalidFareClass, "invalid fare class %q: capacity must not be negative", c.Name)
+		}
+		if c.OversellLimit < 0 {
+			return NewValidationError(CodeInvalidFareClass, "invalid fare class %q: oversell_limit must not be negative", c.Name)
+		}
+		c.AvailableSeats = c.Capacity
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFareClassFlight creates a flight with 2 Economy seats that may oversell by 1
+// and 1 Business seat that may not oversell
+func newTestFareClassFlight(t *testing.T, svc FlightService) *models.Flight {
+	flight := newTestFlight()
+	flight.Price = 0
+	flight.AvailableSeats = 0
+	flight.FareClasses = []models.FareClass{
+		{Name: FareClassBusiness, Price: 400, Capacity: 1},
+		{Name: FareClassEconomy, Price: 100, Capacity: 2, OversellLimit: 1},
+	}
+
+	created, err := svc.CreateFlight(&flight)
+	require.NoError(t, err)
+	return created
+}
+
+// TestCreateBooking_FareClassInventory tests that each fare class prices and oversells its own seats
+func TestCreateBooking_FareClassInventory(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	flightService := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	bookingService := newTestBookingService(db)
+
+	flight := newTestFareClassFlight(t, flightService)
+	assert.Equal(t, 3, flight.AvailableSeats)
+	assert.Equal(t, 100.0, flight.Price)
+
+	// When: no class picks the cheapest one with enough seats
+	economy, err := bookingService.CreateBooking(newTestBooking(flight.ID, "A", 2))
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, FareClassEconomy, economy.FareClass)
+	assert.Equal(t, BookingStatusConfirmed, economy.BookingStatus)
+	assert.Equal(t, 200.0, economy.TotalPrice)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, 1, reloaded.AvailableSeats)
+	assert.Equal(t, 400.0, reloaded.Price, "economy is sold out, so business is the cheapest fare")
+
+	// Business has no oversell allowance; economy does
+	business := newTestBooking(flight.ID, "B", 1)
+	business.FareClass = FareClassBusiness
+	_, err = bookingService.CreateBooking(business)
+	require.NoError(t, err)
+
+	extraBusiness := newTestBooking(flight.ID, "C", 1)
+	extraBusiness.FareClass = FareClassBusiness
+	_, err = bookingService.CreateBooking(extraBusiness)
+	assert.ErrorIs(t, err, ErrInsufficientSeats)
+
+	extraEconomy := newTestBooking(flight.ID, "D", 1)
+	extraEconomy.FareClass = FareClassEconomy
+	waitlisted, err := bookingService.CreateBooking(extraEconomy)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusWaitlisted, waitlisted.BookingStatus)
+
+	unknown := newTestBooking(flight.ID, "E", 1)
+	unknown.FareClass = "First"
+	_, err = bookingService.CreateBooking(unknown)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// Cancelling economy seats promotes the economy waitlist
+	_, err = bookingService.CancelBooking(economy.ID)
+	require.NoError(t, err)
+
+	got, err := bookingService.GetBooking(waitlisted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus)
+
+	var class models.FareClass
+	require.NoError(t, db.Where("flight_id = ? AND name = ?", flight.ID, FareClassEconomy).First(&class).Error)
+	assert.Equal(t, 1, class.AvailableSeats)
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, 1, reloaded.AvailableSeats)
+	assert.Equal(t, 100.0, reloaded.Price)
+}
+
+// TestUpdateFlight_FareClassSeats tests that seats and price of a fare-class flight cannot be set directly
+func TestUpdateFlight_FareClassSeats(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	flight := newTestFareClassFlight(t, svc)
+
+	seats := 10
+	_, err := svc.UpdateFlight(flight.ID, &FlightPatch{AvailableSeats: &seats})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// Sending back the current values is allowed, so a full replace still works
+	seats = flight.AvailableSeats
+	airline := "China Airlines"
+	updated, err := svc.UpdateFlight(flight.ID, &FlightPatch{AvailableSeats: &seats, Price: &flight.Price, Airline: &airline})
+	require.NoError(t, err)
+	assert.Equal(t, airline, updated.Airline)
+	assert.Len(t, updated.FareClasses, 2)
+}
+
+// TestCreateBooking_FareClassOversellPolicy tests that the flight's oversell policy and the
+// oversell rules apply to each fare class's capacity ahead of the class's own limit
+func TestCreateBooking_FareClassOversellPolicy(t *testing.T) {
+	// Given a sold-out business class that may not oversell on its own
+	db := setupTestDB(t)
+	flightService := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	bookingService := newTestBookingService(db)
+	flight := newTestFareClassFlight(t, flightService)
+
+	business := func(name string) *models.Booking {
+		booking := newTestBooking(flight.ID, name, 1)
+		booking.FareClass = FareClassBusiness
+		return booking
+	}
+	_, err := bookingService.CreateBooking(business("A"))
+	require.NoError(t, err)
+	_, err = bookingService.CreateBooking(business("B"))
+	require.ErrorIs(t, err, ErrInsufficientSeats)
+
+	// When the admin sets a policy on the flight
+	policy, value := OversellPolicyFixed, 1.0
+	_, err = flightService.UpdateFlight(flight.ID, &FlightPatch{OversellPolicy: &policy, OversellValue: &value})
+	require.NoError(t, err)
+
+	// Then the class may oversell by it
+	waitlisted, err := bookingService.CreateBooking(business("B"))
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusWaitlisted, waitlisted.BookingStatus)
+	_, err = bookingService.CreateBooking(business("C"))
+	assert.ErrorIs(t, err, ErrInsufficientSeats)
+
+	// When the flight's policy is cleared and a rule for its route allows no oversell
+	policy = ""
+	_, err = flightService.UpdateFlight(flight.ID, &FlightPatch{OversellPolicy: &policy})
+	require.NoError(t, err)
+	_, err = NewOversellRuleService(db).CreateRule(&models.OversellRule{DepartureAirport: "TPE", ArrivalAirport: "NRT", Policy: OversellPolicyNone})
+	require.NoError(t, err)
+
+	// Then the rule overrides economy's own limit
+	economy := newTestBooking(flight.ID, "D", 3)
+	economy.FareClass = FareClassEconomy
+	_, err = bookingService.CreateBooking(economy)
+	assert.ErrorIs(t, err, ErrInsufficientSeats)
+}
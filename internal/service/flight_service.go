@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/refdata"
+	"flight-booking/internal/repository"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FlightTimeLayout is the layout of the local times Flight.DepartureLocalTime and
+// Flight.ArrivalLocalTime, which admins also use to set a flight's times
+const FlightTimeLayout = "2006-01-02 15:04"
+
+var airportCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// FlightPatch holds the fields to change on a flight; nil fields are left untouched
+type FlightPatch struct {
+	FlightNumber     *string
+	DepartureAirport *string
+	ArrivalAirport   *string
+	DepartureTime    *string // local time at the departure airport, in FlightTimeLayout
+	ArrivalTime      *string // local time at the arrival airport, in FlightTimeLayout
+	Airline          *string
+	Price            *float64
+	AvailableSeats   *int
+	RefundRule       *string
+	OversellPolicy   *string // an empty policy falls back to the oversell rules
+	OversellValue    *float64
+}
+
+type FlightService interface {
+	CreateFlight(flight *models.Flight) (*models.Flight, error)
+	UpdateFlight(id uint, patch *FlightPatch) (*models.Flight, error)
+	DeleteFlight(id uint) error
+	UpdateStatus(id uint, update *FlightStatusUpdate) (*models.Flight, error)
+}
+
+type FlightServiceImpl struct {
+	FlightRepo repository.FlightRepository
+	DB         *gorm.DB
+	Waitlist   WaitlistEngine
+}
+
+func NewFlightService(flightRepo repository.FlightRepository, db *gorm.DB) FlightService {
+	return &FlightServiceImpl{
+		FlightRepo: flightRepo,
+		DB:         db,
+		Waitlist:   NewWaitlistEngine(),
+	}
+}
+
+func (s *FlightServiceImpl) CreateFlight(flight *models.Flight) (*models.Flight, error) {
+	if flight.RefundRule == "" {
+		flight.RefundRule = RefundRuleStandard
+	}
+	flight.Status = FlightStatusScheduled
+	// A flight sold by fare class takes its price and seats from the classes
+	if len(flight.FareClasses) > 0 {
+		if err := validateFareClasses(flight.FareClasses); err != nil {
+			return nil, err
+		}
+		syncFlightInventory(flight, flight.FareClasses)
+	}
+	if err := validateFlight(s.DB, flight); err != nil {
+		return nil, err
+	}
+	if err := validateSeats(flight.AvailableSeats); err != nil {
+		return nil, err
+	}
+	flight.Capacity = flight.AvailableSeats
+
+	if err := s.FlightRepo.Create(flight); err != nil {
+		return nil, fmt.Errorf("failed to create flight: %w", err)
+	}
+	return flight, nil
+}
+
+func (s *FlightServiceImpl) UpdateFlight(id uint, patch *FlightPatch) (*models.Flight, error) {
+	var flight models.Flight
+
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		// Lock the flight so seat changes do not race with bookings. A retried attempt
+		// reloads it, so the patch is applied to the flight as it is now.
+		flight = models.Flight{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&flight, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeFlightNotFound, "flight not found")
+			}
+			return fmt.Errorf("failed to lock flight: %w", err)
+		}
+
+		classes, err := lockFareClasses(tx, flight.ID)
+		if err != nil {
+			return err
+		}
+
+		previousPrice, previousSeats := flight.Price, flight.AvailableSeats
+		patch.applyTo(&flight)
+
+		if err := validateFlight(tx, &flight); err != nil {
+			return err
+		}
+		if len(classes) > 0 && (flight.Price != previousPrice || flight.AvailableSeats != previousSeats) {
+			return NewValidationError(CodeInvalidFlight, "invalid flight: price and available_seats are set per fare class on this flight")
+		}
+		if patch.AvailableSeats != nil {
+			if err := validateSeats(flight.AvailableSeats); err != nil {
+				return err
+			}
+		}
+		// Seats added or removed by an admin change the aircraft's capacity
+		flight.Capacity += flight.AvailableSeats - previousSeats
+
+		if err := saveFlight(tx, &flight); err != nil {
+			return err
+		}
+
+		// A capacity increase frees seats for the waitlist
+		if flight.AvailableSeats > previousSeats {
+			if err := promoteWaitlist(tx, s.Waitlist, &flight); err != nil {
+				return err
+			}
+		}
+
+		flight.FareClasses = classes
+		return nil // Commit transaction
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &flight, nil
+}
+
+// DeleteFlight deletes a flight without active bookings. Its active seat holds are
+// expired in the same transaction, so they can no longer become bookings.
+func (s *FlightServiceImpl) DeleteFlight(id uint) error {
+	return inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		var flight models.Flight
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&flight, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeFlightNotFound, "flight not found")
+			}
+			return fmt.Errorf("failed to lock flight: %w", err)
+		}
+
+		var activeBookings int64
+		if err := tx.Model(&models.Booking{}).
+			Where("flight_id = ? AND booking_status IN ?", flight.ID, []string{BookingStatusConfirmed, BookingStatusWaitlisted, BookingStatusDisrupted}).
+			Count(&activeBookings).Error; err != nil {
+			return fmt.Errorf("failed to count bookings: %w", err)
+		}
+		if activeBookings > 0 {
+			return NewConflictError(CodeFlightHasBookings, "flight has %d active bookings", activeBookings)
+		}
+
+		if err := expireFlightHolds(tx, &flight); err != nil {
+			return err
+		}
+		if err := saveFlight(tx, &flight); err != nil {
+			return err
+		}
+
+		// gorm.Model carries DeletedAt, so this is a soft delete
+		if err := tx.Delete(&flight).Error; err != nil {
+			return fmt.Errorf("failed to delete flight: %w", err)
+		}
+
+		return nil // Commit transaction
+	})
+}
+
+func (p *FlightPatch) applyTo(flight *models.Flight) {
+	if p.FlightNumber != nil {
+		flight.FlightNumber = *p.FlightNumber
+	}
+	if p.DepartureAirport != nil {
+		flight.DepartureAirport = *p.DepartureAirport
+	}
+	if p.ArrivalAirport != nil {
+		flight.ArrivalAirport = *p.ArrivalAirport
+	}
+	if p.DepartureTime != nil {
+		flight.DepartureLocalTime = *p.DepartureTime
+	}
+	if p.ArrivalTime != nil {
+		flight.ArrivalLocalTime = *p.ArrivalTime
+	}
+	if p.Airline != nil {
+		flight.Airline = *p.Airline
+	}
+	if p.Price != nil {
+		flight.Price = *p.Price
+	}
+	if p.AvailableSeats != nil {
+		flight.AvailableSeats = *p.AvailableSeats
+	}
+	if p.RefundRule != nil {
+		flight.RefundRule = *p.RefundRule
+	}
+	if p.OversellPolicy != nil {
+		flight.OversellPolicy = *p.OversellPolicy
+	}
+	if p.OversellValue != nil {
+		flight.OversellValue = *p.OversellValue
+	}
+}
+
+// validateFlight checks the fields an admin is allowed to set on a flight against the
+// reference data, stores the airline by name and derives the UTC times from the local ones
+func validateFlight(db *gorm.DB, flight *models.Flight) error {
+	if flight.FlightNumber == "" {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: flight_number is required")
+	}
+	if flight.Airline == "" {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: airline is required")
+	}
+	if !airportCodePattern.MatchString(flight.DepartureAirport) {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: departure_airport must be a 3-letter IATA code")
+	}
+	if !airportCodePattern.MatchString(flight.ArrivalAirport) {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: arrival_airport must be a 3-letter IATA code")
+	}
+	if flight.DepartureAirport == flight.ArrivalAirport {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: departure_airport and arrival_airport must differ")
+	}
+	if err := checkAirport(db, "departure_airport", flight.DepartureAirport); err != nil {
+		return err
+	}
+	if err := checkAirport(db, "arrival_airport", flight.ArrivalAirport); err != nil {
+		return err
+	}
+
+	// The airline may be given by code; flights, searches and oversell rules use its name
+	airline, err := resolveAirline(db, flight.Airline)
+	if err != nil {
+		return err
+	}
+	flight.Airline = airline.Name
+
+	if err := setFlightTimesFromLocal(flight); err != nil {
+		return err
+	}
+
+	if _, ok := refundRules[flight.RefundRule]; !ok {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: refund_rule must be Flexible, Standard or NonRefundable")
+	}
+
+	if flight.OversellPolicy != "" {
+		if _, err := NewOversellPolicy(flight.OversellPolicy, flight.OversellValue); err != nil {
+			return NewValidationError(CodeInvalidFlight, "invalid flight: %v", err)
+		}
+	}
+
+	if flight.Price <= 0 {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: price must be positive")
+	}
+	return nil
+}
+
+// validateSeats checks a seat count set by an admin. Existing flights may hold a
+// negative AvailableSeats due to oversell, so only explicitly set values are checked.
+func validateSeats(seats int) error {
+	if seats < 0 {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: available_seats must not be negative")
+	}
+	return nil
+}
+
+// SetFlightTimes sets the flight's UTC departure and arrival times and derives the
+// airports' time zones, the local times and the duration from them. The airports must
+// be set first.
+func SetFlightTimes(flight *models.Flight, departure, arrival time.Time) error {
+	departureZone, arrivalZone, err := flightTimeZones(flight)
+	if err != nil {
+		return err
+	}
+	if !arrival.After(departure) {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: arrival_time must be after departure_time")
+	}
+
+	flight.DepartureTime = departure.UTC()
+	flight.ArrivalTime = arrival.UTC()
+	flight.DepartureTimeZone = departureZone.String()
+	flight.ArrivalTimeZone = arrivalZone.String()
+	flight.DepartureLocalTime = departure.In(departureZone).Format(FlightTimeLayout)
+	flight.ArrivalLocalTime = arrival.In(arrivalZone).Format(FlightTimeLayout)
+	flight.DurationMinutes = int(arrival.Sub(departure).Minutes())
+	return nil
+}
+
+// setFlightTimesFromLocal reads the flight's local times as wall-clock times at its
+// airports, the way they are printed on a ticket, and sets the UTC times from them
+func setFlightTimesFromLocal(flight *models.Flight) error {
+	departureZone, arrivalZone, err := flightTimeZones(flight)
+	if err != nil {
+		return err
+	}
+
+	departure, err := time.ParseInLocation(FlightTimeLayout, flight.DepartureLocalTime, departureZone)
+	if err != nil {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: departure_time must be in YYYY-MM-DD HH:MM format")
+	}
+	arrival, err := time.ParseInLocation(FlightTimeLayout, flight.ArrivalLocalTime, arrivalZone)
+	if err != nil {
+		return NewValidationError(CodeInvalidFlight, "invalid flight: arrival_time must be in YYYY-MM-DD HH:MM format")
+	}
+	return SetFlightTimes(flight, departure, arrival)
+}
+
+// flightTimeZones returns the time zones of the flight's departure and arrival airports
+func flightTimeZones(flight *models.Flight) (*time.Location, *time.Location, error) {
+	departureZone, err := refdata.Location(flight.DepartureAirport)
+	if err != nil {
+		return nil, nil, NewValidationError(CodeInvalidFlight, "invalid flight: time zone of departure_airport %s is unknown", flight.DepartureAirport)
+	}
+	arrivalZone, err := refdata.Location(flight.ArrivalAirport)
+	if err != nil {
+		return nil, nil, NewValidationError(CodeInvalidFlight, "invalid flight: time zone of arrival_airport %s is unknown", flight.ArrivalAirport)
+	}
+	return departureZone, arrivalZone, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestFlight() models.Flight {
+	flight := models.Flight{
+		FlightNumber:       "BR101",
+		DepartureAirport:   "TPE",
+		ArrivalAirport:     "NRT",
+		DepartureLocalTime: "2025-08-01 10:00",
+		ArrivalLocalTime:   "2025-08-01 14:00",
+		Airline:            "EVA Air",
+		Price:              100,
+		AvailableSeats:     1,
+	}
+	if err := setFlightTimesFromLocal(&flight); err != nil {
+		panic(err)
+	}
+	return flight
+}
+
+// TestCreateFlight_Validation tests that invalid flights are rejected
+func TestCreateFlight_Validation(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewFlightService(repository.NewGORMFlightRepository(db), db)
+
+	cases := map[string]func(f *models.Flight){
+		"lowercase airport": func(f *models.Flight) { f.DepartureAirport = "tpe" },
+		"same airports":     func(f *models.Flight) { f.ArrivalAirport = "TPE" },
+		"bad time":          func(f *models.Flight) { f.DepartureLocalTime = "2025/08/01" },
+		"arrival first":     func(f *models.Flight) { f.ArrivalLocalTime = "2025-08-01 10:30" }, // 09:30 in Taipei
+		"unknown time zone": func(f *models.Flight) { f.ArrivalAirport = "ZZZ" },
+		"zero price":        func(f *models.Flight) { f.Price = 0 },
+		"negative seats":    func(f *models.Flight) { f.AvailableSeats = -1 },
+	}
+
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			flight := newTestFlight()
+			mutate(&flight)
+			_, err := svc.CreateFlight(&flight)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+// TestCreateFlight_Times tests that local times are converted to UTC using each airport's time zone
+func TestCreateFlight_Times(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	flight := models.Flight{
+		FlightNumber:       "BR198",
+		DepartureAirport:   "TPE",
+		ArrivalAirport:     "NRT",
+		DepartureLocalTime: "2025-08-01 08:50",
+		ArrivalLocalTime:   "2025-08-01 13:15",
+		Airline:            "EVA Air",
+		Price:              100,
+		AvailableSeats:     1,
+	}
+
+	// When
+	created, err := svc.CreateFlight(&flight)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 50, 0, 0, time.UTC), created.DepartureTime)
+	assert.Equal(t, time.Date(2025, 8, 1, 4, 15, 0, 0, time.UTC), created.ArrivalTime)
+	assert.Equal(t, "Asia/Taipei", created.DepartureTimeZone)
+	assert.Equal(t, "Asia/Tokyo", created.ArrivalTimeZone)
+	assert.Equal(t, 205, created.DurationMinutes)
+}
+
+// TestUpdateFlight_CapacityIncreasePromotesWaitlist tests that added seats go to the waitlist
+func TestUpdateFlight_CapacityIncreasePromotesWaitlist(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	flightService := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	bookingService := newTestBookingService(db)
+
+	flight := newTestFlight()
+	require.NoError(t, db.Create(&flight).Error)
+
+	waitlisted, err := bookingService.CreateBooking(newTestBooking(flight.ID, "A", 2))
+	require.NoError(t, err)
+	require.Equal(t, BookingStatusWaitlisted, waitlisted.BookingStatus)
+
+	// When
+	seats := 1 // -1 after the booking, so this adds 2 seats
+	_, err = flightService.UpdateFlight(flight.ID, &FlightPatch{AvailableSeats: &seats})
+	require.NoError(t, err)
+
+	// Then
+	got, err := bookingService.GetBooking(waitlisted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus)
+}
+
+// TestUpdateFlight_RetriedAttempt tests that an attempt that lost the race for the
+// flight is retried on a freshly loaded flight and the patch is applied once
+func TestUpdateFlight_RetriedAttempt(t *testing.T) {
+	// Given a first write to the flight that loses a race
+	db := setupTestDB(t)
+	flightService := NewFlightService(repository.NewGORMFlightRepository(db), db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 5
+	flight.Capacity = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	lost := false
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:lose_race", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*models.Flight); ok && !lost {
+			lost = true
+			tx.AddError(errInventoryConflict)
+		}
+	}))
+
+	// When
+	seats := 8
+	updated, err := flightService.UpdateFlight(flight.ID, &FlightPatch{AvailableSeats: &seats})
+
+	// Then
+	require.NoError(t, err)
+	assert.True(t, lost)
+	assert.Equal(t, 8, updated.AvailableSeats)
+	assert.Equal(t, 8, updated.Capacity, "the added seats are counted once")
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, 8, reloaded.Capacity)
+	assert.Equal(t, flight.Version+1, reloaded.Version)
+}
+
+// TestDeleteFlight_ExpiresHolds tests that deleting a flight expires its active seat holds
+func TestDeleteFlight_ExpiresHolds(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	flightService := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	holds := NewHoldService(db, DefaultHoldTTL)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+	hold, err := holds.CreateHold(flight.ID, "", 2)
+	require.NoError(t, err)
+
+	// When
+	err = flightService.DeleteFlight(flight.ID)
+
+	// Then
+	require.NoError(t, err)
+	var got models.SeatHold
+	require.NoError(t, db.First(&got, hold.ID).Error)
+	assert.Equal(t, HoldStatusExpired, got.HoldStatus)
+
+	var deleted models.Flight
+	require.NoError(t, db.Unscoped().First(&deleted, flight.ID).Error)
+	assert.True(t, deleted.DeletedAt.Valid)
+	assert.Equal(t, 5, deleted.AvailableSeats, "the held seats are returned")
+
+	_, err = newTestBookingService(db).CreateBookingFromHold(hold.Token, newTestBooking(flight.ID, "A", 2))
+	assertCode(t, err, CodeHoldExpired)
+}
+
+// TestDeleteFlight_ActiveBookings tests that flights with active bookings are kept
+func TestDeleteFlight_ActiveBookings(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	flightService := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	bookingService := newTestBookingService(db)
+
+	flight := newTestFlight()
+	require.NoError(t, db.Create(&flight).Error)
+
+	booking, err := bookingService.CreateBooking(newTestBooking(flight.ID, "A", 1))
+	require.NoError(t, err)
+
+	// When / Then
+	assert.ErrorIs(t, flightService.DeleteFlight(flight.ID), ErrConflict)
+
+	_, err = bookingService.CancelBooking(booking.ID)
+	require.NoError(t, err)
+	require.NoError(t, flightService.DeleteFlight(flight.ID))
+
+	var count int64
+	db.Model(&models.Flight{}).Where("id = ?", flight.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	db.Unscoped().Model(&models.Flight{}).Where("id = ?", flight.ID).Count(&count)
+	assert.Equal(t, int64(1), count, "flight should be soft deleted")
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Operational statuses of a flight
+const (
+	FlightStatusScheduled = "Scheduled"
+	FlightStatusDelayed   = "Delayed"
+	FlightStatusBoarding  = "Boarding"
+	FlightStatusDeparted  = "Departed"
+	FlightStatusArrived   = "Arrived"
+	FlightStatusCancelled = "Cancelled"
+)
+
+// flightStatusTransitions lists the statuses a flight may move to from each status.
+// Delayed and Departed may be set again to revise the estimated times; Arrived and
+// Cancelled are final.
+var flightStatusTransitions = map[string][]string{
+	FlightStatusScheduled: {FlightStatusDelayed, FlightStatusBoarding, FlightStatusDeparted, FlightStatusCancelled},
+	FlightStatusDelayed:   {FlightStatusScheduled, FlightStatusDelayed, FlightStatusBoarding, FlightStatusDeparted, FlightStatusCancelled},
+	FlightStatusBoarding:  {FlightStatusDelayed, FlightStatusDeparted, FlightStatusCancelled},
+	FlightStatusDeparted:  {FlightStatusDeparted, FlightStatusArrived},
+	FlightStatusArrived:   {},
+	FlightStatusCancelled: {},
+}
+
+// FlightStatusUpdate is an admin's change of a flight's operational status
+type FlightStatusUpdate struct {
+	Status                 string
+	EstimatedDepartureTime string // local time at the departure airport, in FlightTimeLayout
+	EstimatedArrivalTime   string // local time at the arrival airport, in FlightTimeLayout
+	Reason                 string
+}
+
+// UpdateStatus moves the flight to a new operational status and records it in the
+// flight's status history. Passengers of a delayed flight are notified of the new
+// estimate. Cancelling the flight releases its holds and disrupts every active booking;
+// a disrupted booking can no longer be paid and is refunded in full when cancelled.
+func (s *FlightServiceImpl) UpdateStatus(id uint, update *FlightStatusUpdate) (*models.Flight, error) {
+	if _, ok := flightStatusTransitions[update.Status]; !ok {
+		return nil, NewValidationError(CodeInvalidFlightStatus,
+			"invalid flight status: status must be Scheduled, Delayed, Boarding, Departed, Arrived or Cancelled")
+	}
+
+	var flight models.Flight
+
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		flight = models.Flight{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&flight, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeFlightNotFound, "flight not found")
+			}
+			return fmt.Errorf("failed to lock flight: %w", err)
+		}
+
+		if !canTransition(flight.Status, update.Status) {
+			return NewConflictError(CodeInvalidStatusTransition, "flight status cannot change from %s to %s", flight.Status, update.Status)
+		}
+		if err := applyFlightStatus(&flight, update); err != nil {
+			return err
+		}
+
+		if update.Status == FlightStatusCancelled {
+			if err := expireFlightHolds(tx, &flight); err != nil {
+				return err
+			}
+		}
+		if err := saveFlight(tx, &flight); err != nil {
+			return err
+		}
+
+		event := models.FlightStatusEvent{
+			FlightID:               flight.ID,
+			Status:                 flight.Status,
+			EstimatedDepartureTime: flight.EstimatedDepartureTime,
+			EstimatedArrivalTime:   flight.EstimatedArrivalTime,
+			Reason:                 update.Reason,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("failed to record flight status: %w", err)
+		}
+
+		switch update.Status {
+		case FlightStatusCancelled:
+			if err := disruptBookings(tx, &flight); err != nil {
+				return err
+			}
+		case FlightStatusDelayed:
+			if err := notifyDelay(tx, &flight); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("flight_id = ?", flight.ID).Order("id ASC").
+			Find(&flight.StatusHistory).Error; err != nil {
+			return fmt.Errorf("failed to load flight status history: %w", err)
+		}
+		return nil // Commit transaction
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &flight, nil
+}
+
+// canTransition reports whether a flight may move from one status to another. Flights
+// created before statuses existed have none and count as Scheduled.
+func canTransition(from, to string) bool {
+	if from == "" {
+		from = FlightStatusScheduled
+	}
+	for _, next := range flightStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFlightStatus sets the status and the estimated times on the flight. A delay needs
+// an estimated departure later than scheduled; the estimated arrival defaults to the
+// estimated departure plus the scheduled duration. Boarding, Departed and Arrived keep the
+// current estimates unless new ones are given, and Scheduled and Cancelled clear them.
+func applyFlightStatus(flight *models.Flight, update *FlightStatusUpdate) error {
+	flight.Status = update.Status
+
+	hasEstimates := update.EstimatedDepartureTime != "" || update.EstimatedArrivalTime != ""
+	if update.Status == FlightStatusScheduled || update.Status == FlightStatusCancelled {
+		if hasEstimates {
+			return NewValidationError(CodeInvalidFlightStatus, "invalid flight status: a %s flight has no estimated times", update.Status)
+		}
+		setEstimatedTimes(flight, nil, nil)
+		return nil
+	}
+	if update.Status == FlightStatusDelayed && update.EstimatedDepartureTime == "" {
+		return NewValidationError(CodeInvalidFlightStatus, "invalid flight status: estimated_departure_time is required for a delay")
+	}
+	if !hasEstimates {
+		return nil
+	}
+
+	departureZone, arrivalZone, err := flightTimeZones(flight)
+	if err != nil {
+		return err
+	}
+
+	departure := flight.DepartureTime
+	if flight.EstimatedDepartureTime != nil {
+		departure = *flight.EstimatedDepartureTime
+	}
+	if update.EstimatedDepartureTime != "" {
+		departure, err = time.ParseInLocation(FlightTimeLayout, update.EstimatedDepartureTime, departureZone)
+		if err != nil {
+			return NewValidationError(CodeInvalidFlightStatus, "invalid flight status: estimated_departure_time must be in YYYY-MM-DD HH:MM format")
+		}
+	}
+	arrival := departure.Add(time.Duration(flight.DurationMinutes) * time.Minute)
+	if update.EstimatedArrivalTime != "" {
+		arrival, err = time.ParseInLocation(FlightTimeLayout, update.EstimatedArrivalTime, arrivalZone)
+		if err != nil {
+			return NewValidationError(CodeInvalidFlightStatus, "invalid flight status: estimated_arrival_time must be in YYYY-MM-DD HH:MM format")
+		}
+	}
+
+	if update.Status == FlightStatusDelayed && !departure.After(flight.DepartureTime) {
+		return NewValidationError(CodeInvalidFlightStatus, "invalid flight status: estimated_departure_time of a delay must be after the scheduled departure")
+	}
+	if !arrival.After(departure) {
+		return NewValidationError(CodeInvalidFlightStatus, "invalid flight status: estimated_arrival_time must be after estimated_departure_time")
+	}
+	setEstimatedTimes(flight, &departure, &arrival)
+	return nil
+}
+
+// setEstimatedTimes sets the flight's estimated UTC times and their local times at the
+// airports, or clears them when nil
+func setEstimatedTimes(flight *models.Flight, departure, arrival *time.Time) {
+	flight.EstimatedDepartureTime, flight.EstimatedDepartureLocalTime = nil, ""
+	flight.EstimatedArrivalTime, flight.EstimatedArrivalLocalTime = nil, ""
+	if departure == nil || arrival == nil {
+		return
+	}
+
+	departureZone, arrivalZone, err := flightTimeZones(flight)
+	if err != nil {
+		departureZone, arrivalZone = time.UTC, time.UTC
+	}
+	departureUTC, arrivalUTC := departure.UTC(), arrival.UTC()
+	flight.EstimatedDepartureTime = &departureUTC
+	flight.EstimatedArrivalTime = &arrivalUTC
+	flight.EstimatedDepartureLocalTime = departure.In(departureZone).Format(FlightTimeLayout)
+	flight.EstimatedArrivalLocalTime = arrival.In(arrivalZone).Format(FlightTimeLayout)
+}
+
+// checkFlightOpen rejects selling seats on a cancelled flight
+func checkFlightOpen(flight *models.Flight) error {
+	if flight.Status == FlightStatusCancelled {
+		return NewConflictError(CodeFlightCancelled, "flight %s has been cancelled", flight.FlightNumber)
+	}
+	return nil
+}
+
+// expireFlightHolds expires the active holds on a locked flight being cancelled or
+// deleted and returns their seats to it. The caller saves the flight.
+func expireFlightHolds(tx *gorm.DB, flight *models.Flight) error {
+	var holds []models.SeatHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("flight_id = ? AND hold_status = ?", flight.ID, HoldStatusActive).
+		Order("id ASC").
+		Find(&holds).Error; err != nil {
+		return fmt.Errorf("failed to load holds: %w", err)
+	}
+
+	for i := range holds {
+		if err := returnSeats(tx, flight, holds[i].FareClass, holds[i].Quantity); err != nil {
+			return err
+		}
+		holds[i].HoldStatus = HoldStatusExpired
+		if err := tx.Save(&holds[i]).Error; err != nil {
+			return fmt.Errorf("failed to expire hold: %w", err)
+		}
+	}
+	return nil
+}
+
+// activeFlightBookings locks the confirmed and waitlisted bookings on the flight
+func activeFlightBookings(tx *gorm.DB, flightID uint) ([]models.Booking, error) {
+	var bookings []models.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("flight_id = ? AND booking_status IN ?", flightID, []string{BookingStatusConfirmed, BookingStatusWaitlisted}).
+		Order("id ASC").
+		Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load bookings: %w", err)
+	}
+	return bookings, nil
+}
+
+// disruptBookings moves every active booking on a cancelled flight to Disrupted and
+// notifies its passengers. The seats stay with the bookings until they are cancelled.
+func disruptBookings(tx *gorm.DB, flight *models.Flight) error {
+	bookings, err := activeFlightBookings(tx, flight.ID)
+	if err != nil {
+		return err
+	}
+	for i := range bookings {
+		bookings[i].BookingStatus = BookingStatusDisrupted
+		if err := tx.Save(&bookings[i]).Error; err != nil {
+			return fmt.Errorf("failed to disrupt booking: %w", err)
+		}
+		if err := refreshOrderStatus(tx, &bookings[i]); err != nil {
+			return err
+		}
+		if err := enqueueFlightStatusEvent(tx, notification.EventBookingDisrupted, &bookings[i], flight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyDelay tells the passengers of every active booking on a delayed flight its
+// estimated departure
+func notifyDelay(tx *gorm.DB, flight *models.Flight) error {
+	bookings, err := activeFlightBookings(tx, flight.ID)
+	if err != nil {
+		return err
+	}
+	for i := range bookings {
+		if err := enqueueFlightStatusEvent(tx, notification.EventBookingDelayed, &bookings[i], flight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"flight-booking/internal/payment"
+	"flight-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertCode asserts that err is a domain error with the code
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var domainErr *Error
+	require.True(t, errors.As(err, &domainErr), "expected a domain error, got %v", err)
+	assert.Equal(t, code, domainErr.Code)
+}
+
+// TestUpdateStatus_CancelDisruptsBookings tests that cancelling a flight disrupts its
+// bookings, notifies their passengers, releases its holds and stops further sales
+func TestUpdateStatus_CancelDisruptsBookings(t *testing.T) {
+	// Given a non-refundable flight with a paid booking, an unpaid booking and a hold
+	db := setupTestDB(t)
+	gateway := payment.NewFakeGateway()
+	flights := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	bookings := NewBookingService(repository.NewGORMBookingRepository(db), db, FixedOversell{Seats: 10}, NewRefundService(db, gateway))
+	payments := NewPaymentService(db, gateway)
+	holds := NewHoldService(db, DefaultHoldTTL)
+
+	flight := newTestFlight()
+	require.NoError(t, SetFlightTimes(&flight, time.Now().AddDate(0, 0, 2), time.Now().AddDate(0, 0, 2).Add(4*time.Hour)))
+	flight.RefundRule = RefundRuleNonRefundable
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	paid, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	_, err = payments.PayBooking(paid.ID, "tok_visa")
+	require.NoError(t, err)
+	unpaid, err := bookings.CreateBooking(newTestBooking(flight.ID, "Lin Mei", 2))
+	require.NoError(t, err)
+	hold, err := holds.CreateHold(flight.ID, "", 1)
+	require.NoError(t, err)
+
+	// When
+	cancelled, err := flights.UpdateStatus(flight.ID, &FlightStatusUpdate{Status: FlightStatusCancelled, Reason: "Typhoon"})
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, FlightStatusCancelled, cancelled.Status)
+	assert.Equal(t, 2, cancelled.AvailableSeats, "the held seat is returned")
+	require.Len(t, cancelled.StatusHistory, 1)
+	assert.Equal(t, "Typhoon", cancelled.StatusHistory[0].Reason)
+
+	for _, id := range []uint{paid.ID, unpaid.ID} {
+		got, err := bookings.GetBooking(id)
+		require.NoError(t, err)
+		assert.Equal(t, BookingStatusDisrupted, got.BookingStatus)
+		assert.False(t, got.NotificationSent)
+	}
+	var events []models.OutboxEvent
+	require.NoError(t, db.Where("event_type = ?", notification.EventBookingDisrupted).Find(&events).Error)
+	assert.Len(t, events, 2)
+
+	var expired models.SeatHold
+	require.NoError(t, db.First(&expired, hold.ID).Error)
+	assert.Equal(t, HoldStatusExpired, expired.HoldStatus)
+
+	// And the flight can no longer be sold or paid for
+	_, err = bookings.CreateBooking(newTestBooking(flight.ID, "Wang Hao", 1))
+	assertCode(t, err, CodeFlightCancelled)
+	_, err = holds.CreateHold(flight.ID, "", 1)
+	assertCode(t, err, CodeFlightCancelled)
+	_, err = payments.PayBooking(unpaid.ID, "tok_visa")
+	assertCode(t, err, CodeFlightCancelled)
+
+	// And a disrupted booking is refunded in full despite the refund rule
+	refunded, err := bookings.CancelBooking(paid.ID)
+	require.NoError(t, err)
+	require.Len(t, refunded.Refunds, 1)
+	assert.Equal(t, paid.TotalPrice, refunded.Refunds[0].Amount)
+	assert.Zero(t, refunded.Refunds[0].Fee)
+}
+
+// TestUpdateStatus_Delay tests that a delay sets the estimated times, notifies the
+// passengers and is kept in the status history
+func TestUpdateStatus_Delay(t *testing.T) {
+	// Given a flight from Taipei at 10:00 with a booking
+	db := setupTestDB(t)
+	repo := repository.NewGORMFlightRepository(db)
+	flights := NewFlightService(repo, db)
+	flight := newTestFlight()
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+	booking, err := newTestBookingService(db).CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+
+	// When
+	delayed, err := flights.UpdateStatus(flight.ID, &FlightStatusUpdate{
+		Status:                 FlightStatusDelayed,
+		EstimatedDepartureTime: "2025-08-01 12:30",
+		Reason:                 "Late inbound aircraft",
+	})
+
+	// Then the arrival moves by as much as the departure
+	require.NoError(t, err)
+	assert.Equal(t, FlightStatusDelayed, delayed.Status)
+	assert.Equal(t, "2025-08-01 12:30", delayed.EstimatedDepartureLocalTime)
+	assert.Equal(t, "2025-08-01 16:30", delayed.EstimatedArrivalLocalTime)
+	require.NotNil(t, delayed.EstimatedDepartureTime)
+	assert.Equal(t, time.Date(2025, 8, 1, 4, 30, 0, 0, time.UTC), *delayed.EstimatedDepartureTime)
+
+	var outbox models.OutboxEvent
+	require.NoError(t, db.Where("event_type = ? AND booking_id = ?", notification.EventBookingDelayed, booking.ID).First(&outbox).Error)
+	var event notification.Event
+	require.NoError(t, json.Unmarshal([]byte(outbox.Payload), &event))
+	assert.Equal(t, FlightStatusDelayed, event.FlightStatus)
+	assert.Equal(t, delayed.EstimatedDepartureTime.Unix(), event.EstimatedDepartureTime.Unix())
+
+	// When boarding starts, the estimates are kept
+	_, err = flights.UpdateStatus(flight.ID, &FlightStatusUpdate{Status: FlightStatusBoarding})
+	require.NoError(t, err)
+
+	// Then
+	got, err := repo.FindByID(flight.ID)
+	require.NoError(t, err)
+	assert.Equal(t, FlightStatusBoarding, got.Status)
+	assert.Equal(t, "2025-08-01 12:30", got.EstimatedDepartureLocalTime)
+	require.Len(t, got.StatusHistory, 2)
+	assert.Equal(t, FlightStatusDelayed, got.StatusHistory[0].Status)
+	assert.Equal(t, FlightStatusBoarding, got.StatusHistory[1].Status)
+}
+
+// TestUpdateStatus_Invalid tests that unknown statuses, bad estimates and disallowed
+// transitions are rejected
+func TestUpdateStatus_Invalid(t *testing.T) {
+	db := setupTestDB(t)
+	flights := NewFlightService(repository.NewGORMFlightRepository(db), db)
+	flight := newTestFlight()
+	require.NoError(t, db.Create(&flight).Error)
+
+	invalid := map[string]FlightStatusUpdate{
+		"unknown status":     {Status: "OnTime"},
+		"delay without time": {Status: FlightStatusDelayed},
+		"delay earlier":      {Status: FlightStatusDelayed, EstimatedDepartureTime: "2025-08-01 09:00"},
+		"bad time":           {Status: FlightStatusDelayed, EstimatedDepartureTime: "2025/08/01 12:00"},
+		"arrival first":      {Status: FlightStatusDelayed, EstimatedDepartureTime: "2025-08-01 12:00", EstimatedArrivalTime: "2025-08-01 12:00"},
+		"estimate on cancel": {Status: FlightStatusCancelled, EstimatedDepartureTime: "2025-08-01 12:00"},
+	}
+	for name, update := range invalid {
+		_, err := flights.UpdateStatus(flight.ID, &update)
+		assert.ErrorIs(t, err, ErrValidation, name)
+	}
+
+	_, err := flights.UpdateStatus(flight.ID, &FlightStatusUpdate{Status: FlightStatusArrived})
+	assertCode(t, err, CodeInvalidStatusTransition)
+
+	_, err = flights.UpdateStatus(flight.ID, &FlightStatusUpdate{Status: FlightStatusCancelled})
+	require.NoError(t, err)
+	_, err = flights.UpdateStatus(flight.ID, &FlightStatusUpdate{Status: FlightStatusScheduled})
+	assertCode(t, err, CodeInvalidStatusTransition)
+
+	_, err = flights.UpdateStatus(999, &FlightStatusUpdate{Status: FlightStatusDelayed, EstimatedDepartureTime: "2025-08-01 12:00"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flight-booking/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	HoldStatusActive    = "Active"
+	HoldStatusConverted = "Converted"
+	HoldStatusExpired   = "Expired"
+)
+
+// DefaultHoldTTL is how long seats stay reserved while the customer checks out
+const DefaultHoldTTL = 15 * time.Minute
+
+type HoldService interface {
+	CreateHold(flightID uint, fareClass string, quantity int) (*models.SeatHold, error)
+	ReleaseExpiredHolds(now time.Time) (int, error)
+}
+
+type HoldServiceImpl struct {
+	DB       *gorm.DB
+	TTL      time.Duration
+	Waitlist WaitlistEngine
+}
+
+func NewHoldService(db *gorm.DB, ttl time.Duration) HoldService {
+	return &HoldServiceImpl{
+		DB:       db,
+		TTL:      ttl,
+		Waitlist: NewWaitlistEngine(),
+	}
+}
+
+// CreateHold deducts seats from the flight, or from a fare class on flights that have
+// them, and returns a hold that expires after the service's TTL. Holds never oversell:
+// only seats that are actually free can be held.
+func (s *HoldServiceImpl) CreateHold(flightID uint, fareClass string, quantity int) (*models.SeatHold, error) {
+	if quantity <= 0 {
+		return nil, NewValidationError(CodeInvalidRequest, "quantity must be a positive integer")
+	}
+
+	token, err := generateHoldToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hold := models.SeatHold{
+		Token:      token,
+		FlightID:   flightID,
+		Quantity:   quantity,
+		HoldStatus: HoldStatusActive,
+		ExpiresAt:  time.Now().Add(s.TTL),
+	}
+
+	err = inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		hold.ID = 0
+		var flight models.Flight
+		// Select flight with pessimistic lock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", flightID).
+			First(&flight).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeFlightNotFound, "flight not found")
+			}
+			return fmt.Errorf("failed to lock flight: %w", err)
+		}
+		if err := checkFlightOpen(&flight); err != nil {
+			return err
+		}
+
+		classes, err := lockFareClasses(tx, flight.ID)
+		if err != nil {
+			return err
+		}
+		class, err := selectFareClass(classes, fareClass, quantity)
+		if err != nil {
+			return err
+		}
+
+		if class == nil {
+			if flight.AvailableSeats < quantity {
+				return NewInsufficientSeatsError("not enough seats to hold: available=%d", flight.AvailableSeats)
+			}
+			flight.AvailableSeats -= quantity
+		} else {
+			if class.AvailableSeats < quantity {
+				return NewInsufficientSeatsError("not enough %s seats to hold: available=%d", class.Name, class.AvailableSeats)
+			}
+			class.AvailableSeats -= quantity
+			if err := tx.Save(class).Error; err != nil {
+				return fmt.Errorf("failed to update fare class seats: %w", err)
+			}
+			syncFlightInventory(&flight, classes)
+			hold.FareClass = class.Name
+		}
+
+		if err := saveFlight(tx, &flight); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&hold).Error; err != nil {
+			return fmt.Errorf("failed to create hold: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// ReleaseExpiredHolds returns the seats of every active hold that expired before now
+// to its flight and promotes waitlisted bookings into the freed seats. It returns the
+// number of holds released.
+func (s *HoldServiceImpl) ReleaseExpiredHolds(now time.Time) (int, error) {
+	released := 0
+
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		released = 0
+		var holds []models.SeatHold
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hold_status = ? AND expires_at <= ?", HoldStatusActive, now).
+			Order("flight_id ASC, id ASC").
+			Find(&holds).Error; err != nil {
+			return fmt.Errorf("failed to load expired holds: %w", err)
+		}
+
+		for i := range holds {
+			hold := &holds[i]
+
+			var flight models.Flight
+			// The flight may have been deleted while the hold was active; its seats are still returned
+			if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", hold.FlightID).
+				First(&flight).Error; err != nil {
+				return fmt.Errorf("failed to lock flight %d: %w", hold.FlightID, err)
+			}
+
+			if err := returnSeats(tx, &flight, hold.FareClass, hold.Quantity); err != nil {
+				return err
+			}
+			if err := saveFlight(tx.Unscoped(), &flight); err != nil {
+				return err
+			}
+
+			hold.HoldStatus = HoldStatusExpired
+			if err := tx.Save(hold).Error; err != nil {
+				return fmt.Errorf("failed to expire hold: %w", err)
+			}
+
+			if err := promoteWaitlist(tx, s.Waitlist, &flight); err != nil {
+				return err
+			}
+			released++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return released, nil
+}
+
+// generateHoldToken returns a random 128-bit token encoded as hex
+func generateHoldToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate hold token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
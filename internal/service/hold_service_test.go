@@ -0,0 +1,76 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeatHold_ConvertToBooking tests that a hold deducts seats once and converts into a confirmed booking
+func TestSeatHold_ConvertToBooking(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	holds := NewHoldService(db, DefaultHoldTTL)
+	bookings := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 3
+	require.NoError(t, db.Create(&flight).Error)
+
+	hold, err := holds.CreateHold(flight.ID, "", 2)
+	require.NoError(t, err)
+
+	var got models.Flight
+	require.NoError(t, db.First(&got, flight.ID).Error)
+	assert.Equal(t, 1, got.AvailableSeats)
+
+	// When
+	booking := newTestBooking(0, "Chen Wei", 2)
+	created, err := bookings.CreateBookingFromHold(hold.Token, booking)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, BookingStatusConfirmed, created.BookingStatus)
+	assert.Equal(t, flight.ID, created.FlightID)
+	require.NoError(t, db.First(&got, flight.ID).Error)
+	assert.Equal(t, 1, got.AvailableSeats)
+
+	_, err = bookings.CreateBookingFromHold(hold.Token, newTestBooking(0, "Chen Wei", 2))
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+// TestReleaseExpiredHolds tests that the sweeper returns expired holds to the flight
+func TestReleaseExpiredHolds(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	holds := NewHoldService(db, DefaultHoldTTL)
+	bookings := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	hold, err := holds.CreateHold(flight.ID, "", 2)
+	require.NoError(t, err)
+
+	// Nothing has expired yet
+	released, err := holds.ReleaseExpiredHolds(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, released)
+
+	// When
+	released, err = holds.ReleaseExpiredHolds(time.Now().Add(DefaultHoldTTL + time.Second))
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 1, released)
+	var got models.Flight
+	require.NoError(t, db.First(&got, flight.ID).Error)
+	assert.Equal(t, 2, got.AvailableSeats)
+
+	_, err = bookings.CreateBookingFromHold(hold.Token, newTestBooking(0, "Chen Wei", 2))
+	assert.ErrorIs(t, err, ErrConflict)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Row locks are not enough on their own: SQLite ignores SELECT ... FOR UPDATE, so two
+// transactions can read the same seat count. Postgres and MySQL honour the lock. Every
+// flight write is therefore a compare-and-swap on Flight.Version, and transactions
+// that lose the race are retried.
+const (
+	inventoryMaxAttempts = 8
+	inventoryBaseBackoff = 2 * time.Millisecond
+)
+
+// errInventoryConflict reports that another transaction changed the flight after it was read
+var errInventoryConflict = errors.New("flight inventory changed concurrently")
+
+// saveFlight writes every column of the flight, but only if its version is still the
+// one that was read, and bumps the version. Fare class rows are only written together
+// with their flight, so the flight's version guards them too. Pass tx.Unscoped() to
+// update a deleted flight.
+func saveFlight(tx *gorm.DB, flight *models.Flight) error {
+	version := flight.Version
+	flight.Version++
+
+	result := tx.Model(flight).
+		Select("*").
+		Omit(clause.Associations).
+		Where("version = ?", version).
+		Updates(flight)
+	if result.Error != nil {
+		flight.Version = version
+		return fmt.Errorf("failed to update flight: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		flight.Version = version
+		return errInventoryConflict
+	}
+	return nil
+}
+
+// inventoryTransaction runs fn in a transaction and runs it again from the start when
+// it lost a race for a flight's inventory. fn must reload everything it locks, since
+// each attempt starts from scratch. When every attempt loses, a Conflict error asks the
+// client to try again.
+func inventoryTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 1; attempt <= inventoryMaxAttempts; attempt++ {
+		err = db.Transaction(fn)
+		if !isInventoryRace(err) {
+			return err
+		}
+		// Jitter keeps the losers from colliding again on the next attempt
+		backoff := inventoryBaseBackoff << (attempt - 1)
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff))))
+	}
+	return NewConflictError(CodeInventoryBusy, "flight inventory is busy, please try again: %v", err)
+}
+
+// isInventoryRace reports whether err means another transaction got to the same rows
+// first: a failed compare-and-swap, SQLite refusing a write because of a concurrent
+// one, or Postgres and MySQL picking this transaction as a deadlock victim
+func isInventoryRace(err error) bool {
+	if errors.Is(err, errInventoryConflict) {
+		return true
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01" // serialization_failure, deadlock_detected
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205 // deadlock, lock wait timeout
+	}
+	return false
+}
+
+// resetNewBooking clears what a rolled-back attempt assigned to a booking that was
+// being created, so the next attempt inserts it afresh. The timestamps are left for
+// GORM to set and payment fields are cleared as well: only PayBooking records a charge.
+func resetNewBooking(booking *models.Booking) {
+	booking.Model = gorm.Model{}
+	booking.PaymentReference = ""
+	booking.PaidAt = nil
+	for i := range booking.Passengers {
+		booking.Passengers[i].Model = gorm.Model{}
+		booking.Passengers[i].BookingID = 0
+		// A seat request dropped because the attempt waitlisted the booking is made again
+		if seat := booking.Passengers[i].DroppedSeat; seat != "" {
+			booking.Passengers[i].Seat, booking.Passengers[i].DroppedSeat = seat, ""
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupConcurrentTestDB opens a file database with several connections, so
+// transactions really run side by side unlike the single-connection setupTestDB
+func setupConcurrentTestDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "flights.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		// Lost races are expected and retried; keep them out of the test output
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(8)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(testModels...))
+	return db
+}
+
+// TestCreateBooking_ConcurrentInventory tests that many concurrent bookings on one
+// flight never sell more than its seats plus the oversell limit
+func TestCreateBooking_ConcurrentInventory(t *testing.T) {
+	// Given: 5 seats that may oversell by 3
+	db := setupConcurrentTestDB(t)
+	const seats, oversellLimit, buyers = 5, 3, 40
+	bookingService := NewBookingService(nil, db, FixedOversell{Seats: oversellLimit}, nil)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = seats
+	flight.Capacity = seats
+	require.NoError(t, db.Create(&flight).Error)
+
+	// When
+	var wg sync.WaitGroup
+	errs := make([]error, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = bookingService.CreateBooking(newTestBooking(flight.ID, fmt.Sprintf("Buyer %d", i), 1))
+		}(i)
+	}
+	wg.Wait()
+
+	// Then: every seat up to the limit is sold and the rest are refused
+	sold := 0
+	for _, err := range errs {
+		if err == nil {
+			sold++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrInsufficientSeats)
+	}
+	assert.Equal(t, seats+oversellLimit, sold)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.GreaterOrEqual(t, reloaded.AvailableSeats, -oversellLimit)
+	assert.Equal(t, seats-sold, reloaded.AvailableSeats)
+
+	var booked int64
+	require.NoError(t, db.Model(&models.Booking{}).Where("flight_id = ?", flight.ID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&booked).Error)
+	assert.Equal(t, int64(sold), booked, "every booking's seats were deducted exactly once")
+}
+
+// TestSaveFlight_StaleVersion tests that a flight read before another write cannot overwrite it
+func TestSaveFlight_StaleVersion(t *testing.T) {
+	db := setupTestDB(t)
+	flight := newTestFlight()
+	require.NoError(t, db.Create(&flight).Error)
+
+	stale := flight
+	flight.AvailableSeats = 0
+	require.NoError(t, saveFlight(db, &flight))
+
+	stale.AvailableSeats = 5
+	assert.ErrorIs(t, saveFlight(db, &stale), errInventoryConflict)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, 0, reloaded.AvailableSeats)
+	assert.Equal(t, 1, reloaded.Version)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/refdata"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ItinerarySortDuration = "duration"
+	ItinerarySortPrice    = "price"
+
+	// maxLegDuration bounds how far ahead connecting flights are loaded
+	maxLegDuration = 18 * time.Hour
+)
+
+// ItineraryQuery describes a connecting-itinerary search
+type ItineraryQuery struct {
+	DepartureAirport string
+	ArrivalAirport   string
+	Date             time.Time // local departure date of the first leg at the origin
+	MaxStops         int
+	MinLayover       time.Duration
+	MaxLayover       time.Duration
+	Passengers       int
+	SortBy           string // ItinerarySortDuration or ItinerarySortPrice
+}
+
+// Itinerary is a priced sequence of flights from origin to destination
+type Itinerary struct {
+	Segments      []models.Flight
+	Stops         int
+	TotalPrice    float64
+	TotalDuration time.Duration
+	DepartureTime time.Time // UTC
+	ArrivalTime   time.Time // UTC
+}
+
+type ItineraryService interface {
+	SearchItineraries(query ItineraryQuery) ([]Itinerary, error)
+}
+
+type ItineraryServiceImpl struct {
+	DB *gorm.DB
+}
+
+func NewItineraryService(db *gorm.DB) ItineraryService {
+	return &ItineraryServiceImpl{DB: db}
+}
+
+func (s *ItineraryServiceImpl) SearchItineraries(query ItineraryQuery) ([]Itinerary, error) {
+	if err := checkAirport(s.DB, "departure airport", query.DepartureAirport); err != nil {
+		return nil, err
+	}
+	if err := checkAirport(s.DB, "arrival airport", query.ArrivalAirport); err != nil {
+		return nil, err
+	}
+
+	// The date is the local date at the origin
+	zone, err := refdata.Location(query.DepartureAirport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find time zone: %w", err)
+	}
+	dayStart := time.Date(query.Date.Year(), query.Date.Month(), query.Date.Day(), 0, 0, 0, 0, zone)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	windowEnd := dayEnd.Add(time.Duration(query.MaxStops) * (query.MaxLayover + maxLegDuration))
+
+	var flights []models.Flight
+	if err := s.DB.Where("departure_time >= ? AND departure_time < ? AND available_seats >= ? AND status <> ?",
+		dayStart.UTC(), windowEnd.UTC(), query.Passengers, FlightStatusCancelled).
+		Find(&flights).Error; err != nil {
+		return nil, fmt.Errorf("failed to load flights: %w", err)
+	}
+
+	// Index legs by departure airport so connections can be looked up directly
+	byOrigin := make(map[string][]models.Flight)
+	for _, f := range flights {
+		if !f.ArrivalTime.After(f.DepartureTime) {
+			continue // skip rows with inconsistent times
+		}
+		byOrigin[f.DepartureAirport] = append(byOrigin[f.DepartureAirport], f)
+	}
+
+	var itineraries []Itinerary
+	var path []models.Flight
+	visited := map[string]bool{query.DepartureAirport: true}
+
+	var extend func(origin string, earliest, latest time.Time)
+	extend = func(origin string, earliest, latest time.Time) {
+		for _, l := range byOrigin[origin] {
+			if l.DepartureTime.Before(earliest) || l.DepartureTime.After(latest) {
+				continue
+			}
+
+			next := l.ArrivalAirport
+			if next == query.ArrivalAirport {
+				itineraries = append(itineraries, newItinerary(append(path, l)))
+				continue
+			}
+
+			// Stop expanding once the stop limit is reached and never revisit an airport
+			if len(path) >= query.MaxStops || visited[next] {
+				continue
+			}
+
+			visited[next] = true
+			path = append(path, l)
+			extend(next, l.ArrivalTime.Add(query.MinLayover), l.ArrivalTime.Add(query.MaxLayover))
+			path = path[:len(path)-1]
+			visited[next] = false
+		}
+	}
+	// Flight times have minute precision, so the last minute of the day is the upper bound
+	extend(query.DepartureAirport, dayStart, dayEnd.Add(-time.Minute))
+
+	sortItineraries(itineraries, query.SortBy)
+	return itineraries, nil
+}
+
+func newItinerary(legs []models.Flight) Itinerary {
+	itinerary := Itinerary{
+		Segments:      make([]models.Flight, 0, len(legs)),
+		Stops:         len(legs) - 1,
+		DepartureTime: legs[0].DepartureTime,
+		ArrivalTime:   legs[len(legs)-1].ArrivalTime,
+	}
+	for _, l := range legs {
+		itinerary.Segments = append(itinerary.Segments, l)
+		itinerary.TotalPrice += l.Price
+	}
+	itinerary.TotalDuration = itinerary.ArrivalTime.Sub(itinerary.DepartureTime)
+	return itinerary
+}
+
+// sortItineraries orders by the requested key, breaking ties with the other key
+// and then by fewer stops and earlier departure
+func sortItineraries(itineraries []Itinerary, sortBy string) {
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		a, b := itineraries[i], itineraries[j]
+		if sortBy == ItinerarySortPrice {
+			if a.TotalPrice != b.TotalPrice {
+				return a.TotalPrice < b.TotalPrice
+			}
+			if a.TotalDuration != b.TotalDuration {
+				return a.TotalDuration < b.TotalDuration
+			}
+		} else {
+			if a.TotalDuration != b.TotalDuration {
+				return a.TotalDuration < b.TotalDuration
+			}
+			if a.TotalPrice != b.TotalPrice {
+				return a.TotalPrice < b.TotalPrice
+			}
+		}
+		if a.Stops != b.Stops {
+			return a.Stops < b.Stops
+		}
+		return a.DepartureTime.Before(b.DepartureTime)
+	})
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLeg builds a flight from local times at its airports
+func newTestLeg(number, from, to, departure, arrival string, price float64) models.Flight {
+	flight := models.Flight{
+		FlightNumber:       number,
+		DepartureAirport:   from,
+		ArrivalAirport:     to,
+		DepartureLocalTime: departure,
+		ArrivalLocalTime:   arrival,
+		Airline:            "EVA Air",
+		Price:              price,
+		AvailableSeats:     10,
+	}
+	if err := setFlightTimesFromLocal(&flight); err != nil {
+		panic(err)
+	}
+	return flight
+}
+
+// TestSearchItineraries_Connections tests direct, one-stop and two-stop itineraries with layover limits
+func TestSearchItineraries_Connections(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	require.NoError(t, db.Create(&[]models.Flight{
+		newTestLeg("DIRECT", "KHH", "CTS", "2025-08-01 08:00", "2025-08-01 13:30", 900),
+		newTestLeg("KHH-TPE", "KHH", "TPE", "2025-08-01 07:00", "2025-08-01 08:00", 100),
+		newTestLeg("TPE-CTS", "TPE", "CTS", "2025-08-01 09:30", "2025-08-01 13:00", 300),       // 90 min layover
+		newTestLeg("TPE-CTS-TIGHT", "TPE", "CTS", "2025-08-01 08:20", "2025-08-01 11:50", 200), // 20 min layover
+		newTestLeg("TPE-CTS-LATE", "TPE", "CTS", "2025-08-01 20:00", "2025-08-01 23:30", 150),  // 12 h layover
+		newTestLeg("TPE-NRT", "TPE", "NRT", "2025-08-01 09:00", "2025-08-01 13:00", 200),
+		newTestLeg("NRT-CTS", "NRT", "CTS", "2025-08-01 14:30", "2025-08-01 16:00", 100),
+		newTestLeg("NRT-TPE", "NRT", "TPE", "2025-08-01 14:00", "2025-08-01 16:30", 100), // would revisit TPE
+	}).Error)
+
+	svc := NewItineraryService(db)
+	query := ItineraryQuery{
+		DepartureAirport: "KHH",
+		ArrivalAirport:   "CTS",
+		Date:             time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		MaxStops:         2,
+		MinLayover:       45 * time.Minute,
+		MaxLayover:       6 * time.Hour,
+		Passengers:       1,
+		SortBy:           ItinerarySortDuration,
+	}
+
+	// When
+	itineraries, err := svc.SearchItineraries(query)
+	require.NoError(t, err)
+
+	// Then
+	var routes [][]string
+	for _, it := range itineraries {
+		var numbers []string
+		for _, f := range it.Segments {
+			numbers = append(numbers, f.FlightNumber)
+		}
+		routes = append(routes, numbers)
+	}
+	assert.Equal(t, [][]string{
+		{"DIRECT"},
+		{"KHH-TPE", "TPE-CTS"},
+		{"KHH-TPE", "TPE-NRT", "NRT-CTS"},
+	}, routes)
+	assert.Equal(t, 2, itineraries[2].Stops)
+	assert.Equal(t, 400.0, itineraries[1].TotalPrice)
+	assert.Equal(t, 5*time.Hour, itineraries[1].TotalDuration, "07:00 in KHH to 13:00 in CTS, an hour ahead")
+
+	// When sorted by price the cheapest connection comes first
+	query.SortBy = ItinerarySortPrice
+	itineraries, err = svc.SearchItineraries(query)
+	require.NoError(t, err)
+	assert.Equal(t, 400.0, itineraries[0].TotalPrice)
+
+	// When limited to direct flights only the direct itinerary remains
+	query.MaxStops = 0
+	itineraries, err = svc.SearchItineraries(query)
+	require.NoError(t, err)
+	assert.Len(t, itineraries, 1)
+}
+
+// TestSearchItineraries_LocalDate tests that the date is the local date at the origin
+func TestSearchItineraries_LocalDate(t *testing.T) {
+	// Given a flight leaving Taipei just after midnight local time, which is still the
+	// previous day in UTC
+	db := setupTestDB(t)
+	require.NoError(t, db.Create(&[]models.Flight{
+		newTestLeg("EARLY", "TPE", "NRT", "2025-08-02 00:30", "2025-08-02 04:30", 200),
+		newTestLeg("LATE", "TPE", "NRT", "2025-08-01 23:30", "2025-08-02 03:30", 200),
+	}).Error)
+
+	// When
+	itineraries, err := NewItineraryService(db).SearchItineraries(ItineraryQuery{
+		DepartureAirport: "TPE",
+		ArrivalAirport:   "NRT",
+		Date:             time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC),
+		Passengers:       1,
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, itineraries, 1)
+	assert.Equal(t, "EARLY", itineraries[0].Segments[0].FlightNumber)
+	assert.Equal(t, time.Date(2025, 8, 1, 16, 30, 0, 0, time.UTC), itineraries[0].DepartureTime)
+}
+
+// TestSearchItineraries_UnknownAirport tests that unknown airports are rejected
+func TestSearchItineraries_UnknownAirport(t *testing.T) {
+	db := setupTestDB(t)
+
+	_, err := NewItineraryService(db).SearchItineraries(ItineraryQuery{
+		DepartureAirport: "TPE",
+		ArrivalAirport:   "XXX",
+		Date:             time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		Passengers:       1,
+	})
+
+	var domainErr *Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, CodeUnknownAirport, domainErr.Code)
+}
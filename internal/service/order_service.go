@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/repository"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxOrderSegments limits how many flights a single order may contain
+const MaxOrderSegments = 6
+
+// OrderStatusPartiallyCancelled marks an order with some but not all of its bookings
+// cancelled. Otherwise an order takes a booking status; see orderStatus.
+const OrderStatusPartiallyCancelled = "PartiallyCancelled"
+
+type OrderService interface {
+	CreateOrder(order *models.Order) (*models.Order, error)
+	GetOrder(id uint) (*models.Order, error)
+}
+
+type OrderServiceImpl struct {
+	OrderRepo     repository.OrderRepository
+	DB            *gorm.DB
+	Oversell      OversellPolicy // used when neither the flight nor an OversellRule sets one
+	PaymentWindow time.Duration
+}
+
+func NewOrderService(orderRepo repository.OrderRepository, db *gorm.DB, oversell OversellPolicy) OrderService {
+	return &OrderServiceImpl{
+		OrderRepo:     orderRepo,
+		DB:            db,
+		Oversell:      oversell,
+		PaymentWindow: DefaultPaymentWindow,
+	}
+}
+
+// CreateOrder reserves seats on every segment of the order in one transaction.
+// order.Bookings must hold one booking per segment with only FlightID set.
+func (s *OrderServiceImpl) CreateOrder(order *models.Order) (*models.Order, error) {
+	if len(order.Bookings) == 0 || len(order.Bookings) > MaxOrderSegments {
+		return nil, NewValidationError(CodeInvalidOrder, "an order must have between 1 and %d segments", MaxOrderSegments)
+	}
+	if order.Quantity <= 0 {
+		return nil, NewValidationError(CodeInvalidOrder, "quantity must be a positive integer")
+	}
+
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		order.ID, order.TotalPrice = 0, 0
+		for i := range order.Bookings {
+			resetNewBooking(&order.Bookings[i])
+		}
+
+		// Lock every flight up front in id order so concurrent orders sharing
+		// flights always acquire their locks in the same order
+		flightIDs := make([]uint, 0, len(order.Bookings))
+		for _, b := range order.Bookings {
+			flightIDs = append(flightIDs, b.FlightID)
+		}
+
+		var flights []models.Flight
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", flightIDs).
+			Order("id ASC").
+			Find(&flights).Error; err != nil {
+			return fmt.Errorf("failed to lock flights: %w", err)
+		}
+
+		flightsByID := make(map[uint]models.Flight, len(flights))
+		for _, f := range flights {
+			flightsByID[f.ID] = f
+		}
+
+		if err := validateSegmentOrder(order.Bookings, flightsByID); err != nil {
+			return err
+		}
+
+		// Create the order first so its ID can be set on every segment
+		order.OrderStatus = BookingStatusConfirmed
+		if err := tx.Omit("Bookings").Create(order).Error; err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+
+		for i := range order.Bookings {
+			booking := &order.Bookings[i]
+			booking.OrderID = &order.ID
+			booking.PassengerName = order.PassengerName
+			booking.Quantity = order.Quantity
+			// Each segment is paid through its own booking
+			if err := reserveSeats(tx, booking, s.Oversell, s.PaymentWindow); err != nil {
+				return err // Rollback every segment reserved so far
+			}
+
+			order.TotalPrice += booking.TotalPrice
+		}
+		order.OrderStatus = orderStatus(bookingStatuses(order.Bookings))
+
+		if err := tx.Omit("Bookings").Save(order).Error; err != nil {
+			return fmt.Errorf("failed to update order: %w", err)
+		}
+
+		return nil // Commit transaction
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *OrderServiceImpl) GetOrder(id uint) (*models.Order, error) {
+	order, err := s.OrderRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError(CodeOrderNotFound, "order not found")
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return order, nil
+}
+
+// refreshOrderStatus recomputes the status of the booking's order, if it has one, from
+// all of its bookings. It must run in the transaction that changed the booking's
+// status, after the booking was saved.
+func refreshOrderStatus(tx *gorm.DB, booking *models.Booking) error {
+	if booking.OrderID == nil {
+		return nil
+	}
+
+	var order models.Order
+	// Lock the order so segments changed concurrently are all counted
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&order, *booking.OrderID).Error; err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	var statuses []string
+	if err := tx.Model(&models.Booking{}).
+		Where("order_id = ?", order.ID).
+		Pluck("booking_status", &statuses).Error; err != nil {
+		return fmt.Errorf("failed to load order bookings: %w", err)
+	}
+
+	if err := tx.Model(&order).Update("order_status", orderStatus(statuses)).Error; err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	return nil
+}
+
+// orderStatus derives an order's status from the statuses of its bookings. A disrupted
+// segment needs the customer's attention first, then a cancelled one, then a waitlisted one.
+func orderStatus(statuses []string) string {
+	count := make(map[string]int, len(statuses))
+	for _, status := range statuses {
+		count[status]++
+	}
+	switch {
+	case count[BookingStatusCancelled] == len(statuses):
+		return BookingStatusCancelled
+	case count[BookingStatusDisrupted] > 0:
+		return BookingStatusDisrupted
+	case count[BookingStatusCancelled] > 0:
+		return OrderStatusPartiallyCancelled
+	case count[BookingStatusWaitlisted] > 0:
+		return BookingStatusWaitlisted
+	default:
+		return BookingStatusConfirmed
+	}
+}
+
+// bookingStatuses lists the status of each booking
+func bookingStatuses(bookings []models.Booking) []string {
+	statuses := make([]string, 0, len(bookings))
+	for _, b := range bookings {
+		statuses = append(statuses, b.BookingStatus)
+	}
+	return statuses
+}
+
+// validateSegmentOrder checks that every flight exists and that each segment
+// departs after the previous one has arrived
+func validateSegmentOrder(bookings []models.Booking, flightsByID map[uint]models.Flight) error {
+	var previousArrival time.Time
+	for i, b := range bookings {
+		flight, ok := flightsByID[b.FlightID]
+		if !ok {
+			return NewNotFoundError(CodeFlightNotFound, "flight not found: %d", b.FlightID)
+		}
+
+		// Compare in UTC so segments crossing time zones are ordered correctly
+		if i > 0 && !flight.DepartureTime.After(previousArrival) {
+			return NewValidationError(CodeInvalidOrder, "segment %d departs before segment %d arrives", i+1, i)
+		}
+		previousArrival = flight.ArrivalTime
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"flight-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestOrder creates an order request for the given flights with adult passengers
+func newTestOrder(quantity int, flightIDs ...uint) models.Order {
+	order := models.Order{PassengerName: "Test User", Quantity: quantity}
+	for _, id := range flightIDs {
+		order.Bookings = append(order.Bookings, *newTestBooking(id, "", quantity))
+	}
+	return order
+}
+
+// TestCreateOrder_RoundTrip tests that every segment of an order is booked
+func TestCreateOrder_RoundTrip(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewOrderService(repository.NewGORMOrderRepository(db), db, NoOversell{})
+
+	outbound := newTestLeg("BR198", "TPE", "NRT", "2025-08-01 08:00", "2025-08-01 12:00", 300)
+	inbound := newTestLeg("BR197", "NRT", "TPE", "2025-08-05 13:00", "2025-08-05 16:00", 250)
+	require.NoError(t, db.Create(&outbound).Error)
+	require.NoError(t, db.Create(&inbound).Error)
+
+	order := newTestOrder(2, outbound.ID, inbound.ID)
+
+	// When
+	created, err := svc.CreateOrder(&order)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, BookingStatusConfirmed, created.OrderStatus)
+	assert.Equal(t, 1100.0, created.TotalPrice)
+
+	got, err := svc.GetOrder(created.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Bookings, 2)
+	assert.Equal(t, outbound.ID, got.Bookings[0].FlightID)
+	assert.Equal(t, created.ID, *got.Bookings[1].OrderID)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, inbound.ID).Error)
+	assert.Equal(t, 8, reloaded.AvailableSeats)
+}
+
+// TestCreateOrder_AllOrNothing tests that a failing segment rolls back the whole order
+func TestCreateOrder_AllOrNothing(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewOrderService(repository.NewGORMOrderRepository(db), db, NoOversell{})
+
+	outbound := newTestLeg("BR198", "TPE", "NRT", "2025-08-01 08:00", "2025-08-01 12:00", 300)
+	inbound := newTestLeg("BR197", "NRT", "TPE", "2025-08-05 13:00", "2025-08-05 16:00", 250)
+	inbound.AvailableSeats = 1
+	require.NoError(t, db.Create(&outbound).Error)
+	require.NoError(t, db.Create(&inbound).Error)
+
+	order := newTestOrder(2, outbound.ID, inbound.ID)
+
+	// When
+	_, err := svc.CreateOrder(&order)
+
+	// Then
+	assert.ErrorIs(t, err, ErrInsufficientSeats)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, outbound.ID).Error)
+	assert.Equal(t, 10, reloaded.AvailableSeats, "outbound seats must be rolled back")
+
+	var bookings, orders int64
+	db.Model(&models.Booking{}).Count(&bookings)
+	db.Model(&models.Order{}).Count(&orders)
+	assert.Zero(t, bookings)
+	assert.Zero(t, orders)
+}
+
+// TestCreateOrder_SegmentsOutOfOrder tests that segments must be chronological
+func TestCreateOrder_SegmentsOutOfOrder(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewOrderService(repository.NewGORMOrderRepository(db), db, NoOversell{})
+
+	outbound := newTestLeg("BR198", "TPE", "NRT", "2025-08-01 08:00", "2025-08-01 12:00", 300)
+	inbound := newTestLeg("BR197", "NRT", "TPE", "2025-08-01 11:00", "2025-08-01 14:00", 250)
+	require.NoError(t, db.Create(&outbound).Error)
+	require.NoError(t, db.Create(&inbound).Error)
+
+	order := newTestOrder(1, outbound.ID, inbound.ID)
+
+	// When
+	_, err := svc.CreateOrder(&order)
+
+	// Then
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+// TestOrderStatus_FollowsBookings tests that the order's status is recomputed when its
+// bookings are promoted, cancelled or disrupted
+func TestOrderStatus_FollowsBookings(t *testing.T) {
+	// Given an order whose return segment is waitlisted
+	db := setupTestDB(t)
+	orders := NewOrderService(repository.NewGORMOrderRepository(db), db, FixedOversell{Seats: 5})
+	bookings := newTestBookingService(db)
+	flights := NewFlightService(repository.NewGORMFlightRepository(db), db)
+
+	outbound := newTestLeg("BR198", "TPE", "NRT", "2025-08-01 08:00", "2025-08-01 12:00", 300)
+	inbound := newTestLeg("BR197", "NRT", "TPE", "2025-08-05 13:00", "2025-08-05 16:00", 250)
+	inbound.AvailableSeats = 1
+	require.NoError(t, db.Create(&outbound).Error)
+	require.NoError(t, db.Create(&inbound).Error)
+	solo, err := bookings.CreateBooking(newTestBooking(inbound.ID, "A", 1))
+	require.NoError(t, err)
+
+	order := newTestOrder(1, outbound.ID, inbound.ID)
+	created, err := orders.CreateOrder(&order)
+	require.NoError(t, err)
+	require.Equal(t, BookingStatusWaitlisted, created.OrderStatus)
+
+	statusOf := func() string {
+		got, err := orders.GetOrder(created.ID)
+		require.NoError(t, err)
+		return got.OrderStatus
+	}
+
+	// When / Then: the freed seat promotes the return segment
+	_, err = bookings.CancelBooking(solo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, statusOf())
+
+	// A cancelled return flight disrupts the order
+	_, err = flights.UpdateStatus(inbound.ID, &FlightStatusUpdate{Status: FlightStatusCancelled})
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusDisrupted, statusOf())
+
+	// Cancelling segments one at a time
+	_, err = bookings.CancelBooking(created.Bookings[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, OrderStatusPartiallyCancelled, statusOf())
+
+	_, err = bookings.CancelBooking(created.Bookings[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusCancelled, statusOf())
+}
+
+// TestOrderStatus_ReleaseUnpaid tests that releasing an unpaid segment updates its order
+func TestOrderStatus_ReleaseUnpaid(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	orders := NewOrderService(repository.NewGORMOrderRepository(db), db, NoOversell{})
+	payments := NewPaymentService(db, payment.NewFakeGateway())
+
+	outbound := newTestLeg("BR198", "TPE", "NRT", "2025-08-01 08:00", "2025-08-01 12:00", 300)
+	require.NoError(t, db.Create(&outbound).Error)
+	order := newTestOrder(1, outbound.ID)
+	created, err := orders.CreateOrder(&order)
+	require.NoError(t, err)
+
+	// When
+	released, err := payments.ReleaseUnpaidBookings(time.Now().Add(DefaultPaymentWindow + time.Minute))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	got, err := orders.GetOrder(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusCancelled, got.OrderStatus)
+}
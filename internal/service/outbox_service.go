@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OutboxStatusPending   = "Pending"
+	OutboxStatusDelivered = "Delivered"
+	OutboxStatusFailed    = "Failed"
+)
+
+// An event is given up on after outboxMaxAttempts attempts; see retryBackoff for the delay
+const (
+	outboxMaxAttempts = 8
+	outboxBatchSize   = 100
+)
+
+// enqueueBookingEvent writes a notification for the booking to the outbox. It must run
+// inside the transaction that changed the booking, after the booking has been saved.
+func enqueueBookingEvent(tx *gorm.DB, eventType string, booking *models.Booking) error {
+	return enqueueEvent(tx, newBookingEvent(eventType, booking), booking)
+}
+
+// enqueueFlightStatusEvent writes a notification that the booking's flight changed
+// status, with its estimated departure, to the outbox
+func enqueueFlightStatusEvent(tx *gorm.DB, eventType string, booking *models.Booking, flight *models.Flight) error {
+	event := newBookingEvent(eventType, booking)
+	event.FlightStatus = flight.Status
+	event.EstimatedDepartureTime = flight.EstimatedDepartureTime
+	return enqueueEvent(tx, event, booking)
+}
+
+// newBookingEvent describes the booking as it is now
+func newBookingEvent(eventType string, booking *models.Booking) notification.Event {
+	return notification.Event{
+		Type:          eventType,
+		BookingID:     booking.ID,
+		RecordLocator: booking.RecordLocator,
+		FlightID:      booking.FlightID,
+		PassengerName: booking.PassengerName,
+		ContactEmail:  booking.ContactEmail,
+		BookingStatus: booking.BookingStatus,
+		OccurredAt:    time.Now(),
+	}
+}
+
+// enqueueEvent writes the event to the outbox, queues it for the partner webhooks and
+// marks the booking as not yet notified
+func enqueueEvent(tx *gorm.DB, event notification.Event, booking *models.Booking) error {
+	eventType := event.Type
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox event: %w", err)
+	}
+
+	outboxEvent := models.OutboxEvent{
+		EventType:     eventType,
+		BookingID:     booking.ID,
+		Payload:       string(payload),
+		OutboxStatus:  OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(&outboxEvent).Error; err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+
+	// Partners get the event with its ID but without the customer's contact email
+	event.ID = outboxEvent.ID
+	event.ContactEmail = ""
+	webhookPayload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	if err := enqueueWebhookDeliveries(tx, &outboxEvent, webhookPayload); err != nil {
+		return err
+	}
+
+	// The customer has not heard about this change yet
+	if err := tx.Model(&models.Booking{}).Where("id = ?", booking.ID).
+		Update("notification_sent", false).Error; err != nil {
+		return fmt.Errorf("failed to update booking notification flag: %w", err)
+	}
+	booking.NotificationSent = false
+	return nil
+}
+
+// OutboxDispatcher delivers pending outbox events to every sink. Only one dispatcher
+// should run against a database at a time.
+type OutboxDispatcher interface {
+	DispatchPending(now time.Time) (int, error)
+}
+
+type OutboxDispatcherImpl struct {
+	DB    *gorm.DB
+	Sinks []notification.Sink
+}
+
+func NewOutboxDispatcher(db *gorm.DB, sinks ...notification.Sink) OutboxDispatcher {
+	return &OutboxDispatcherImpl{
+		DB:    db,
+		Sinks: sinks,
+	}
+}
+
+// DispatchPending sends every event that is due. An event is delivered once all sinks
+// accept it; a sink that fails is retried later on its own, while the sinks that
+// accepted the event are not sent it again. A sink may still see an event ID twice
+// if the dispatcher stops between sending and recording. It returns the number of
+// events delivered.
+func (d *OutboxDispatcherImpl) DispatchPending(now time.Time) (int, error) {
+	var events []models.OutboxEvent
+	if err := d.DB.Where("outbox_status = ? AND next_attempt_at <= ?", OutboxStatusPending, now).
+		Order("id ASC").
+		Limit(outboxBatchSize).
+		Find(&events).Error; err != nil {
+		return 0, fmt.Errorf("failed to load outbox events: %w", err)
+	}
+
+	delivered := 0
+	for i := range events {
+		ok, err := d.deliver(&events[i], now)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliver sends one event to every sink that has not accepted it yet and records the outcome
+func (d *OutboxDispatcherImpl) deliver(outboxEvent *models.OutboxEvent, now time.Time) (bool, error) {
+	var event notification.Event
+	sendErr := json.Unmarshal([]byte(outboxEvent.Payload), &event)
+	// The outbox ID lets sinks drop duplicate deliveries
+	event.ID = outboxEvent.ID
+	if sendErr == nil {
+		delivered := deliveredSinks(outboxEvent.DeliveredSinks)
+		for _, sink := range d.Sinks {
+			if delivered[sink.Name()] {
+				continue
+			}
+			if err := sink.Send(context.Background(), event); err != nil {
+				sendErr = errors.Join(sendErr, fmt.Errorf("%s: %w", sink.Name(), err))
+				continue
+			}
+			delivered[sink.Name()] = true
+			outboxEvent.DeliveredSinks = joinSinks(outboxEvent.DeliveredSinks, sink.Name())
+		}
+	}
+
+	outboxEvent.Attempts++
+	if sendErr != nil {
+		outboxEvent.LastError = sendErr.Error()
+		if outboxEvent.Attempts >= outboxMaxAttempts {
+			outboxEvent.OutboxStatus = OutboxStatusFailed
+		} else {
+			outboxEvent.NextAttemptAt = now.Add(retryBackoff(outboxEvent.Attempts))
+		}
+		if err := d.DB.Save(outboxEvent).Error; err != nil {
+			return false, fmt.Errorf("failed to update outbox event: %w", err)
+		}
+		return false, nil
+	}
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		outboxEvent.OutboxStatus = OutboxStatusDelivered
+		outboxEvent.LastError = ""
+		outboxEvent.DeliveredAt = &now
+		if err := tx.Save(outboxEvent).Error; err != nil {
+			return fmt.Errorf("failed to update outbox event: %w", err)
+		}
+
+		// The flag is only set once nothing newer is waiting for this booking
+		var pending int64
+		if err := tx.Model(&models.OutboxEvent{}).
+			Where("booking_id = ? AND outbox_status = ?", outboxEvent.BookingID, OutboxStatusPending).
+			Count(&pending).Error; err != nil {
+			return fmt.Errorf("failed to count pending events: %w", err)
+		}
+		if pending == 0 {
+			if err := tx.Model(&models.Booking{}).Where("id = ?", outboxEvent.BookingID).
+				Update("notification_sent", true).Error; err != nil {
+				return fmt.Errorf("failed to update booking notification flag: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// deliveredSinks parses the comma-separated names of the sinks that accepted an event
+func deliveredSinks(names string) map[string]bool {
+	delivered := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		if name != "" {
+			delivered[name] = true
+		}
+	}
+	return delivered
+}
+
+// joinSinks adds a sink name to a comma-separated list
+func joinSinks(names, name string) string {
+	if names == "" {
+		return name
+	}
+	return names + "," + name
+}
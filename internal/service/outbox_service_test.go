@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySink fails the first failures sends and records every event it accepts
+type flakySink struct {
+	name     string
+	failures int
+	sent     []notification.Event
+}
+
+func (s *flakySink) Name() string { return s.name }
+
+func (s *flakySink) Send(ctx context.Context, event notification.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.sent = append(s.sent, event)
+	return nil
+}
+
+// TestOutbox_BookingLifecycle tests that booking changes are queued in the outbox and delivered with retries
+func TestOutbox_BookingLifecycle(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+	sink := &flakySink{name: "flaky", failures: 1}
+	dispatcher := NewOutboxDispatcher(db, sink)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 1
+	require.NoError(t, db.Create(&flight).Error)
+
+	first, err := svc.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	second, err := svc.CreateBooking(newTestBooking(flight.ID, "Lin Mei", 1))
+	require.NoError(t, err)
+	_, err = svc.CancelBooking(first.ID) // promotes the second booking
+	require.NoError(t, err)
+
+	var events []models.OutboxEvent
+	require.NoError(t, db.Order("id ASC").Find(&events).Error)
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.EventType)
+	}
+	assert.Equal(t, []string{
+		notification.EventBookingConfirmed,
+		notification.EventBookingWaitlisted,
+		notification.EventBookingCancelled,
+		notification.EventBookingPromoted,
+	}, types)
+
+	// When: the first attempt fails for the first event and is retried after the backoff
+	now := time.Now()
+	delivered, err := dispatcher.DispatchPending(now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+
+	var retried models.OutboxEvent
+	require.NoError(t, db.First(&retried, events[0].ID).Error)
+	assert.Equal(t, OutboxStatusPending, retried.OutboxStatus)
+	assert.Equal(t, 1, retried.Attempts)
+	assert.NotEmpty(t, retried.LastError)
+
+	delivered, err = dispatcher.DispatchPending(now.Add(retryBaseBackoff))
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, sink.sent, 4)
+
+	got, err := svc.GetBooking(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus)
+	assert.True(t, got.NotificationSent)
+}
+
+// TestOutbox_RetriesOnlyFailedSinks tests that a retry skips the sinks that already
+// accepted the event
+func TestOutbox_RetriesOnlyFailedSinks(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+	healthy := &flakySink{name: "healthy"}
+	flaky := &flakySink{name: "flaky", failures: 1}
+	dispatcher := NewOutboxDispatcher(db, flaky, healthy)
+
+	flight := newTestFlight()
+	require.NoError(t, db.Create(&flight).Error)
+	booking, err := svc.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+
+	// When
+	now := time.Now()
+	delivered, err := dispatcher.DispatchPending(now)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("booking_id = ?", booking.ID).First(&event).Error)
+	assert.Equal(t, OutboxStatusPending, event.OutboxStatus)
+	assert.Equal(t, "healthy", event.DeliveredSinks)
+
+	delivered, err = dispatcher.DispatchPending(now.Add(retryBaseBackoff))
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, healthy.sent, 1, "the healthy sink is not sent the event again")
+	assert.Len(t, flaky.sent, 1)
+
+	require.NoError(t, db.First(&event, event.ID).Error)
+	assert.Equal(t, OutboxStatusDelivered, event.OutboxStatus)
+	assert.Equal(t, 2, event.Attempts)
+}
+
+// TestRetryBackoff tests that the retry delay doubles up to the maximum
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, retryBaseBackoff, retryBackoff(1))
+	assert.Equal(t, 4*retryBaseBackoff, retryBackoff(3))
+	assert.Equal(t, retryMaxBackoff, retryBackoff(20))
+	assert.Equal(t, retryMaxBackoff, retryBackoff(100))
+}
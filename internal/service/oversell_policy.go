@@ -0,0 +1,107 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OversellPolicyFixed      = "Fixed"
+	OversellPolicyPercentage = "Percentage"
+	OversellPolicyNone       = "None"
+)
+
+// OversellPolicy decides how many seats beyond a flight's capacity may be sold.
+// Bookings past the capacity are waitlisted.
+type OversellPolicy interface {
+	Limit(capacity int) int
+}
+
+// FixedOversell allows a fixed number of extra seats
+type FixedOversell struct {
+	Seats int
+}
+
+// Limit implements OversellPolicy.Limit
+func (p FixedOversell) Limit(capacity int) int {
+	return p.Seats
+}
+
+// PercentageOversell allows a share of the capacity, rounded down
+type PercentageOversell struct {
+	Percent float64
+}
+
+// Limit implements OversellPolicy.Limit
+func (p PercentageOversell) Limit(capacity int) int {
+	return int(math.Floor(float64(capacity) * p.Percent / 100))
+}
+
+// NoOversell never sells more seats than the flight has
+type NoOversell struct{}
+
+// Limit implements OversellPolicy.Limit
+func (NoOversell) Limit(capacity int) int {
+	return 0
+}
+
+// DefaultOversellPolicy applies to flights that have no policy and match no rule
+var DefaultOversellPolicy OversellPolicy = FixedOversell{Seats: 10}
+
+// NewOversellPolicy builds a policy from its stored name and value
+func NewOversellPolicy(name string, value float64) (OversellPolicy, error) {
+	switch name {
+	case OversellPolicyFixed:
+		if value < 0 || value != math.Trunc(value) {
+			return nil, fmt.Errorf("a Fixed oversell value must be a whole number of seats")
+		}
+		return FixedOversell{Seats: int(value)}, nil
+	case OversellPolicyPercentage:
+		if value < 0 || value > 100 {
+			return nil, fmt.Errorf("a Percentage oversell value must be between 0 and 100")
+		}
+		return PercentageOversell{Percent: value}, nil
+	case OversellPolicyNone:
+		return NoOversell{}, nil
+	}
+	return nil, fmt.Errorf("oversell policy must be Fixed, Percentage or None")
+}
+
+// resolveOversellPolicy returns the flight's own policy, else the most specific
+// matching OversellRule, else fallback. An airline on a route beats the route alone,
+// which beats the airline alone; among equals the newest rule wins.
+func resolveOversellPolicy(tx *gorm.DB, flight *models.Flight, fallback OversellPolicy) (OversellPolicy, error) {
+	if flight.OversellPolicy != "" {
+		return NewOversellPolicy(flight.OversellPolicy, flight.OversellValue)
+	}
+
+	var rules []models.OversellRule
+	if err := tx.Where("airline IN ?", []string{"", flight.Airline}).
+		Where("(departure_airport = ? AND arrival_airport = ?) OR departure_airport = ''", flight.DepartureAirport, flight.ArrivalAirport).
+		Order("id DESC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load oversell rules: %w", err)
+	}
+
+	var best *models.OversellRule
+	bestScore := 0
+	for i := range rules {
+		score := 0
+		if rules[i].DepartureAirport != "" {
+			score += 2
+		}
+		if rules[i].Airline != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = &rules[i], score
+		}
+	}
+	if best == nil {
+		return fallback, nil
+	}
+	return NewOversellPolicy(best.Policy, best.Value)
+}
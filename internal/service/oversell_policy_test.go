@@ -0,0 +1,151 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOversellPolicy_Limit tests the extra seats each policy allows
+func TestOversellPolicy_Limit(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   string
+		value    float64
+		capacity int
+		want     int
+	}{
+		{"fixed", OversellPolicyFixed, 3, 100, 3},
+		{"percentage rounds down", OversellPolicyPercentage, 5, 150, 7},
+		{"percentage of no capacity", OversellPolicyPercentage, 10, 0, 0},
+		{"none", OversellPolicyNone, 0, 100, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := NewOversellPolicy(tc.policy, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, policy.Limit(tc.capacity))
+		})
+	}
+
+	for _, invalid := range []struct {
+		policy string
+		value  float64
+	}{{OversellPolicyFixed, -1}, {OversellPolicyFixed, 1.5}, {OversellPolicyPercentage, 101}, {"Unlimited", 0}} {
+		_, err := NewOversellPolicy(invalid.policy, invalid.value)
+		assert.Error(t, err, "%s %v", invalid.policy, invalid.value)
+	}
+}
+
+// TestResolveOversellPolicy tests that the flight's own policy beats rules and the most specific rule wins
+func TestResolveOversellPolicy(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	rules := NewOversellRuleService(db)
+	flight := newTestFlight()
+	fallback := FixedOversell{Seats: 10}
+
+	create := func(rule models.OversellRule) uint {
+		created, err := rules.CreateRule(&rule)
+		require.NoError(t, err)
+		return created.ID
+	}
+
+	// When / Then: nothing matches, so the fallback applies
+	create(models.OversellRule{Airline: "China Airlines", Policy: OversellPolicyNone})
+	create(models.OversellRule{DepartureAirport: "TPE", ArrivalAirport: "KIX", Policy: OversellPolicyNone})
+	policy, err := resolveOversellPolicy(db, &flight, fallback)
+	require.NoError(t, err)
+	assert.Equal(t, fallback, policy)
+
+	airline := create(models.OversellRule{Airline: flight.Airline, Policy: OversellPolicyFixed, Value: 1})
+	policy, err = resolveOversellPolicy(db, &flight, fallback)
+	require.NoError(t, err)
+	assert.Equal(t, FixedOversell{Seats: 1}, policy)
+
+	route := create(models.OversellRule{DepartureAirport: "TPE", ArrivalAirport: "NRT", Policy: OversellPolicyFixed, Value: 2})
+	policy, err = resolveOversellPolicy(db, &flight, fallback)
+	require.NoError(t, err)
+	assert.Equal(t, FixedOversell{Seats: 2}, policy, "a route rule beats an airline rule")
+
+	both := create(models.OversellRule{Airline: flight.Airline, DepartureAirport: "TPE", ArrivalAirport: "NRT", Policy: OversellPolicyPercentage, Value: 5})
+	policy, err = resolveOversellPolicy(db, &flight, fallback)
+	require.NoError(t, err)
+	assert.Equal(t, PercentageOversell{Percent: 5}, policy, "an airline on a route beats the route alone")
+
+	flight.OversellPolicy = OversellPolicyNone
+	policy, err = resolveOversellPolicy(db, &flight, fallback)
+	require.NoError(t, err)
+	assert.Equal(t, NoOversell{}, policy, "the flight's own policy beats every rule")
+
+	// Deleting rules falls back to the next match
+	flight.OversellPolicy = ""
+	require.NoError(t, rules.DeleteRule(both))
+	require.NoError(t, rules.DeleteRule(route))
+	policy, err = resolveOversellPolicy(db, &flight, fallback)
+	require.NoError(t, err)
+	assert.Equal(t, FixedOversell{Seats: 1}, policy)
+
+	require.NoError(t, rules.DeleteRule(airline))
+	assert.ErrorIs(t, rules.DeleteRule(airline), ErrNotFound)
+}
+
+// TestCreateBooking_PercentageOversell tests that a percentage policy is applied to the flight's capacity
+func TestCreateBooking_PercentageOversell(t *testing.T) {
+	// Given: 20 seats with 10% oversell allow 2 extra bookings
+	db := setupTestDB(t)
+	bookingService := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 20
+	flight.Capacity = 20
+	flight.OversellPolicy = OversellPolicyPercentage
+	flight.OversellValue = 10
+	require.NoError(t, db.Create(&flight).Error)
+
+	// When
+	_, err := bookingService.CreateBooking(newTestBooking(flight.ID, "A", 20))
+	require.NoError(t, err)
+	overbooked, err := bookingService.CreateBooking(newTestBooking(flight.ID, "B", 2))
+	require.NoError(t, err)
+	_, err = bookingService.CreateBooking(newTestBooking(flight.ID, "C", 1))
+
+	// Then
+	assert.Equal(t, BookingStatusWaitlisted, overbooked.BookingStatus)
+	assert.ErrorIs(t, err, ErrInsufficientSeats)
+}
+
+// TestCreateOversellRule_Invalid tests oversell rule validation
+func TestCreateOversellRule_Invalid(t *testing.T) {
+	db := setupTestDB(t)
+	rules := NewOversellRuleService(db)
+
+	cases := map[string]models.OversellRule{
+		"no scope":         {Policy: OversellPolicyNone},
+		"half a route":     {DepartureAirport: "TPE", Policy: OversellPolicyNone},
+		"bad airport":      {DepartureAirport: "tpe", ArrivalAirport: "NRT", Policy: OversellPolicyNone},
+		"unknown policy":   {Airline: "EVA Air", Policy: "Unlimited"},
+		"negative percent": {Airline: "EVA Air", Policy: OversellPolicyPercentage, Value: -5},
+		"unknown airport":  {DepartureAirport: "TPE", ArrivalAirport: "XXX", Policy: OversellPolicyNone},
+		"unknown airline":  {Airline: "Imaginary Air", Policy: OversellPolicyNone},
+	}
+	for name, rule := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := rules.CreateRule(&rule)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+// TestCreateOversellRule_AirlineCode tests that a rule's airline code is stored as the
+// airline's name, which is what flights are matched on
+func TestCreateOversellRule_AirlineCode(t *testing.T) {
+	db := setupTestDB(t)
+
+	rule, err := NewOversellRuleService(db).CreateRule(&models.OversellRule{Airline: "BR", Policy: OversellPolicyNone})
+
+	require.NoError(t, err)
+	assert.Equal(t, "EVA Air", rule.Airline)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type OversellRuleService interface {
+	CreateRule(rule *models.OversellRule) (*models.OversellRule, error)
+	ListRules() ([]models.OversellRule, error)
+	DeleteRule(id uint) error
+}
+
+type OversellRuleServiceImpl struct {
+	DB *gorm.DB
+}
+
+func NewOversellRuleService(db *gorm.DB) OversellRuleService {
+	return &OversellRuleServiceImpl{DB: db}
+}
+
+// CreateRule validates and stores an override rule. It applies to bookings made from
+// then on; seats already sold are not touched.
+func (s *OversellRuleServiceImpl) CreateRule(rule *models.OversellRule) (*models.OversellRule, error) {
+	if err := validateOversellRule(rule); err != nil {
+		return nil, err
+	}
+	if rule.DepartureAirport != "" {
+		if err := checkAirport(s.DB, "departure_airport", rule.DepartureAirport); err != nil {
+			return nil, err
+		}
+		if err := checkAirport(s.DB, "arrival_airport", rule.ArrivalAirport); err != nil {
+			return nil, err
+		}
+	}
+	// Rules match flights by airline name
+	if rule.Airline != "" {
+		airline, err := resolveAirline(s.DB, rule.Airline)
+		if err != nil {
+			return nil, err
+		}
+		rule.Airline = airline.Name
+	}
+
+	if err := s.DB.Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create oversell rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListRules returns every rule, oldest first
+func (s *OversellRuleServiceImpl) ListRules() ([]models.OversellRule, error) {
+	var rules []models.OversellRule
+	if err := s.DB.Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list oversell rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a rule; its flights fall back to the next matching rule
+func (s *OversellRuleServiceImpl) DeleteRule(id uint) error {
+	result := s.DB.Delete(&models.OversellRule{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete oversell rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return NewNotFoundError(CodeOversellRuleNotFound, "oversell rule not found")
+	}
+	return nil
+}
+
+// validateOversellRule checks that the rule names an airline, a route or both, and has a valid policy
+func validateOversellRule(rule *models.OversellRule) error {
+	if rule.Airline == "" && rule.DepartureAirport == "" && rule.ArrivalAirport == "" {
+		return NewValidationError(CodeInvalidOversellRule, "invalid oversell rule: an airline or a route is required")
+	}
+	if (rule.DepartureAirport == "") != (rule.ArrivalAirport == "") {
+		return NewValidationError(CodeInvalidOversellRule, "invalid oversell rule: a route needs both departure_airport and arrival_airport")
+	}
+	if rule.DepartureAirport != "" &&
+		(!airportCodePattern.MatchString(rule.DepartureAirport) || !airportCodePattern.MatchString(rule.ArrivalAirport)) {
+		return NewValidationError(CodeInvalidOversellRule, "invalid oversell rule: airports must be 3-letter IATA codes")
+	}
+	if _, err := NewOversellPolicy(rule.Policy, rule.Value); err != nil {
+		return NewValidationError(CodeInvalidOversellRule, "invalid oversell rule: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"time"
+)
+
+const (
+	PassengerTypeAdult  = "Adult"
+	PassengerTypeChild  = "Child"
+	PassengerTypeInfant = "Infant"
+)
+
+// passengerFareRates is the share of the seat's fare each passenger type pays
+var passengerFareRates = map[string]float64{
+	PassengerTypeAdult:  1.0,
+	PassengerTypeChild:  0.75,
+	PassengerTypeInfant: 0.1,
+}
+
+// priceBooking validates the booking's passengers against the flight and sets each
+// passenger's fare from the seat's fare and the booking's total price
+func priceBooking(booking *models.Booking, flight *models.Flight, fare float64) error {
+	if len(booking.Passengers) != booking.Quantity {
+		return NewValidationError(CodeInvalidPassengers, "%d passengers given for %d seats; one passenger is required per seat", len(booking.Passengers), booking.Quantity)
+	}
+
+	// Ages are counted on the local departure date
+	departure, err := time.Parse(FlightTimeLayout, flight.DepartureLocalTime)
+	if err != nil {
+		return NewValidationError(CodeInvalidFlight, "flight %d has an invalid departure time", flight.ID)
+	}
+
+	booking.TotalPrice = 0
+	for i := range booking.Passengers {
+		p := &booking.Passengers[i]
+		if err := validatePassenger(i, p, departure); err != nil {
+			return err
+		}
+
+		p.Fare = fare * passengerFareRates[p.PassengerType]
+		booking.TotalPrice += p.Fare
+	}
+	return nil
+}
+
+// validatePassenger checks the passenger's details and that the passenger type
+// matches their age on the departure date
+func validatePassenger(index int, p *models.Passenger, departure time.Time) error {
+	if p.Name == "" {
+		return NewValidationError(CodeInvalidPassengers, "passenger %d: name is required", index+1)
+	}
+	if p.DocumentNumber == "" {
+		return NewValidationError(CodeInvalidPassengers, "passenger %d: document_number is required", index+1)
+	}
+
+	birth, err := time.Parse("2006-01-02", p.DateOfBirth)
+	if err != nil {
+		return NewValidationError(CodeInvalidPassengers, "passenger %d: date_of_birth must be in YYYY-MM-DD format", index+1)
+	}
+	if birth.After(departure) {
+		return NewValidationError(CodeInvalidPassengers, "passenger %d: date_of_birth is after departure", index+1)
+	}
+
+	if _, ok := passengerFareRates[p.PassengerType]; !ok {
+		return NewValidationError(CodeInvalidPassengers, "passenger %d: passenger_type must be Adult, Child or Infant", index+1)
+	}
+	if expected := passengerTypeForAge(ageAt(birth, departure)); p.PassengerType != expected {
+		return NewValidationError(CodeInvalidPassengers, "passenger %d: passenger_type must be %s for their age at departure", index+1, expected)
+	}
+	return nil
+}
+
+// passengerTypeForAge uses the usual airline age bands: infants are under 2,
+// children are 2 to 11
+func passengerTypeForAge(age int) string {
+	switch {
+	case age < 2:
+		return PassengerTypeInfant
+	case age < 12:
+		return PassengerTypeChild
+	default:
+		return PassengerTypeAdult
+	}
+}
+
+// ageAt returns the age in whole years on the given day
+func ageAt(birth, day time.Time) int {
+	age := day.Year() - birth.Year()
+	if day.Month() < birth.Month() || (day.Month() == birth.Month() && day.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
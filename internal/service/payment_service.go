@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PaymentStatusPending = "PendingPayment"
+	PaymentStatusPaid    = "Paid"
+	PaymentStatusFailed  = "PaymentFailed"
+)
+
+// DefaultPaymentWindow is how long a new booking keeps its seats while unpaid
+const DefaultPaymentWindow = 30 * time.Minute
+
+type PaymentService interface {
+	PayBooking(id uint, paymentMethod string) (*models.Booking, error)
+	ReleaseUnpaidBookings(now time.Time) (int, error)
+}
+
+type PaymentServiceImpl struct {
+	DB       *gorm.DB
+	Gateway  payment.PaymentGateway
+	Waitlist WaitlistEngine
+}
+
+func NewPaymentService(db *gorm.DB, gateway payment.PaymentGateway) PaymentService {
+	return &PaymentServiceImpl{
+		DB:       db,
+		Gateway:  gateway,
+		Waitlist: NewWaitlistEngine(),
+	}
+}
+
+// awaitPayment marks a booking as unpaid. A confirmed booking gets a deadline after
+// which its seats are released; a waitlisted one has no seat to pay for yet, so its
+// clock starts when it is promoted.
+func awaitPayment(booking *models.Booking, window time.Duration) {
+	booking.PaymentStatus = PaymentStatusPending
+	booking.PaymentDeadline = nil
+	if booking.BookingStatus == BookingStatusConfirmed {
+		deadline := time.Now().Add(window)
+		booking.PaymentDeadline = &deadline
+	}
+}
+
+// PayBooking charges the booking's total price through the gateway. A declined payment
+// is recorded as PaymentFailed and may be retried with another method until the deadline.
+func (s *PaymentServiceImpl) PayBooking(id uint, paymentMethod string) (*models.Booking, error) {
+	if paymentMethod == "" {
+		return nil, NewValidationError(CodeInvalidRequest, "payment method is required")
+	}
+
+	var booking models.Booking
+	declined := false
+
+	// The booking stays locked while the gateway is called so it cannot be paid
+	// twice or released by the sweeper halfway through a payment
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&booking, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeBookingNotFound, "booking not found")
+			}
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+
+		if booking.BookingStatus == BookingStatusCancelled {
+			return NewConflictError(CodeBookingAlreadyCancelled, "booking already cancelled")
+		}
+		if booking.BookingStatus == BookingStatusDisrupted {
+			return NewConflictError(CodeFlightCancelled, "the booking's flight has been cancelled")
+		}
+		if booking.BookingStatus != BookingStatusConfirmed {
+			return NewConflictError(CodeBookingNotConfirmed, "booking is %s and cannot be paid until it is confirmed", booking.BookingStatus)
+		}
+		if booking.PaymentStatus == PaymentStatusPaid {
+			return NewConflictError(CodeBookingAlreadyPaid, "booking already paid")
+		}
+		if booking.PaymentDeadline != nil && !time.Now().Before(*booking.PaymentDeadline) {
+			return NewConflictError(CodePaymentDeadlinePassed, "payment deadline has passed")
+		}
+
+		charge, err := s.Gateway.Charge(context.Background(), payment.ChargeRequest{
+			IdempotencyKey: fmt.Sprintf("booking-%d", booking.ID),
+			Amount:         booking.TotalPrice,
+			PaymentMethod:  paymentMethod,
+			Description:    fmt.Sprintf("Booking %s", booking.RecordLocator),
+		})
+		switch {
+		case errors.Is(err, payment.ErrDeclined):
+			declined = true
+			booking.PaymentStatus = PaymentStatusFailed
+		case err != nil:
+			return fmt.Errorf("failed to charge booking: %w", err)
+		default:
+			paidAt := time.Now()
+			booking.PaymentStatus = PaymentStatusPaid
+			booking.PaymentReference = charge.ID
+			booking.PaidAt = &paidAt
+		}
+
+		if err := tx.Save(&booking).Error; err != nil {
+			return fmt.Errorf("failed to update payment status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if declined {
+		return nil, NewPaymentDeclinedError("payment declined")
+	}
+	return &booking, nil
+}
+
+// ReleaseUnpaidBookings cancels every booking whose payment deadline passed before now
+// and returns its seats to the flight. Waitlisted bookings keep their place in the queue.
+// It returns the number of bookings released.
+func (s *PaymentServiceImpl) ReleaseUnpaidBookings(now time.Time) (int, error) {
+	released := 0
+
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		released = 0
+		var bookings []models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("payment_status IN ? AND payment_deadline <= ? AND booking_status NOT IN ?",
+				[]string{PaymentStatusPending, PaymentStatusFailed}, now,
+				[]string{BookingStatusCancelled, BookingStatusWaitlisted}).
+			Order("flight_id ASC, id ASC").
+			Find(&bookings).Error; err != nil {
+			return fmt.Errorf("failed to load unpaid bookings: %w", err)
+		}
+
+		for i := range bookings {
+			if _, err := releaseBooking(tx, &bookings[i], s.Waitlist); err != nil {
+				return err
+			}
+			released++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return released, nil
+}
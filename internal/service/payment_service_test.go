@@ -0,0 +1,129 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPayBooking tests that a declined payment can be retried and a paid booking cannot be paid again
+func TestPayBooking(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	bookings := newTestBookingService(db)
+	payments := NewPaymentService(db, payment.NewFakeGateway())
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	booking, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusPending, booking.PaymentStatus)
+	require.NotNil(t, booking.PaymentDeadline)
+
+	// When
+	_, err = payments.PayBooking(booking.ID, payment.FakeDeclinedMethod)
+	assert.ErrorIs(t, err, ErrPaymentDeclined)
+
+	got, err := bookings.GetBooking(booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusFailed, got.PaymentStatus)
+
+	paid, err := payments.PayBooking(booking.ID, "tok_visa")
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, PaymentStatusPaid, paid.PaymentStatus)
+	assert.NotEmpty(t, paid.PaymentReference)
+	assert.NotNil(t, paid.PaidAt)
+
+	_, err = payments.PayBooking(booking.ID, "tok_visa")
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+// TestReleaseUnpaidBookings tests that unpaid bookings give their seats back after the deadline
+func TestReleaseUnpaidBookings(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	bookings := newTestBookingService(db)
+	payments := NewPaymentService(db, payment.NewFakeGateway())
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	unpaid, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	paid, err := bookings.CreateBooking(newTestBooking(flight.ID, "Lin Mei", 1))
+	require.NoError(t, err)
+	_, err = payments.PayBooking(paid.ID, "tok_visa")
+	require.NoError(t, err)
+
+	// When
+	released, err := payments.ReleaseUnpaidBookings(time.Now().Add(DefaultPaymentWindow + time.Second))
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 1, released)
+
+	got, err := bookings.GetBooking(unpaid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusCancelled, got.BookingStatus)
+
+	got, err = bookings.GetBooking(paid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus)
+
+	var f models.Flight
+	require.NoError(t, db.First(&f, flight.ID).Error)
+	assert.Equal(t, 1, f.AvailableSeats)
+}
+
+// TestPayBooking_Waitlisted tests that a waitlisted booking has nothing to pay for until it
+// is promoted, and that its payment clock starts then
+func TestPayBooking_Waitlisted(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	bookings := newTestBookingService(db)
+	payments := NewPaymentService(db, payment.NewFakeGateway())
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 1
+	require.NoError(t, db.Create(&flight).Error)
+
+	confirmed, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	waitlisted, err := bookings.CreateBooking(newTestBooking(flight.ID, "Lin Mei", 1))
+	require.NoError(t, err)
+	require.Equal(t, BookingStatusWaitlisted, waitlisted.BookingStatus)
+	assert.Nil(t, waitlisted.PaymentDeadline)
+
+	// When
+	_, err = payments.PayBooking(waitlisted.ID, "tok_visa")
+
+	// Then
+	assertCode(t, err, CodeBookingNotConfirmed)
+
+	released, err := payments.ReleaseUnpaidBookings(time.Now().Add(DefaultPaymentWindow + time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, released, "only the confirmed booking is released")
+
+	got, err := bookings.GetBooking(waitlisted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus, "the released seat promotes the waitlist")
+	assert.Equal(t, PaymentStatusPending, got.PaymentStatus)
+	require.NotNil(t, got.PaymentDeadline)
+	assert.True(t, got.PaymentDeadline.After(time.Now().Add(DefaultPaymentWindow-time.Minute)))
+
+	_, err = payments.PayBooking(waitlisted.ID, "tok_visa")
+	require.NoError(t, err)
+
+	got, err = bookings.GetBooking(confirmed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusCancelled, got.BookingStatus)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"flight-booking/internal/models"
+	"fmt"
+	"math/big"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// recordLocatorAlphabet leaves out 0, 1, I and O, which are easy to misread
+	recordLocatorAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	recordLocatorLength   = 6
+
+	// maxRecordLocatorAttempts bounds retries on the rare unique-index collision
+	maxRecordLocatorAttempts = 5
+)
+
+// GenerateRecordLocator returns a random 6-character alphanumeric record locator
+func GenerateRecordLocator() (string, error) {
+	max := big.NewInt(int64(len(recordLocatorAlphabet)))
+	locator := make([]byte, recordLocatorLength)
+	for i := range locator {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate record locator: %w", err)
+		}
+		locator[i] = recordLocatorAlphabet[n.Int64()]
+	}
+	return string(locator), nil
+}
+
+// createWithRecordLocator inserts the booking with a fresh record locator. The
+// unique index on record_locator is the source of truth, so concurrent bookings
+// that draw the same locator are detected by the database and retried. Each
+// attempt runs in a savepoint so a collision does not abort the outer transaction.
+func createWithRecordLocator(tx *gorm.DB, booking *models.Booking) error {
+	for attempt := 0; attempt < maxRecordLocatorAttempts; attempt++ {
+		locator, err := GenerateRecordLocator()
+		if err != nil {
+			return err
+		}
+		booking.RecordLocator = locator
+
+		err = tx.Transaction(func(sp *gorm.DB) error {
+			// Passengers are the only association a new booking brings; anything else
+			// on it, such as refunds, is never inserted with it
+			if err := sp.Omit(clause.Associations).Create(booking).Error; err != nil {
+				return err
+			}
+			if len(booking.Passengers) == 0 {
+				return nil
+			}
+			for i := range booking.Passengers {
+				booking.Passengers[i].BookingID = booking.ID
+			}
+			return sp.Omit(clause.Associations).Create(&booking.Passengers).Error
+		})
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+		booking.ID = 0
+	}
+	return fmt.Errorf("failed to allocate a unique record locator after %d attempts", maxRecordLocatorAttempts)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/refdata"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeEscaper drops the LIKE wildcards from autocomplete input, which no airport name contains
+var likeEscaper = strings.NewReplacer("%", "", "_", "")
+
+type ReferenceDataService interface {
+	SearchAirports(query string, limit int) ([]models.Airport, error)
+	ValidateAirport(code string) error
+	ResolveAirline(codeOrName string) (*models.Airline, error)
+}
+
+type ReferenceDataServiceImpl struct {
+	DB *gorm.DB
+}
+
+func NewReferenceDataService(db *gorm.DB) ReferenceDataService {
+	return &ReferenceDataServiceImpl{DB: db}
+}
+
+// SearchAirports returns up to limit airports for an autocomplete query. An exact code
+// match comes first, then codes, cities and names starting with the query, then names
+// containing it.
+func (s *ReferenceDataServiceImpl) SearchAirports(query string, limit int) ([]models.Airport, error) {
+	query = strings.ToLower(strings.TrimSpace(likeEscaper.Replace(query)))
+	if query == "" {
+		return nil, NewValidationError(CodeInvalidRequest, "q is required")
+	}
+	prefix, contains := query+"%", "%"+query+"%"
+
+	var airports []models.Airport
+	err := s.DB.
+		Where("LOWER(code) LIKE ? OR LOWER(city) LIKE ? OR LOWER(name) LIKE ?", prefix, prefix, contains).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: "CASE WHEN LOWER(code) = ? THEN 0 WHEN LOWER(code) LIKE ? THEN 1 WHEN LOWER(city) LIKE ? THEN 2 " +
+				"WHEN LOWER(name) LIKE ? THEN 3 ELSE 4 END, code",
+			Vars:               []interface{}{query, prefix, prefix, prefix},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&airports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search airports: %w", err)
+	}
+	return airports, nil
+}
+
+// ValidateAirport returns a validation error unless an airport has the IATA code
+func (s *ReferenceDataServiceImpl) ValidateAirport(code string) error {
+	return checkAirport(s.DB, "airport", code)
+}
+
+// ResolveAirline finds an airline by its IATA code, ICAO code or name
+func (s *ReferenceDataServiceImpl) ResolveAirline(codeOrName string) (*models.Airline, error) {
+	return resolveAirline(s.DB, codeOrName)
+}
+
+// checkAirport returns a validation error naming field unless an airport has the code
+func checkAirport(db *gorm.DB, field, code string) error {
+	var count int64
+	if err := db.Model(&models.Airport{}).Where("code = ?", code).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up airport: %w", err)
+	}
+	if count == 0 {
+		return NewValidationError(CodeUnknownAirport, "unknown %s %s", field, code)
+	}
+	return nil
+}
+
+// resolveAirline finds an airline by its IATA code, ICAO code or name; codes are
+// matched case-insensitively
+func resolveAirline(db *gorm.DB, codeOrName string) (*models.Airline, error) {
+	code := strings.ToUpper(codeOrName)
+
+	var airline models.Airline
+	err := db.Where("iata_code = ? OR icao_code = ? OR name = ?", code, code, codeOrName).First(&airline).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewValidationError(CodeUnknownAirline, "unknown airline %s", codeOrName)
+		}
+		return nil, fmt.Errorf("failed to look up airline: %w", err)
+	}
+	return &airline, nil
+}
+
+// LoadReferenceData upserts the bundled airports and airlines by code. Rows that are
+// no longer bundled are kept, since flights may still refer to them.
+func LoadReferenceData(db *gorm.DB) error {
+	airports, err := refdata.Airports()
+	if err != nil {
+		return err
+	}
+	airlines, err := refdata.Airlines()
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "city", "country", "time_zone", "latitude", "longitude", "updated_at"}),
+		}).CreateInBatches(airports, 200).Error; err != nil {
+			return fmt.Errorf("failed to load airports: %w", err)
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "iata_code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"icao_code", "name", "updated_at"}),
+		}).CreateInBatches(airlines, 200).Error; err != nil {
+			return fmt.Errorf("failed to load airlines: %w", err)
+		}
+		return nil
+	})
+}
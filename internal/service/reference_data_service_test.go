@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/refdata"
+	"flight-booking/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadReferenceData_Idempotent tests that loading twice keeps one row per code and
+// restores edited rows
+func TestLoadReferenceData_Idempotent(t *testing.T) {
+	// Given a database loaded once, with an airport edited since
+	db := setupTestDB(t)
+	require.NoError(t, db.Model(&models.Airport{}).Where("code = ?", "TPE").Update("name", "Old name").Error)
+
+	// When
+	require.NoError(t, LoadReferenceData(db))
+
+	// Then
+	airports, err := refdata.Airports()
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Model(&models.Airport{}).Count(&count).Error)
+	assert.Equal(t, int64(len(airports)), count)
+
+	var tpe models.Airport
+	require.NoError(t, db.Where("code = ?", "TPE").First(&tpe).Error)
+	assert.Equal(t, "Taiwan Taoyuan International Airport", tpe.Name)
+	assert.Equal(t, "Asia/Taipei", tpe.TimeZone)
+}
+
+// TestSearchAirports_Ranking tests that code matches rank above city and name matches
+func TestSearchAirports_Ranking(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewReferenceDataService(db)
+
+	cases := map[string][]string{
+		"nrt":    {"NRT"},
+		"tok":    {"HND", "NRT"}, // by city
+		"osaka":  {"ITM", "KIX"}, // by city, then code
+		"changi": {"SIN"},        // inside the name
+		"tp":     {"TPE"},        // code prefix
+		"100%":   {},             // wildcards are ignored
+	}
+	for query, want := range cases {
+		airports, err := svc.SearchAirports(query, 10)
+		require.NoError(t, err, query)
+		codes := []string{}
+		for _, a := range airports {
+			codes = append(codes, a.Code)
+		}
+		assert.Equal(t, want, codes, query)
+	}
+
+	airports, err := svc.SearchAirports("a", 3)
+	require.NoError(t, err)
+	assert.Len(t, airports, 3)
+
+	_, err = svc.SearchAirports("  ", 10)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+// TestResolveAirline tests looking airlines up by IATA code, ICAO code or name
+func TestResolveAirline(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewReferenceDataService(db)
+
+	for _, value := range []string{"BR", "br", "EVA", "EVA Air"} {
+		airline, err := svc.ResolveAirline(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, "EVA Air", airline.Name, value)
+	}
+
+	_, err := svc.ResolveAirline("ZZ")
+	var domainErr *Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, CodeUnknownAirline, domainErr.Code)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+// TestCreateFlight_ReferenceData tests that flights need known airports and airlines,
+// and are stored with the airline's name
+func TestCreateFlight_ReferenceData(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewFlightService(repository.NewGORMFlightRepository(db), db)
+
+	// When the airline is given by code
+	flight := newTestFlight()
+	flight.Airline = "BR"
+	created, err := svc.CreateFlight(&flight)
+
+	// Then it is stored by name
+	require.NoError(t, err)
+	assert.Equal(t, "EVA Air", created.Airline)
+
+	// When an airport or airline is unknown
+	for name, mutate := range map[string]func(f *models.Flight){
+		CodeUnknownAirport: func(f *models.Flight) { f.ArrivalAirport = "XXX" },
+		CodeUnknownAirline: func(f *models.Flight) { f.Airline = "Imaginary Air" },
+	} {
+		flight := newTestFlight()
+		mutate(&flight)
+		_, err := svc.CreateFlight(&flight)
+
+		// Then
+		var domainErr *Error
+		require.True(t, errors.As(err, &domainErr), name)
+		assert.Equal(t, name, domainErr.Code)
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RefundRuleFlexible      = "Flexible"
+	RefundRuleStandard      = "Standard"
+	RefundRuleNonRefundable = "NonRefundable"
+)
+
+const (
+	RefundStatusPending       = "Pending"
+	RefundStatusSucceeded     = "Succeeded"
+	RefundStatusFailed        = "Failed"
+	RefundStatusDeadLetter    = "DeadLetter"
+	RefundStatusNotRefundable = "NotRefundable"
+)
+
+// A failed refund is retried until it has made refundMaxAttempts attempts and then
+// parked as DeadLetter for manual handling; see retryBackoff for the delay
+const (
+	refundMaxAttempts = 8
+	refundBatchSize   = 100
+)
+
+// RefundTier applies when a booking is cancelled at least MinNotice before departure.
+// The refund is Rate of the paid amount minus Fee.
+type RefundTier struct {
+	MinNotice time.Duration
+	Rate      float64
+	Fee       float64
+}
+
+// refundRules lists each rule's tiers from the longest notice to the shortest.
+// Cancelling with less notice than the last tier refunds nothing.
+var refundRules = map[string][]RefundTier{
+	RefundRuleFlexible: {
+		{MinNotice: 0, Rate: 1.0},
+	},
+	RefundRuleStandard: {
+		{MinNotice: 7 * 24 * time.Hour, Rate: 1.0},
+		{MinNotice: 72 * time.Hour, Rate: 1.0, Fee: 50},
+		{MinNotice: 24 * time.Hour, Rate: 0.5, Fee: 50},
+	},
+	RefundRuleNonRefundable: {},
+}
+
+// calculateRefund returns the refund and the fee kept for cancelling a booking that
+// paid the given amount, with notice left before departure
+func calculateRefund(rule string, paid float64, notice time.Duration) (amount, fee float64) {
+	for _, tier := range refundRules[rule] {
+		if notice < tier.MinNotice {
+			continue
+		}
+		amount = math.Max(0, math.Round((paid*tier.Rate-tier.Fee)*100)/100)
+		return amount, paid - amount
+	}
+	return 0, paid
+}
+
+type RefundService interface {
+	// CreateRefund records the refund owed for a paid booking being cancelled. It must
+	// run inside the cancelling transaction; the refund is dispatched after commit.
+	CreateRefund(tx *gorm.DB, booking *models.Booking, flight *models.Flight) (*models.Refund, error)
+	DispatchRefund(id uint) (*models.Refund, error)
+	DispatchPendingRefunds(now time.Time) (int, error)
+}
+
+type RefundServiceImpl struct {
+	DB      *gorm.DB
+	Gateway payment.PaymentGateway
+}
+
+func NewRefundService(db *gorm.DB, gateway payment.PaymentGateway) RefundService {
+	return &RefundServiceImpl{
+		DB:      db,
+		Gateway: gateway,
+	}
+}
+
+// CreateRefund implements RefundService.CreateRefund
+func (s *RefundServiceImpl) CreateRefund(tx *gorm.DB, booking *models.Booking, flight *models.Flight) (*models.Refund, error) {
+	rule := flight.RefundRule
+	if rule == "" {
+		rule = RefundRuleStandard
+	}
+
+	amount, fee := calculateRefund(rule, booking.TotalPrice, flight.DepartureTime.Sub(time.Now().UTC()))
+	// The rule only covers the passenger changing plans; a flight the airline cancelled
+	// is refunded in full
+	if flight.Status == FlightStatusCancelled {
+		amount, fee = booking.TotalPrice, 0
+	}
+
+	refund := models.Refund{
+		BookingID:     booking.ID,
+		RefundRule:    rule,
+		Amount:        amount,
+		Fee:           fee,
+		RefundStatus:  RefundStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if amount == 0 {
+		refund.RefundStatus = RefundStatusNotRefundable
+	}
+
+	if err := tx.Create(&refund).Error; err != nil {
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+	return &refund, nil
+}
+
+// DispatchRefund sends a pending or failed refund to the payment gateway and records the outcome
+func (s *RefundServiceImpl) DispatchRefund(id uint) (*models.Refund, error) {
+	return s.dispatchRefund(id, time.Now())
+}
+
+// dispatchRefund sends the refund to the gateway. A failed attempt is retried after
+// retryBackoff, or parked once the refund has used up its attempts.
+func (s *RefundServiceImpl) dispatchRefund(id uint, now time.Time) (*models.Refund, error) {
+	var refund models.Refund
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the refund so it is not sent twice by the sweeper and a cancellation at once
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&refund, id).Error; err != nil {
+			return fmt.Errorf("failed to lock refund: %w", err)
+		}
+		if refund.RefundStatus != RefundStatusPending && refund.RefundStatus != RefundStatusFailed {
+			return nil
+		}
+
+		var booking models.Booking
+		if err := tx.Unscoped().First(&booking, refund.BookingID).Error; err != nil {
+			return fmt.Errorf("failed to load booking for refund: %w", err)
+		}
+
+		result, err := s.Gateway.Refund(context.Background(), payment.RefundRequest{
+			IdempotencyKey: fmt.Sprintf("refund-%d", refund.ID),
+			ChargeID:       booking.PaymentReference,
+			Amount:         refund.Amount,
+		})
+
+		refund.Attempts++
+		if err != nil {
+			// Failed refunds are retried by the refund sweeper
+			refund.LastError = err.Error()
+			if refund.Attempts >= refundMaxAttempts {
+				refund.RefundStatus = RefundStatusDeadLetter
+			} else {
+				refund.RefundStatus = RefundStatusFailed
+				refund.NextAttemptAt = now.Add(retryBackoff(refund.Attempts))
+			}
+		} else {
+			refund.RefundStatus = RefundStatusSucceeded
+			refund.GatewayReference = result.ID
+			refund.LastError = ""
+		}
+
+		if err := tx.Save(&refund).Error; err != nil {
+			return fmt.Errorf("failed to update refund: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// DispatchPendingRefunds sends every refund that has not reached the gateway yet and
+// is due. A refund that cannot be dispatched is logged and left for the next run, so it
+// does not hold up the others. It returns the number of refunds that succeeded.
+func (s *RefundServiceImpl) DispatchPendingRefunds(now time.Time) (int, error) {
+	var ids []uint
+	if err := s.DB.Model(&models.Refund{}).
+		Where("refund_status IN ? AND next_attempt_at <= ?", []string{RefundStatusPending, RefundStatusFailed}, now).
+		Order("id ASC").
+		Limit(refundBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to load pending refunds: %w", err)
+	}
+
+	succeeded := 0
+	for _, id := range ids {
+		refund, err := s.dispatchRefund(id, now)
+		if err != nil {
+			log.Printf("refund %d: %v", id, err)
+			continue
+		}
+		if refund.RefundStatus == RefundStatusSucceeded {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
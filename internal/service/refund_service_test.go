@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"flight-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRefundGateway charges through the fake gateway but fails the first failures
+// refunds of each charge
+type flakyRefundGateway struct {
+	*payment.FakeGateway
+	failures map[string]int
+}
+
+func (g *flakyRefundGateway) Refund(ctx context.Context, req payment.RefundRequest) (*payment.RefundResult, error) {
+	if g.failures[req.ChargeID] > 0 {
+		g.failures[req.ChargeID]--
+		return nil, errors.New("gateway unavailable")
+	}
+	return g.FakeGateway.Refund(ctx, req)
+}
+
+// TestCalculateRefund tests the refund tiers of each refund rule
+func TestCalculateRefund(t *testing.T) {
+	cases := []struct {
+		name       string
+		rule       string
+		notice     time.Duration
+		wantAmount float64
+		wantFee    float64
+	}{
+		{"flexible just before departure", RefundRuleFlexible, time.Minute, 400, 0},
+		{"flexible after departure", RefundRuleFlexible, -time.Minute, 0, 400},
+		{"standard a week ahead", RefundRuleStandard, 8 * 24 * time.Hour, 400, 0},
+		{"standard three days ahead", RefundRuleStandard, 80 * time.Hour, 350, 50},
+		{"standard one day ahead", RefundRuleStandard, 30 * time.Hour, 150, 250},
+		{"standard same day", RefundRuleStandard, 2 * time.Hour, 0, 400},
+		{"non-refundable", RefundRuleNonRefundable, 30 * 24 * time.Hour, 0, 400},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			amount, fee := calculateRefund(tc.rule, 400, tc.notice)
+			assert.Equal(t, tc.wantAmount, amount)
+			assert.Equal(t, tc.wantFee, fee)
+		})
+	}
+}
+
+// TestCancelBooking_RefundsPaidBooking tests that cancelling a paid booking refunds it through the gateway
+func TestCancelBooking_RefundsPaidBooking(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	gateway := payment.NewFakeGateway()
+	bookings := NewBookingService(repository.NewGORMBookingRepository(db), db, FixedOversell{Seats: 10}, NewRefundService(db, gateway))
+	payments := NewPaymentService(db, gateway)
+
+	flight := newTestFlight()
+	require.NoError(t, SetFlightTimes(&flight, time.Now().AddDate(0, 0, 30), time.Now().AddDate(0, 0, 31)))
+	flight.RefundRule = RefundRuleFlexible
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	paid, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+	_, err = payments.PayBooking(paid.ID, "tok_visa")
+	require.NoError(t, err)
+
+	unpaid, err := bookings.CreateBooking(newTestBooking(flight.ID, "Lin Mei", 1))
+	require.NoError(t, err)
+
+	// When
+	cancelled, err := bookings.CancelBooking(paid.ID)
+	require.NoError(t, err)
+
+	// Then
+	require.Len(t, cancelled.Refunds, 1)
+	assert.Equal(t, RefundStatusSucceeded, cancelled.Refunds[0].RefundStatus)
+	assert.Equal(t, paid.TotalPrice, cancelled.Refunds[0].Amount)
+	assert.NotEmpty(t, cancelled.Refunds[0].GatewayReference)
+
+	cancelled, err = bookings.CancelBooking(unpaid.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cancelled.Refunds)
+}
+
+// TestDispatchPendingRefunds tests that failed refunds are retried with backoff, one
+// failing refund does not hold up the others, and a refund that keeps failing is parked
+func TestDispatchPendingRefunds(t *testing.T) {
+	// Given two cancelled bookings whose refunds fail: one once, the other every time
+	db := setupTestDB(t)
+	gateway := &flakyRefundGateway{FakeGateway: payment.NewFakeGateway(), failures: map[string]int{}}
+	refunds := NewRefundService(db, gateway)
+	bookings := NewBookingService(repository.NewGORMBookingRepository(db), db, FixedOversell{Seats: 10}, refunds)
+	payments := NewPaymentService(db, gateway)
+
+	flight := newTestFlight()
+	require.NoError(t, SetFlightTimes(&flight, time.Now().AddDate(0, 0, 30), time.Now().AddDate(0, 0, 31)))
+	flight.RefundRule = RefundRuleFlexible
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	var cancelled []*models.Booking
+	for i, failures := range []int{refundMaxAttempts, 1} {
+		booking, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+		require.NoError(t, err)
+		paid, err := payments.PayBooking(booking.ID, "tok_visa")
+		require.NoError(t, err)
+		gateway.failures[paid.PaymentReference] = failures
+
+		booking, err = bookings.CancelBooking(booking.ID)
+		require.NoError(t, err)
+		require.Len(t, booking.Refunds, 1, "booking %d", i)
+		assert.Equal(t, RefundStatusFailed, booking.Refunds[0].RefundStatus)
+		assert.Equal(t, 1, booking.Refunds[0].Attempts)
+		assert.NotEmpty(t, booking.Refunds[0].LastError)
+		cancelled = append(cancelled, booking)
+	}
+	failing, flaky := cancelled[0].Refunds[0], cancelled[1].Refunds[0]
+
+	// When the sweeper runs before the backoff has passed
+	now := time.Now()
+	succeeded, err := refunds.DispatchPendingRefunds(now)
+
+	// Then nothing is sent
+	require.NoError(t, err)
+	assert.Zero(t, succeeded)
+
+	// When it runs after the backoff
+	succeeded, err = refunds.DispatchPendingRefunds(now.Add(retryBaseBackoff + time.Second))
+
+	// Then the refund behind the failing one succeeds
+	require.NoError(t, err)
+	assert.Equal(t, 1, succeeded)
+
+	var got models.Refund
+	require.NoError(t, db.First(&got, flaky.ID).Error)
+	assert.Equal(t, RefundStatusSucceeded, got.RefundStatus)
+	assert.Empty(t, got.LastError)
+	got = models.Refund{}
+	require.NoError(t, db.First(&got, failing.ID).Error)
+	assert.Equal(t, RefundStatusFailed, got.RefundStatus)
+	assert.Equal(t, 2, got.Attempts)
+
+	// When the failing refund uses up its attempts
+	for i := 0; i < refundMaxAttempts; i++ {
+		now = now.Add(retryMaxBackoff)
+		_, err = refunds.DispatchPendingRefunds(now)
+		require.NoError(t, err)
+	}
+
+	// Then it is parked and no longer sent
+	got = models.Refund{}
+	require.NoError(t, db.First(&got, failing.ID).Error)
+	assert.Equal(t, RefundStatusDeadLetter, got.RefundStatus)
+	assert.Equal(t, refundMaxAttempts, got.Attempts)
+	assert.Equal(t, 0, gateway.failures[cancelled[0].PaymentReference])
+}
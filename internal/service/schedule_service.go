@@ -0,0 +1,447 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultScheduleHorizonDays is how many days ahead flights are generated from schedules
+const DefaultScheduleHorizonDays = 90
+
+// scheduleDateLayout is the layout of a schedule's effective dates and of Flight.ScheduleDate
+const scheduleDateLayout = "2006-01-02"
+
+// ScheduleSync reports what a schedule change did to the schedule's future flights
+type ScheduleSync struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+	// KeptFlightIDs are flights that no longer match the schedule but have bookings.
+	// They are left as they were for an admin to re-accommodate the passengers.
+	KeptFlightIDs []uint `json:"kept_flight_ids"`
+}
+
+type ScheduleService interface {
+	CreateSchedule(schedule *models.Schedule) (*models.Schedule, *ScheduleSync, error)
+	ListSchedules() ([]models.Schedule, error)
+	UpdateSchedule(id uint, schedule *models.Schedule) (*models.Schedule, *ScheduleSync, error)
+	DeleteSchedule(id uint) (*ScheduleSync, error)
+	GenerateFlights(now time.Time) (int, error)
+}
+
+type ScheduleServiceImpl struct {
+	DB          *gorm.DB
+	HorizonDays int
+}
+
+func NewScheduleService(db *gorm.DB, horizonDays int) ScheduleService {
+	return &ScheduleServiceImpl{
+		DB:          db,
+		HorizonDays: horizonDays,
+	}
+}
+
+// CreateSchedule stores the schedule and generates its flights up to the horizon
+func (s *ScheduleServiceImpl) CreateSchedule(schedule *models.Schedule) (*models.Schedule, *ScheduleSync, error) {
+	if schedule.RefundRule == "" {
+		schedule.RefundRule = RefundRuleStandard
+	}
+	if err := validateSchedule(s.DB, schedule); err != nil {
+		return nil, nil, err
+	}
+
+	sync := &ScheduleSync{KeptFlightIDs: []uint{}}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(schedule).Error; err != nil {
+			return fmt.Errorf("failed to create schedule: %w", err)
+		}
+		created, err := generateScheduleFlights(tx, schedule, time.Now(), s.HorizonDays)
+		if err != nil {
+			return err
+		}
+		sync.Created = created
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedule, sync, nil
+}
+
+// ListSchedules returns every schedule, oldest first
+func (s *ScheduleServiceImpl) ListSchedules() ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	if err := s.DB.Order("id ASC").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule replaces the schedule and applies the change to its future flights.
+// Flights without bookings follow the schedule or are removed from dates it no longer
+// operates on; flights with bookings are kept unchanged and reported.
+func (s *ScheduleServiceImpl) UpdateSchedule(id uint, schedule *models.Schedule) (*models.Schedule, *ScheduleSync, error) {
+	if schedule.RefundRule == "" {
+		schedule.RefundRule = RefundRuleStandard
+	}
+	if err := validateSchedule(s.DB, schedule); err != nil {
+		return nil, nil, err
+	}
+
+	var sync *ScheduleSync
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		sync = &ScheduleSync{KeptFlightIDs: []uint{}}
+		existing, err := lockSchedule(tx, id)
+		if err != nil {
+			return err
+		}
+		schedule.Model = existing.Model
+		if err := tx.Save(schedule).Error; err != nil {
+			return fmt.Errorf("failed to update schedule: %w", err)
+		}
+
+		now := time.Now()
+		if err := reconcileScheduleFlights(tx, schedule, now, sync); err != nil {
+			return err
+		}
+		sync.Created, err = generateScheduleFlights(tx, schedule, now, s.HorizonDays)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedule, sync, nil
+}
+
+// DeleteSchedule soft deletes the schedule and removes its future flights that have no
+// bookings; flights with bookings keep operating and are reported
+func (s *ScheduleServiceImpl) DeleteSchedule(id uint) (*ScheduleSync, error) {
+	var sync *ScheduleSync
+	err := inventoryTransaction(s.DB, func(tx *gorm.DB) error {
+		sync = &ScheduleSync{KeptFlightIDs: []uint{}}
+		schedule, err := lockSchedule(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(schedule).Error; err != nil {
+			return fmt.Errorf("failed to delete schedule: %w", err)
+		}
+
+		// A deleted schedule operates on no date
+		now := time.Now()
+		schedule.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		return reconcileScheduleFlights(tx, schedule, now, sync)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sync, nil
+}
+
+// GenerateFlights creates the missing flights of every schedule up to the horizon and
+// returns how many it created. Dates that already have a flight, including one an
+// admin deleted, are skipped, so running it again creates nothing new.
+func (s *ScheduleServiceImpl) GenerateFlights(now time.Time) (int, error) {
+	var schedules []models.Schedule
+	// A day of slack covers airports whose local date is behind UTC
+	if err := s.DB.
+		Where("effective_to = '' OR effective_to >= ?", now.UTC().AddDate(0, 0, -1).Format(scheduleDateLayout)).
+		Find(&schedules).Error; err != nil {
+		return 0, fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	total := 0
+	for i := range schedules {
+		created, err := generateScheduleFlights(s.DB, &schedules[i], now, s.HorizonDays)
+		total += created
+		if err != nil {
+			return total, fmt.Errorf("schedule %d: %w", schedules[i].ID, err)
+		}
+	}
+	return total, nil
+}
+
+// lockSchedule loads the schedule for update
+func lockSchedule(tx *gorm.DB, id uint) (*models.Schedule, error) {
+	var schedule models.Schedule
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError(CodeScheduleNotFound, "schedule not found")
+		}
+		return nil, fmt.Errorf("failed to lock schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// generateScheduleFlights creates a flight for every operating date from today at the
+// departure airport up to horizonDays ahead that does not have one yet. Flights that
+// would already have departed are not created.
+func generateScheduleFlights(tx *gorm.DB, schedule *models.Schedule, now time.Time, horizonDays int) (int, error) {
+	departureZone, _, err := flightTimeZones(&models.Flight{
+		DepartureAirport: schedule.DepartureAirport,
+		ArrivalAirport:   schedule.ArrivalAirport,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Dates are handled as midnight UTC so that adding days never meets a DST change
+	today, _ := time.Parse(scheduleDateLayout, now.In(departureZone).Format(scheduleDateLayout))
+	from, _ := time.Parse(scheduleDateLayout, schedule.EffectiveFrom)
+	if from.Before(today) {
+		from = today
+	}
+	to := today.AddDate(0, 0, horizonDays)
+	if schedule.EffectiveTo != "" {
+		if end, _ := time.Parse(scheduleDateLayout, schedule.EffectiveTo); end.Before(to) {
+			to = end
+		}
+	}
+	if to.Before(from) {
+		return 0, nil
+	}
+
+	var existing []string
+	if err := tx.Unscoped().Model(&models.Flight{}).
+		Where("schedule_id = ? AND schedule_date >= ? AND schedule_date <= ?",
+			schedule.ID, from.Format(scheduleDateLayout), to.Format(scheduleDateLayout)).
+		Pluck("schedule_date", &existing).Error; err != nil {
+		return 0, fmt.Errorf("failed to load scheduled flights: %w", err)
+	}
+
+	var flights []models.Flight
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if !scheduleOperatesOn(schedule, date) || slices.Contains(existing, date.Format(scheduleDateLayout)) {
+			continue
+		}
+		var flight models.Flight
+		if err := applySchedule(&flight, schedule, date); err != nil {
+			return 0, err
+		}
+		if flight.DepartureTime.After(now) {
+			flights = append(flights, flight)
+		}
+	}
+	if len(flights) == 0 {
+		return 0, nil
+	}
+
+	// Another generator may have created some of the dates in the meantime
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&flights, 100)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to create scheduled flights: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
+
+// reconcileScheduleFlights brings the schedule's flights that have not departed in line
+// with it. A flight that customers hold seats on or wait for is never changed.
+func reconcileScheduleFlights(tx *gorm.DB, schedule *models.Schedule, now time.Time, sync *ScheduleSync) error {
+	var flights []models.Flight
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("schedule_id = ? AND departure_time > ?", schedule.ID, now.UTC()).
+		Order("schedule_date ASC").
+		Find(&flights).Error; err != nil {
+		return fmt.Errorf("failed to lock scheduled flights: %w", err)
+	}
+
+	for i := range flights {
+		flight := &flights[i]
+		date, err := time.Parse(scheduleDateLayout, flight.ScheduleDate)
+		if err != nil {
+			return fmt.Errorf("flight %d has an invalid schedule_date %q", flight.ID, flight.ScheduleDate)
+		}
+
+		operates := scheduleOperatesOn(schedule, date)
+		updated := *flight
+		if operates {
+			if err := applySchedule(&updated, schedule, date); err != nil {
+				return err
+			}
+			if sameScheduledFlight(flight, &updated) {
+				continue
+			}
+		}
+
+		inUse, err := flightInUse(tx, flight)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			sync.KeptFlightIDs = append(sync.KeptFlightIDs, flight.ID)
+			continue
+		}
+
+		if !operates {
+			// Unlinking the flight lets the date be generated again if the schedule
+			// operates on it later; a flight an admin deletes stays linked and is not
+			// regenerated
+			updated.ScheduleID = nil
+			updated.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			sync.Removed++
+		} else {
+			sync.Updated++
+		}
+		if err := saveFlight(tx, &updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applySchedule sets the fields a schedule owns on its flight for the date. The flight
+// gets the full aircraft, so it must not have seats sold or held.
+func applySchedule(flight *models.Flight, schedule *models.Schedule, date time.Time) error {
+	scheduleID := schedule.ID
+	flight.ScheduleID = &scheduleID
+	flight.ScheduleDate = date.Format(scheduleDateLayout)
+	flight.FlightNumber = schedule.FlightNumber
+	flight.Airline = schedule.Airline
+	flight.DepartureAirport = schedule.DepartureAirport
+	flight.ArrivalAirport = schedule.ArrivalAirport
+	flight.Price = schedule.Price
+	flight.Capacity = schedule.Capacity
+	flight.AvailableSeats = schedule.Capacity
+	flight.RefundRule = schedule.RefundRule
+
+	departureZone, _, err := flightTimeZones(flight)
+	if err != nil {
+		return err
+	}
+	departure, err := time.ParseInLocation(FlightTimeLayout, flight.ScheduleDate+" "+schedule.DepartureTime, departureZone)
+	if err != nil {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: departure_time must be in HH:MM format")
+	}
+	return SetFlightTimes(flight, departure, departure.Add(time.Duration(schedule.DurationMinutes)*time.Minute))
+}
+
+// sameScheduledFlight reports whether two flights agree on every field a schedule owns
+// except the seats left, which bookings change
+func sameScheduledFlight(a, b *models.Flight) bool {
+	return a.FlightNumber == b.FlightNumber &&
+		a.Airline == b.Airline &&
+		a.DepartureAirport == b.DepartureAirport &&
+		a.ArrivalAirport == b.ArrivalAirport &&
+		a.DepartureTime.Equal(b.DepartureTime) &&
+		a.ArrivalTime.Equal(b.ArrivalTime) &&
+		a.Price == b.Price &&
+		a.Capacity == b.Capacity &&
+		a.RefundRule == b.RefundRule
+}
+
+// flightInUse reports whether seats on the flight are sold or held, or a booking is
+// waiting for one
+func flightInUse(tx *gorm.DB, flight *models.Flight) (bool, error) {
+	if flight.AvailableSeats != flight.Capacity {
+		return true, nil
+	}
+	var bookings int64
+	if err := tx.Model(&models.Booking{}).
+		Where("flight_id = ? AND booking_status <> ?", flight.ID, BookingStatusCancelled).
+		Count(&bookings).Error; err != nil {
+		return false, fmt.Errorf("failed to count bookings: %w", err)
+	}
+	return bookings > 0, nil
+}
+
+// scheduleOperatesOn reports whether the schedule has a flight on the date
+func scheduleOperatesOn(schedule *models.Schedule, date time.Time) bool {
+	if schedule.DeletedAt.Valid {
+		return false
+	}
+	day := date.Format(scheduleDateLayout)
+	if day < schedule.EffectiveFrom || (schedule.EffectiveTo != "" && day > schedule.EffectiveTo) {
+		return false
+	}
+	// ISO weekdays start on Monday; time.Weekday starts on Sunday
+	weekday := int(date.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return slices.Contains(splitList(schedule.DaysOfWeek), strconv.Itoa(weekday))
+}
+
+// validateSchedule checks the schedule, stores its airline by name and its days of
+// week in order
+func validateSchedule(db *gorm.DB, schedule *models.Schedule) error {
+	if schedule.FlightNumber == "" {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: flight_number is required")
+	}
+	if schedule.Airline == "" {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: airline is required")
+	}
+	if !airportCodePattern.MatchString(schedule.DepartureAirport) || !airportCodePattern.MatchString(schedule.ArrivalAirport) {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: airports must be 3-letter IATA codes")
+	}
+	if schedule.DepartureAirport == schedule.ArrivalAirport {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: departure_airport and arrival_airport must differ")
+	}
+	if err := checkAirport(db, "departure_airport", schedule.DepartureAirport); err != nil {
+		return err
+	}
+	if err := checkAirport(db, "arrival_airport", schedule.ArrivalAirport); err != nil {
+		return err
+	}
+	airline, err := resolveAirline(db, schedule.Airline)
+	if err != nil {
+		return err
+	}
+	schedule.Airline = airline.Name
+
+	var days []int
+	for _, field := range splitList(schedule.DaysOfWeek) {
+		day, err := strconv.Atoi(field)
+		if err != nil || day < 1 || day > 7 {
+			return NewValidationError(CodeInvalidSchedule, "invalid schedule: days_of_week must be ISO weekdays from 1 (Monday) to 7 (Sunday)")
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: days_of_week is required")
+	}
+	slices.Sort(days)
+	fields := make([]string, len(days))
+	for i, day := range days {
+		fields[i] = strconv.Itoa(day)
+	}
+	schedule.DaysOfWeek = strings.Join(fields, ",")
+
+	if _, err := time.Parse("15:04", schedule.DepartureTime); err != nil {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: departure_time must be in HH:MM format")
+	}
+	if schedule.DurationMinutes <= 0 || schedule.DurationMinutes > 24*60 {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: duration_minutes must be between 1 and 1440")
+	}
+	if _, err := time.Parse(scheduleDateLayout, schedule.EffectiveFrom); err != nil {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: effective_from must be in YYYY-MM-DD format")
+	}
+	if schedule.EffectiveTo != "" {
+		if _, err := time.Parse(scheduleDateLayout, schedule.EffectiveTo); err != nil {
+			return NewValidationError(CodeInvalidSchedule, "invalid schedule: effective_to must be in YYYY-MM-DD format")
+		}
+		if schedule.EffectiveTo < schedule.EffectiveFrom {
+			return NewValidationError(CodeInvalidSchedule, "invalid schedule: effective_to must not be before effective_from")
+		}
+	}
+
+	if schedule.Capacity <= 0 {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: capacity must be positive")
+	}
+	if schedule.Price <= 0 {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: price must be positive")
+	}
+	if _, ok := refundRules[schedule.RefundRule]; !ok {
+		return NewValidationError(CodeInvalidSchedule, "invalid schedule: refund_rule must be Flexible, Standard or NonRefundable")
+	}
+	return nil
+}
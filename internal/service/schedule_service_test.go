@@ -0,0 +1,197 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSchedule creates a daily TPE-NRT schedule starting tomorrow in Taipei
+func newTestSchedule() models.Schedule {
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	return models.Schedule{
+		FlightNumber:     "BR198",
+		Airline:          "BR",
+		DepartureAirport: "TPE",
+		ArrivalAirport:   "NRT",
+		DaysOfWeek:       "7,1,2,3,4,5,6",
+		DepartureTime:    "08:30",
+		DurationMinutes:  195,
+		EffectiveFrom:    time.Now().In(taipei).AddDate(0, 0, 1).Format(scheduleDateLayout),
+		Capacity:         180,
+		Price:            520,
+	}
+}
+
+// scheduledFlights returns the schedule's flights by date
+func scheduledFlights(t *testing.T, svc ScheduleService, scheduleID uint) []models.Flight {
+	var flights []models.Flight
+	require.NoError(t, svc.(*ScheduleServiceImpl).DB.
+		Where("schedule_id = ?", scheduleID).Order("schedule_date ASC").Find(&flights).Error)
+	return flights
+}
+
+// TestCreateSchedule_GeneratesFlights tests that a new schedule fills the horizon with
+// flights and that generating again creates nothing
+func TestCreateSchedule_GeneratesFlights(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewScheduleService(db, 14)
+	schedule := newTestSchedule()
+
+	// When
+	created, sync, err := svc.CreateSchedule(&schedule)
+
+	// Then the horizon ends 14 days after today, and the schedule starts tomorrow
+	require.NoError(t, err)
+	assert.Equal(t, 14, sync.Created)
+	assert.Equal(t, "EVA Air", created.Airline)
+	assert.Equal(t, "1,2,3,4,5,6,7", created.DaysOfWeek)
+	assert.Equal(t, RefundRuleStandard, created.RefundRule)
+
+	flights := scheduledFlights(t, svc, created.ID)
+	require.Len(t, flights, 14)
+	first := flights[0]
+	assert.Equal(t, schedule.EffectiveFrom, first.ScheduleDate)
+	assert.Equal(t, schedule.EffectiveFrom+" 08:30", first.DepartureLocalTime)
+	assert.Equal(t, schedule.EffectiveFrom+" 12:45", first.ArrivalLocalTime, "NRT is an hour ahead of TPE")
+	assert.Equal(t, 195, first.DurationMinutes)
+	assert.Equal(t, "EVA Air", first.Airline)
+	assert.Equal(t, 180, first.Capacity)
+	assert.Equal(t, 180, first.AvailableSeats)
+
+	// When generating again, with one flight deleted by an admin
+	require.NoError(t, db.Delete(&flights[3]).Error)
+	again, err := svc.GenerateFlights(time.Now())
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 0, again)
+	assert.Len(t, scheduledFlights(t, svc, created.ID), 13)
+
+	// When the horizon rolls forward a week
+	later, err := svc.GenerateFlights(time.Now().AddDate(0, 0, 7))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 7, later)
+}
+
+// TestCreateSchedule_Validation tests that invalid schedules are rejected
+func TestCreateSchedule_Validation(t *testing.T) {
+	db := setupTestDB(t)
+	svc := NewScheduleService(db, 14)
+
+	cases := map[string]func(s *models.Schedule){
+		"no days":          func(s *models.Schedule) { s.DaysOfWeek = "" },
+		"bad weekday":      func(s *models.Schedule) { s.DaysOfWeek = "0,1" },
+		"bad time":         func(s *models.Schedule) { s.DepartureTime = "8.30" },
+		"no duration":      func(s *models.Schedule) { s.DurationMinutes = 0 },
+		"bad date":         func(s *models.Schedule) { s.EffectiveFrom = "2025/08/01" },
+		"ends before":      func(s *models.Schedule) { s.EffectiveTo = "2000-01-01" },
+		"no capacity":      func(s *models.Schedule) { s.Capacity = 0 },
+		"same airports":    func(s *models.Schedule) { s.ArrivalAirport = "TPE" },
+		"unknown airport":  func(s *models.Schedule) { s.ArrivalAirport = "XXX" },
+		"unknown airline":  func(s *models.Schedule) { s.Airline = "ZZ" },
+		"bad refund rule":  func(s *models.Schedule) { s.RefundRule = "Sometimes" },
+		"no flight number": func(s *models.Schedule) { s.FlightNumber = "" },
+	}
+	for name, mutate := range cases {
+		schedule := newTestSchedule()
+		mutate(&schedule)
+		_, _, err := svc.CreateSchedule(&schedule)
+		assert.ErrorIs(t, err, ErrValidation, name)
+	}
+
+	var count int64
+	require.NoError(t, db.Model(&models.Flight{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// TestUpdateSchedule_KeepsBookedFlights tests that a schedule change moves and removes
+// flights without bookings and leaves booked ones alone
+func TestUpdateSchedule_KeepsBookedFlights(t *testing.T) {
+	// Given a daily schedule with a booking on the first flight
+	db := setupTestDB(t)
+	svc := NewScheduleService(db, 14)
+	schedule := newTestSchedule()
+	created, _, err := svc.CreateSchedule(&schedule)
+	require.NoError(t, err)
+	flights := scheduledFlights(t, svc, created.ID)
+	booked := flights[0]
+	_, err = newTestBookingService(db).CreateBooking(newTestBooking(booked.ID, "Alice", 1))
+	require.NoError(t, err)
+
+	// When the schedule moves to 10:00 and drops the booked flight's weekday
+	date, err := time.Parse(scheduleDateLayout, booked.ScheduleDate)
+	require.NoError(t, err)
+	var days []string
+	for day := 1; day <= 7; day++ {
+		if time.Weekday(day%7) != date.Weekday() {
+			days = append(days, strconv.Itoa(day))
+		}
+	}
+	change := newTestSchedule()
+	change.DepartureTime = "10:00"
+	change.DaysOfWeek = strings.Join(days, ",")
+	updated, sync, err := svc.UpdateSchedule(created.ID, &change)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, []uint{booked.ID}, sync.KeptFlightIDs)
+	assert.Equal(t, 1, sync.Removed, "the other flight on that weekday")
+	assert.Equal(t, 12, sync.Updated)
+	assert.Equal(t, 0, sync.Created)
+
+	var kept models.Flight
+	require.NoError(t, db.First(&kept, booked.ID).Error)
+	assert.Equal(t, booked.DepartureLocalTime, kept.DepartureLocalTime)
+	assert.Equal(t, 179, kept.AvailableSeats)
+
+	remaining := scheduledFlights(t, svc, created.ID)
+	require.Len(t, remaining, 13)
+	assert.Equal(t, remaining[1].ScheduleDate+" 10:00", remaining[1].DepartureLocalTime)
+
+	// When the weekday is added back
+	change = newTestSchedule()
+	change.DepartureTime = "10:00"
+	_, sync, err = svc.UpdateSchedule(created.ID, &change)
+
+	// Then the removed date is generated again and the booked flight is still kept
+	require.NoError(t, err)
+	assert.Equal(t, 1, sync.Created)
+	assert.Equal(t, []uint{booked.ID}, sync.KeptFlightIDs)
+}
+
+// TestDeleteSchedule tests that deleting a schedule removes its unbooked flights
+func TestDeleteSchedule(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := NewScheduleService(db, 14)
+	schedule := newTestSchedule()
+	created, _, err := svc.CreateSchedule(&schedule)
+	require.NoError(t, err)
+	booked := scheduledFlights(t, svc, created.ID)[2]
+	_, err = newTestBookingService(db).CreateBooking(newTestBooking(booked.ID, "Alice", 1))
+	require.NoError(t, err)
+
+	// When
+	sync, err := svc.DeleteSchedule(created.ID)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 13, sync.Removed)
+	assert.Equal(t, []uint{booked.ID}, sync.KeptFlightIDs)
+	remaining := scheduledFlights(t, svc, created.ID)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, booked.ID, remaining[0].ID)
+
+	_, err = svc.DeleteSchedule(created.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
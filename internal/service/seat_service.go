@@ -0,0 +1,458 @@
+package service
+
+import (
+	"errors"
+	"flight-booking/internal/models"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SeatStatusAvailable = "Available"
+	SeatStatusOccupied  = "Occupied"
+	SeatStatusBlocked   = "Blocked"
+)
+
+// maxSeatMapRows keeps seat names within the 4 characters stored on SeatAssignment
+const maxSeatMapRows = 99
+
+var (
+	seatPattern        = regexp.MustCompile(`^([1-9][0-9]?)([A-Z])$`)
+	seatColumnsPattern = regexp.MustCompile(`^[A-Z]{1,10}$`)
+)
+
+// SeatView is one seat of a SeatMapView
+type SeatView struct {
+	Seat   string `json:"seat"`
+	Status string `json:"status"` // "Available", "Occupied" or "Blocked"
+}
+
+// SeatRowView is one row of a SeatMapView
+type SeatRowView struct {
+	Row     int        `json:"row"`
+	Cabin   string     `json:"cabin"`
+	ExitRow bool       `json:"exit_row"`
+	Seats   []SeatView `json:"seats"`
+}
+
+// SeatMapView is a flight's seat map with the occupancy of every seat
+type SeatMapView struct {
+	FlightID  uint          `json:"flight_id"`
+	Columns   string        `json:"columns"`
+	Available int           `json:"available"`
+	Rows      []SeatRowView `json:"rows"`
+}
+
+// SeatRequest picks a seat for one passenger of a booking
+type SeatRequest struct {
+	PassengerID uint
+	Seat        string
+}
+
+type SeatService interface {
+	GetSeatMap(flightID uint) (*SeatMapView, error)
+	SetSeatMap(flightID uint, seatMap *models.SeatMap) (*SeatMapView, error)
+	AssignSeats(bookingID uint, seats []SeatRequest) (*models.Booking, error)
+}
+
+type SeatServiceImpl struct {
+	DB *gorm.DB
+}
+
+func NewSeatService(db *gorm.DB) SeatService {
+	return &SeatServiceImpl{DB: db}
+}
+
+// GetSeatMap implements SeatService.GetSeatMap
+func (s *SeatServiceImpl) GetSeatMap(flightID uint) (*SeatMapView, error) {
+	seatMap, err := loadSeatMap(s.DB, flightID)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := newSeatLayout(seatMap)
+	if err != nil {
+		return nil, err
+	}
+	return buildSeatMapView(s.DB, layout)
+}
+
+// SetSeatMap creates or replaces a flight's seat map. Seats that are already assigned
+// must still exist and must not become blocked.
+func (s *SeatServiceImpl) SetSeatMap(flightID uint, seatMap *models.SeatMap) (*SeatMapView, error) {
+	seatMap.FlightID = flightID
+	layout, err := newSeatLayout(seatMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var view *SeatMapView
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var flight models.Flight
+		// Lock the flight so seats are not assigned against the old map meanwhile
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&flight, flightID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeFlightNotFound, "flight not found")
+			}
+			return fmt.Errorf("failed to lock flight: %w", err)
+		}
+
+		var classes []models.FareClass
+		if err := tx.Where("flight_id = ?", flightID).Find(&classes).Error; err != nil {
+			return fmt.Errorf("failed to load fare classes: %w", err)
+		}
+		if len(classes) > 0 {
+			for _, cabin := range layout.cabins {
+				if findFareClass(classes, cabin.Name) == nil {
+					return NewValidationError(CodeInvalidSeatMap, "invalid seat map: cabin %q is not a fare class of this flight", cabin.Name)
+				}
+			}
+		}
+
+		var assignments []models.SeatAssignment
+		if err := tx.Where("flight_id = ?", flightID).Find(&assignments).Error; err != nil {
+			return fmt.Errorf("failed to load seat assignments: %w", err)
+		}
+		for _, a := range assignments {
+			if _, err := layout.checkSeat(a.Seat); err != nil {
+				return NewConflictError(CodeSeatMapInUse, "seat %s is assigned and must stay on the seat map", a.Seat)
+			}
+		}
+
+		var existing models.SeatMap
+		err := tx.Where("flight_id = ?", flightID).First(&existing).Error
+		switch {
+		case err == nil:
+			seatMap.ID = existing.ID
+			seatMap.CreatedAt = existing.CreatedAt
+			if err := tx.Unscoped().Where("seat_map_id = ?", existing.ID).Delete(&models.SeatMapCabin{}).Error; err != nil {
+				return fmt.Errorf("failed to replace seat map cabins: %w", err)
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return fmt.Errorf("failed to load seat map: %w", err)
+		}
+
+		if err := tx.Save(seatMap).Error; err != nil {
+			return fmt.Errorf("failed to save seat map: %w", err)
+		}
+
+		view, err = buildSeatMapView(tx, layout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// AssignSeats implements SeatService.AssignSeats. Passengers not listed keep their seats.
+func (s *SeatServiceImpl) AssignSeats(bookingID uint, seats []SeatRequest) (*models.Booking, error) {
+	if len(seats) == 0 {
+		return nil, NewValidationError(CodeInvalidSeat, "at least one seat is required")
+	}
+
+	var booking models.Booking
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the booking so two seat changes for it cannot interleave
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&booking, bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeBookingNotFound, "booking not found")
+			}
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+		if err := tx.Where("booking_id = ?", booking.ID).Order("id ASC").Find(&booking.Passengers).Error; err != nil {
+			return fmt.Errorf("failed to load passengers: %w", err)
+		}
+
+		return assignSeats(tx, &booking, seats)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+// assignRequestedSeats assigns the seats passengers picked when booking. It runs in
+// the booking's transaction after the booking and its passengers were created.
+func assignRequestedSeats(tx *gorm.DB, booking *models.Booking) error {
+	var requests []SeatRequest
+	for _, p := range booking.Passengers {
+		if p.Seat != "" {
+			requests = append(requests, SeatRequest{PassengerID: p.ID, Seat: p.Seat})
+		}
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	return assignSeats(tx, booking, requests)
+}
+
+// dropRequestedSeats moves each passenger's requested seat to DroppedSeat, so the
+// booking is created without seats and the response shows what was not assigned
+func dropRequestedSeats(booking *models.Booking) {
+	for i := range booking.Passengers {
+		p := &booking.Passengers[i]
+		if p.Seat != "" {
+			p.DroppedSeat, p.Seat = p.Seat, ""
+		}
+	}
+}
+
+// assignSeats gives each requested passenger of the booking their seat. The booking's
+// passengers must be loaded. Double assignment is caught by the unique index on
+// (flight_id, seat), so two bookings racing for a seat cannot both get it.
+func assignSeats(tx *gorm.DB, booking *models.Booking, requests []SeatRequest) error {
+	if booking.BookingStatus != BookingStatusConfirmed {
+		return NewConflictError(CodeBookingNotConfirmed, "seats can only be chosen on confirmed bookings")
+	}
+
+	seatMap, err := loadSeatMap(tx, booking.FlightID)
+	if err != nil {
+		return err
+	}
+	layout, err := newSeatLayout(seatMap)
+	if err != nil {
+		return err
+	}
+
+	passengers := make(map[uint]*models.Passenger, len(booking.Passengers))
+	for i := range booking.Passengers {
+		passengers[booking.Passengers[i].ID] = &booking.Passengers[i]
+	}
+
+	seen := make(map[string]bool, len(requests))
+	passengerIDs := make([]uint, 0, len(requests))
+	for i := range requests {
+		r := &requests[i]
+		p, ok := passengers[r.PassengerID]
+		if !ok {
+			return NewValidationError(CodeInvalidSeat, "passenger %d is not on this booking", r.PassengerID)
+		}
+		for _, id := range passengerIDs {
+			if id == r.PassengerID {
+				return NewValidationError(CodeInvalidSeat, "passenger %d is given more than one seat", r.PassengerID)
+			}
+		}
+
+		r.Seat = strings.ToUpper(strings.TrimSpace(r.Seat))
+		row, err := layout.checkSeat(r.Seat)
+		if err != nil {
+			return err
+		}
+		if seen[r.Seat] {
+			return NewValidationError(CodeInvalidSeat, "seat %s is requested more than once", r.Seat)
+		}
+		seen[r.Seat] = true
+
+		if booking.FareClass != "" && layout.cabinOf(row) != booking.FareClass {
+			return NewValidationError(CodeInvalidSeat, "seat %s is not in the %s cabin", r.Seat, booking.FareClass)
+		}
+		// Passengers in exit rows must be able to help in an evacuation
+		if layout.exitRows[row] && p.PassengerType != PassengerTypeAdult {
+			return NewValidationError(CodeInvalidSeat, "seat %s is in an exit row, which is for adults only", r.Seat)
+		}
+		passengerIDs = append(passengerIDs, r.PassengerID)
+	}
+
+	// Free the passengers' current seats first so passengers of one booking can swap
+	if err := tx.Unscoped().Where("passenger_id IN ?", passengerIDs).Delete(&models.SeatAssignment{}).Error; err != nil {
+		return fmt.Errorf("failed to release seats: %w", err)
+	}
+
+	for _, r := range requests {
+		assignment := models.SeatAssignment{
+			FlightID:    booking.FlightID,
+			Seat:        r.Seat,
+			BookingID:   booking.ID,
+			PassengerID: r.PassengerID,
+		}
+		if err := tx.Create(&assignment).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return NewConflictError(CodeSeatTaken, "seat %s is already taken", r.Seat)
+			}
+			return fmt.Errorf("failed to assign seat: %w", err)
+		}
+
+		p := passengers[r.PassengerID]
+		p.Seat = r.Seat
+		if err := tx.Model(p).Update("seat", r.Seat).Error; err != nil {
+			return fmt.Errorf("failed to update passenger seat: %w", err)
+		}
+	}
+	return nil
+}
+
+// releaseSeatAssignments frees every seat of a booking that is being cancelled
+func releaseSeatAssignments(tx *gorm.DB, booking *models.Booking) error {
+	if err := tx.Unscoped().Where("booking_id = ?", booking.ID).Delete(&models.SeatAssignment{}).Error; err != nil {
+		return fmt.Errorf("failed to release seats: %w", err)
+	}
+	if err := tx.Model(&models.Passenger{}).Where("booking_id = ? AND seat <> ''", booking.ID).
+		Update("seat", "").Error; err != nil {
+		return fmt.Errorf("failed to clear passenger seats: %w", err)
+	}
+	for i := range booking.Passengers {
+		booking.Passengers[i].Seat = ""
+	}
+	return nil
+}
+
+// loadSeatMap returns the seat map of a flight with its cabins
+func loadSeatMap(db *gorm.DB, flightID uint) (*models.SeatMap, error) {
+	var seatMap models.SeatMap
+	if err := db.Preload("Cabins").Where("flight_id = ?", flightID).First(&seatMap).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError(CodeSeatMapNotFound, "seat map not found")
+		}
+		return nil, fmt.Errorf("failed to load seat map: %w", err)
+	}
+	return &seatMap, nil
+}
+
+// buildSeatMapView lists every seat of the layout with its current occupancy
+func buildSeatMapView(db *gorm.DB, layout *seatLayout) (*SeatMapView, error) {
+	var assignments []models.SeatAssignment
+	if err := db.Where("flight_id = ?", layout.seatMap.FlightID).Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load seat assignments: %w", err)
+	}
+	occupied := make(map[string]bool, len(assignments))
+	for _, a := range assignments {
+		occupied[a.Seat] = true
+	}
+
+	view := SeatMapView{FlightID: layout.seatMap.FlightID, Columns: layout.seatMap.Columns}
+	for row := 1; row <= layout.seatMap.Rows; row++ {
+		rowView := SeatRowView{Row: row, Cabin: layout.cabinOf(row), ExitRow: layout.exitRows[row]}
+		for _, column := range layout.seatMap.Columns {
+			seat := fmt.Sprintf("%d%c", row, column)
+			status := SeatStatusAvailable
+			switch {
+			case layout.blocked[seat]:
+				status = SeatStatusBlocked
+			case occupied[seat]:
+				status = SeatStatusOccupied
+			default:
+				view.Available++
+			}
+			rowView.Seats = append(rowView.Seats, SeatView{Seat: seat, Status: status})
+		}
+		view.Rows = append(view.Rows, rowView)
+	}
+	return &view, nil
+}
+
+// seatLayout is a validated seat map with its lists parsed for lookups
+type seatLayout struct {
+	seatMap  *models.SeatMap
+	cabins   []models.SeatMapCabin // sorted by row
+	exitRows map[int]bool
+	blocked  map[string]bool
+}
+
+// newSeatLayout validates the seat map and parses its exit rows and blocked seats
+func newSeatLayout(seatMap *models.SeatMap) (*seatLayout, error) {
+	if seatMap.Rows < 1 || seatMap.Rows > maxSeatMapRows {
+		return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: rows must be between 1 and %d", maxSeatMapRows)
+	}
+	if !seatColumnsPattern.MatchString(seatMap.Columns) {
+		return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: columns must be 1 to 10 uppercase letters")
+	}
+	for i, column := range seatMap.Columns {
+		if strings.ContainsRune(seatMap.Columns[i+1:], column) {
+			return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: column %c is listed twice", column)
+		}
+	}
+
+	layout := &seatLayout{
+		seatMap:  seatMap,
+		cabins:   append([]models.SeatMapCabin(nil), seatMap.Cabins...),
+		exitRows: make(map[int]bool),
+		blocked:  make(map[string]bool),
+	}
+
+	// Cabins must cover every row exactly once
+	sort.Slice(layout.cabins, func(i, j int) bool { return layout.cabins[i].FirstRow < layout.cabins[j].FirstRow })
+	nextRow := 1
+	for _, cabin := range layout.cabins {
+		if cabin.Name == "" {
+			return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: cabin name is required")
+		}
+		if cabin.FirstRow != nextRow || cabin.LastRow < cabin.FirstRow {
+			return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: cabins must cover rows 1 to %d in order without gaps or overlaps", seatMap.Rows)
+		}
+		nextRow = cabin.LastRow + 1
+	}
+	if nextRow != seatMap.Rows+1 {
+		return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: cabins must cover rows 1 to %d in order without gaps or overlaps", seatMap.Rows)
+	}
+
+	for _, field := range splitList(seatMap.ExitRows) {
+		row, err := strconv.Atoi(field)
+		if err != nil || row < 1 || row > seatMap.Rows {
+			return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: exit row %q is not a row of this seat map", field)
+		}
+		layout.exitRows[row] = true
+	}
+
+	for _, seat := range splitList(seatMap.BlockedSeats) {
+		row, column, ok := parseSeat(seat)
+		if !ok || row > seatMap.Rows || !strings.ContainsRune(seatMap.Columns, column) {
+			return nil, NewValidationError(CodeInvalidSeatMap, "invalid seat map: blocked seat %q is not on this seat map", seat)
+		}
+		layout.blocked[seat] = true
+	}
+
+	return layout, nil
+}
+
+// cabinOf returns the cabin a row belongs to
+func (l *seatLayout) cabinOf(row int) string {
+	for _, cabin := range l.cabins {
+		if row >= cabin.FirstRow && row <= cabin.LastRow {
+			return cabin.Name
+		}
+	}
+	return ""
+}
+
+// checkSeat returns the row of a seat that exists on the map and can be sold
+func (l *seatLayout) checkSeat(seat string) (int, error) {
+	row, column, ok := parseSeat(seat)
+	if !ok || row > l.seatMap.Rows || !strings.ContainsRune(l.seatMap.Columns, column) {
+		return 0, NewValidationError(CodeInvalidSeat, "seat %q is not on this flight's seat map", seat)
+	}
+	if l.blocked[seat] {
+		return 0, NewValidationError(CodeInvalidSeat, "seat %s is blocked", seat)
+	}
+	return row, nil
+}
+
+// parseSeat splits a seat name such as "12C" into its row and column
+func parseSeat(seat string) (int, rune, bool) {
+	m := seatPattern.FindStringSubmatch(seat)
+	if m == nil {
+		return 0, 0, false
+	}
+	row, _ := strconv.Atoi(m[1])
+	return row, rune(m[2][0]), true
+}
+
+// splitList splits a comma-separated list, dropping blanks
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSeatMap is a 5-row ABCD layout: Business rows 1-2, Economy rows 3-5,
+// an exit row at 4 and 1B blocked
+func newTestSeatMap() *models.SeatMap {
+	return &models.SeatMap{
+		Rows:    5,
+		Columns: "ABCD",
+		Cabins: []models.SeatMapCabin{
+			{Name: FareClassBusiness, FirstRow: 1, LastRow: 2},
+			{Name: FareClassEconomy, FirstRow: 3, LastRow: 5},
+		},
+		ExitRows:     "4",
+		BlockedSeats: "1B",
+	}
+}
+
+// TestSeatMap_AssignAndRelease tests choosing seats at booking time, changing them later and freeing them on cancel
+func TestSeatMap_AssignAndRelease(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	seats := NewSeatService(db)
+	bookings := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 10
+	require.NoError(t, db.Create(&flight).Error)
+	_, err := seats.SetSeatMap(flight.ID, newTestSeatMap())
+	require.NoError(t, err)
+
+	// When: seats are picked at booking time
+	booking := newTestBooking(flight.ID, "A", 2)
+	booking.Passengers[0].Seat = "3a"
+	booking.Passengers[1].Seat = "3B"
+	created, err := bookings.CreateBooking(booking)
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, "3A", created.Passengers[0].Seat)
+	view, err := seats.GetSeatMap(flight.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 17, view.Available) // 20 seats, 1 blocked, 2 taken
+	assert.Equal(t, SeatStatusOccupied, view.Rows[2].Seats[0].Status)
+	assert.Equal(t, SeatStatusBlocked, view.Rows[0].Seats[1].Status)
+	assert.True(t, view.Rows[3].ExitRow)
+
+	// Passengers of one booking can swap seats
+	swapped, err := seats.AssignSeats(created.ID, []SeatRequest{
+		{PassengerID: created.Passengers[0].ID, Seat: "3B"},
+		{PassengerID: created.Passengers[1].ID, Seat: "3A"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "3B", swapped.Passengers[0].Seat)
+
+	// A seat held by another booking is taken
+	other, err := bookings.CreateBooking(newTestBooking(flight.ID, "B", 1))
+	require.NoError(t, err)
+	_, err = seats.AssignSeats(other.ID, []SeatRequest{{PassengerID: other.Passengers[0].ID, Seat: "3A"}})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	// Cancelling frees the seats
+	_, err = bookings.CancelBooking(created.ID)
+	require.NoError(t, err)
+	_, err = seats.AssignSeats(other.ID, []SeatRequest{{PassengerID: other.Passengers[0].ID, Seat: "3A"}})
+	require.NoError(t, err)
+}
+
+// TestCreateBooking_WaitlistedDropsSeats tests that a waitlisted booking is accepted
+// without the seats it requested and can pick seats once it is promoted
+func TestCreateBooking_WaitlistedDropsSeats(t *testing.T) {
+	// Given a full flight
+	db := setupTestDB(t)
+	seats := NewSeatService(db)
+	bookings := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 1
+	require.NoError(t, db.Create(&flight).Error)
+	_, err := seats.SetSeatMap(flight.ID, newTestSeatMap())
+	require.NoError(t, err)
+	confirmed, err := bookings.CreateBooking(newTestBooking(flight.ID, "A", 1))
+	require.NoError(t, err)
+
+	// When
+	booking := newTestBooking(flight.ID, "B", 1)
+	booking.Passengers[0].Seat = "3A"
+	waitlisted, err := bookings.CreateBooking(booking)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusWaitlisted, waitlisted.BookingStatus)
+	assert.Empty(t, waitlisted.Passengers[0].Seat)
+	assert.Equal(t, "3A", waitlisted.Passengers[0].DroppedSeat)
+
+	var assigned int64
+	require.NoError(t, db.Model(&models.SeatAssignment{}).Count(&assigned).Error)
+	assert.Zero(t, assigned)
+
+	// Once promoted the passenger can choose a seat
+	_, err = bookings.CancelBooking(confirmed.ID)
+	require.NoError(t, err)
+	promoted, err := seats.AssignSeats(waitlisted.ID, []SeatRequest{{PassengerID: waitlisted.Passengers[0].ID, Seat: "3A"}})
+	require.NoError(t, err)
+	assert.Equal(t, "3A", promoted.Passengers[0].Seat)
+}
+
+// TestAssignSeats_Invalid tests that seats off the map, blocked or not allowed for the passenger are rejected
+func TestAssignSeats_Invalid(t *testing.T) {
+	db := setupTestDB(t)
+	seats := NewSeatService(db)
+	bookings := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 10
+	require.NoError(t, db.Create(&flight).Error)
+	_, err := seats.SetSeatMap(flight.ID, newTestSeatMap())
+	require.NoError(t, err)
+
+	booking := newTestBooking(flight.ID, "A", 2)
+	booking.Passengers[1] = newTestPassenger(PassengerTypeChild)
+	created, err := bookings.CreateBooking(booking)
+	require.NoError(t, err)
+	adult, child := created.Passengers[0].ID, created.Passengers[1].ID
+
+	cases := map[string][]SeatRequest{
+		"off the map":        {{PassengerID: adult, Seat: "9A"}},
+		"unknown column":     {{PassengerID: adult, Seat: "3F"}},
+		"blocked":            {{PassengerID: adult, Seat: "1B"}},
+		"child in exit row":  {{PassengerID: child, Seat: "4A"}},
+		"same seat twice":    {{PassengerID: adult, Seat: "3A"}, {PassengerID: child, Seat: "3A"}},
+		"other passenger":    {{PassengerID: 999, Seat: "3A"}},
+		"passenger repeated": {{PassengerID: adult, Seat: "3A"}, {PassengerID: adult, Seat: "3B"}},
+	}
+	for name, requests := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := seats.AssignSeats(created.ID, requests)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
+
+// TestAssignSeats_Concurrent tests that only one of several concurrent requests gets the same seat
+func TestAssignSeats_Concurrent(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	seats := NewSeatService(db)
+	bookings := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 10
+	require.NoError(t, db.Create(&flight).Error)
+	_, err := seats.SetSeatMap(flight.ID, newTestSeatMap())
+	require.NoError(t, err)
+
+	var created []*models.Booking
+	for i := 0; i < 5; i++ {
+		b, err := bookings.CreateBooking(newTestBooking(flight.ID, "A", 1))
+		require.NoError(t, err)
+		created = append(created, b)
+	}
+
+	// When
+	var wg sync.WaitGroup
+	errs := make([]error, len(created))
+	for i, b := range created {
+		wg.Add(1)
+		go func(i int, b *models.Booking) {
+			defer wg.Done()
+			_, errs[i] = seats.AssignSeats(b.ID, []SeatRequest{{PassengerID: b.Passengers[0].ID, Seat: "5D"}})
+		}(i, b)
+	}
+	wg.Wait()
+
+	// Then
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrConflict)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	var count int64
+	require.NoError(t, db.Model(&models.SeatAssignment{}).Where("flight_id = ? AND seat = ?", flight.ID, "5D").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestSetSeatMap_Invalid tests seat map validation
+func TestSetSeatMap_Invalid(t *testing.T) {
+	db := setupTestDB(t)
+	seats := NewSeatService(db)
+
+	flight := newTestFlight()
+	require.NoError(t, db.Create(&flight).Error)
+
+	cases := map[string]func(m *models.SeatMap){
+		"no rows":          func(m *models.SeatMap) { m.Rows = 0 },
+		"lowercase column": func(m *models.SeatMap) { m.Columns = "abcd" },
+		"repeated column":  func(m *models.SeatMap) { m.Columns = "ABCA" },
+		"cabin gap":        func(m *models.SeatMap) { m.Cabins[1].FirstRow = 4 },
+		"rows uncovered":   func(m *models.SeatMap) { m.Rows = 6 },
+		"exit row outside": func(m *models.SeatMap) { m.ExitRows = "7" },
+		"blocked outside":  func(m *models.SeatMap) { m.BlockedSeats = "1E" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			seatMap := newTestSeatMap()
+			mutate(seatMap)
+			_, err := seats.SetSeatMap(flight.ID, seatMap)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
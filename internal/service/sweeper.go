@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Failed deliveries are retried after a delay that doubles with every attempt,
+// from retryBaseBackoff up to retryMaxBackoff
+const (
+	retryBaseBackoff = 30 * time.Second
+	retryMaxBackoff  = time.Hour
+)
+
+// RunSweeper calls sweep every interval until ctx is cancelled. sweep returns how
+// many records it handled; errors are logged and retried on the next tick.
+func RunSweeper(ctx context.Context, name string, interval time.Duration, sweep func(now time.Time) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			handled, err := sweep(now)
+			if err != nil {
+				log.Printf("%s: %v", name, err)
+				continue
+			}
+			if handled > 0 {
+				log.Printf("%s: handled %d", name, handled)
+			}
+		}
+	}
+}
+
+// retryBackoff returns the delay before the next attempt after the given number of attempts
+func retryBackoff(attempts int) time.Duration {
+	backoff := retryBaseBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > retryMaxBackoff {
+		return retryMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WaitlistEngine promotes waitlisted bookings when seats are returned to a flight
+type WaitlistEngine interface {
+	// PromoteWaitlisted must be called inside the transaction that returned the
+	// seats, after the flight row has been locked and saved.
+	PromoteWaitlisted(tx *gorm.DB, flight *models.Flight) ([]models.Booking, error)
+}
+
+// FIFOWaitlistEngine promotes the oldest waitlisted bookings first. A promoted booking
+// must be paid within PaymentWindow.
+type FIFOWaitlistEngine struct {
+	PaymentWindow time.Duration
+}
+
+// NewWaitlistEngine creates a new FIFOWaitlistEngine
+func NewWaitlistEngine() WaitlistEngine {
+	return &FIFOWaitlistEngine{PaymentWindow: DefaultPaymentWindow}
+}
+
+// PromoteWaitlisted implements WaitlistEngine.PromoteWaitlisted
+func (e *FIFOWaitlistEngine) PromoteWaitlisted(tx *gorm.DB, flight *models.Flight) ([]models.Booking, error) {
+	var waitlisted []models.Booking
+	// IDs are assigned by the database in insert order, so they give the queue order
+	if err := tx.Where("flight_id = ? AND booking_status = ?", flight.ID, BookingStatusWaitlisted).
+		Order("id ASC").
+		Find(&waitlisted).Error; err != nil {
+		return nil, fmt.Errorf("failed to load waitlist: %w", err)
+	}
+
+	var classes []models.FareClass
+	if err := tx.Where("flight_id = ?", flight.ID).Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load fare classes: %w", err)
+	}
+
+	// Each fare class is its own inventory; bookings without a class use the flight's.
+	// Waitlisted bookings have already been deducted from their inventory, so the
+	// seats free for confirmation are the available seats plus the waitlisted ones.
+	free := map[string]int{"": flight.AvailableSeats}
+	for _, c := range classes {
+		free[c.Name] = c.AvailableSeats
+	}
+	for _, b := range waitlisted {
+		free[b.FareClass] += b.Quantity
+	}
+
+	var promoted []models.Booking
+	for _, b := range waitlisted {
+		// Skip bookings that do not fit so smaller ones behind them can still move up
+		if b.Quantity > free[b.FareClass] {
+			continue
+		}
+
+		b.BookingStatus = BookingStatusConfirmed
+		// The payment clock starts now that the booking has seats. Bookings paid
+		// while waitlisted, before that was refused, are already settled.
+		if b.PaymentStatus != PaymentStatusPaid {
+			awaitPayment(&b, e.PaymentWindow)
+		}
+		if err := tx.Save(&b).Error; err != nil {
+			return nil, fmt.Errorf("failed to promote booking %d: %w", b.ID, err)
+		}
+
+		free[b.FareClass] -= b.Quantity
+		promoted = append(promoted, b)
+	}
+
+	return promoted, nil
+}
+
+// promoteWaitlist runs the waitlist engine, updates the orders of the promoted bookings
+// and queues a notification for every promoted booking
+func promoteWaitlist(tx *gorm.DB, waitlist WaitlistEngine, flight *models.Flight) error {
+	promoted, err := waitlist.PromoteWaitlisted(tx, flight)
+	if err != nil {
+		return err
+	}
+	for i := range promoted {
+		if err := refreshOrderStatus(tx, &promoted[i]); err != nil {
+			return err
+		}
+		if err := enqueueBookingEvent(tx, notification.EventBookingPromoted, &promoted[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
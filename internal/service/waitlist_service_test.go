@@ -0,0 +1,171 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/payment"
+	"flight-booking/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testModels is every table the services use
+var testModels = []interface{}{
+	&models.Flight{},
+	&models.FareClass{},
+	&models.OversellRule{},
+	&models.Booking{},
+	&models.Passenger{},
+	&models.SeatMap{},
+	&models.SeatMapCabin{},
+	&models.SeatAssignment{},
+	&models.Order{},
+	&models.SeatHold{},
+	&models.Refund{},
+	&models.OutboxEvent{},
+	&models.WebhookSubscription{},
+	&models.WebhookDelivery{},
+	&models.Airport{},
+	&models.Airline{},
+	&models.Schedule{},
+	&models.FlightStatusEvent{},
+}
+
+// setupTestDB creates an in-memory database with the schema migrated and the reference data loaded
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+
+	// An in-memory database only lives as long as its connection
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.AutoMigrate(testModels...))
+	require.NoError(t, LoadReferenceData(db))
+	return db
+}
+
+func newTestBookingService(db *gorm.DB) BookingService {
+	return NewBookingService(repository.NewGORMBookingRepository(db), db, FixedOversell{Seats: 10}, NewRefundService(db, payment.NewFakeGateway()))
+}
+
+// newTestBooking creates a booking request with one adult passenger per seat
+func newTestBooking(flightID uint, name string, quantity int) *models.Booking {
+	booking := &models.Booking{FlightID: flightID, PassengerName: name, Quantity: quantity}
+	for i := 0; i < quantity; i++ {
+		booking.Passengers = append(booking.Passengers, newTestPassenger(PassengerTypeAdult))
+	}
+	return booking
+}
+
+func newTestPassenger(passengerType string) models.Passenger {
+	birth := map[string]string{
+		PassengerTypeAdult:  "1990-01-01",
+		PassengerTypeChild:  "2018-01-01",
+		PassengerTypeInfant: "2025-01-01",
+	}[passengerType]
+	return models.Passenger{Name: "Test " + passengerType, DateOfBirth: birth, DocumentNumber: "X1234567", PassengerType: passengerType}
+}
+
+// TestCancelBooking_PromotesWaitlist tests that freed seats confirm waitlisted bookings in FIFO order
+func TestCancelBooking_PromotesWaitlist(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	confirmed, err := svc.CreateBooking(newTestBooking(flight.ID, "A", 2))
+	require.NoError(t, err)
+	large, err := svc.CreateBooking(newTestBooking(flight.ID, "B", 3))
+	require.NoError(t, err)
+	small, err := svc.CreateBooking(newTestBooking(flight.ID, "C", 1))
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, confirmed.BookingStatus)
+	assert.Equal(t, BookingStatusWaitlisted, large.BookingStatus)
+	assert.Equal(t, BookingStatusWaitlisted, small.BookingStatus)
+
+	// When
+	_, err = svc.CancelBooking(confirmed.ID)
+	require.NoError(t, err)
+
+	// Then
+	got, err := svc.GetBooking(large.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusWaitlisted, got.BookingStatus, "booking larger than freed seats stays waitlisted")
+
+	got, err = svc.GetBooking(small.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, -2, reloaded.AvailableSeats, "promotion must not deduct seats twice")
+}
+
+// TestPromoteWaitlisted_CreationOrder tests that the queue follows the order bookings
+// were made in, whatever creation time a booking arrives with
+func TestPromoteWaitlisted_CreationOrder(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 1
+	require.NoError(t, db.Create(&flight).Error)
+
+	confirmed, err := svc.CreateBooking(newTestBooking(flight.ID, "A", 1))
+	require.NoError(t, err)
+	first, err := svc.CreateBooking(newTestBooking(flight.ID, "B", 1))
+	require.NoError(t, err)
+	backdated := newTestBooking(flight.ID, "C", 1)
+	backdated.CreatedAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	second, err := svc.CreateBooking(backdated)
+	require.NoError(t, err)
+
+	// When
+	_, err = svc.CancelBooking(confirmed.ID)
+	require.NoError(t, err)
+
+	// Then
+	got, err := svc.GetBooking(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusConfirmed, got.BookingStatus)
+	got, err = svc.GetBooking(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, BookingStatusWaitlisted, got.BookingStatus)
+	assert.True(t, got.CreatedAt.After(time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+// TestCancelBooking_Twice tests that a cancelled booking cannot be cancelled again
+func TestCancelBooking_Twice(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	svc := newTestBookingService(db)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 5
+	require.NoError(t, db.Create(&flight).Error)
+
+	booking, err := svc.CreateBooking(newTestBooking(flight.ID, "A", 2))
+	require.NoError(t, err)
+
+	// When
+	_, err = svc.CancelBooking(booking.ID)
+	require.NoError(t, err)
+	_, err = svc.CancelBooking(booking.ID)
+
+	// Then
+	assert.ErrorIs(t, err, ErrConflict)
+
+	var reloaded models.Flight
+	require.NoError(t, db.First(&reloaded, flight.ID).Error)
+	assert.Equal(t, 5, reloaded.AvailableSeats)
+}
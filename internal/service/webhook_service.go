@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"flight-booking/internal/webhook"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DeliveryStatusPending    = "Pending"
+	DeliveryStatusDelivered  = "Delivered"
+	DeliveryStatusDeadLetter = "DeadLetter"
+)
+
+// A delivery is moved to the dead-letter list after webhookMaxAttempts attempts
+const (
+	webhookMaxAttempts   = 6
+	webhookBatchSize     = 100
+	webhookMinSecretSize = 16
+)
+
+// webhookEventTypes are the booking events partners can subscribe to
+var webhookEventTypes = map[string]bool{
+	notification.EventBookingConfirmed:  true,
+	notification.EventBookingWaitlisted: true,
+	notification.EventBookingCancelled:  true,
+	notification.EventBookingPromoted:   true,
+	notification.EventBookingDelayed:    true,
+	notification.EventBookingDisrupted:  true,
+}
+
+type WebhookService interface {
+	CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	ListDeliveries(subscriptionID uint) ([]models.WebhookDelivery, error)
+	ListDeadLetters() ([]models.WebhookDelivery, error)
+	RetryDelivery(id uint) (*models.WebhookDelivery, error)
+	DispatchPending(now time.Time) (int, error)
+}
+
+type WebhookServiceImpl struct {
+	DB     *gorm.DB
+	Sender *webhook.Sender
+}
+
+func NewWebhookService(db *gorm.DB, sender *webhook.Sender) WebhookService {
+	return &WebhookServiceImpl{
+		DB:     db,
+		Sender: sender,
+	}
+}
+
+// CreateSubscription validates and stores a new active subscription
+func (s *WebhookServiceImpl) CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	subscription.Active = true
+	if err := s.DB.Create(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// ListSubscriptions returns every active subscription
+func (s *WebhookServiceImpl) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := s.DB.Where("active = ?", true).Order("id ASC").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// DeleteSubscription deactivates a subscription. Its pending deliveries are still sent
+// and its delivery log is kept.
+func (s *WebhookServiceImpl) DeleteSubscription(id uint) error {
+	result := s.DB.Model(&models.WebhookSubscription{}).
+		Where("id = ? AND active = ?", id, true).
+		Update("active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return NewNotFoundError(CodeWebhookNotFound, "webhook subscription not found")
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first
+func (s *WebhookServiceImpl) ListDeliveries(subscriptionID uint) ([]models.WebhookDelivery, error) {
+	var subscription models.WebhookSubscription
+	if err := s.DB.First(&subscription, subscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError(CodeWebhookNotFound, "webhook subscription not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := s.DB.Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ListDeadLetters returns every delivery that ran out of attempts, oldest first
+func (s *WebhookServiceImpl) ListDeadLetters() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	if err := s.DB.Where("delivery_status = ?", DeliveryStatusDeadLetter).
+		Order("id ASC").
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RetryDelivery moves a dead-lettered delivery back to the queue with a fresh set of attempts
+func (s *WebhookServiceImpl) RetryDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&delivery, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NewNotFoundError(CodeWebhookDeliveryNotFound, "webhook delivery not found")
+			}
+			return fmt.Errorf("failed to lock webhook delivery: %w", err)
+		}
+
+		if delivery.DeliveryStatus != DeliveryStatusDeadLetter {
+			return NewConflictError(CodeWebhookDeliveryNotDead, "only dead-lettered deliveries can be retried")
+		}
+
+		delivery.DeliveryStatus = DeliveryStatusPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		if err := tx.Save(&delivery).Error; err != nil {
+			return fmt.Errorf("failed to requeue webhook delivery: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// DispatchPending sends every delivery that is due and returns the number delivered
+func (s *WebhookServiceImpl) DispatchPending(now time.Time) (int, error) {
+	var deliveries []models.WebhookDelivery
+	if err := s.DB.Where("delivery_status = ? AND next_attempt_at <= ?", DeliveryStatusPending, now).
+		Order("id ASC").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		return 0, fmt.Errorf("failed to load webhook deliveries: %w", err)
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		var subscription models.WebhookSubscription
+		if err := s.DB.First(&subscription, delivery.SubscriptionID).Error; err != nil {
+			return delivered, fmt.Errorf("failed to load webhook subscription: %w", err)
+		}
+
+		status, sendErr := s.Sender.Send(context.Background(), webhook.Request{
+			URL:        subscription.URL,
+			Secret:     subscription.Secret,
+			DeliveryID: delivery.ID,
+			EventType:  delivery.EventType,
+			Body:       []byte(delivery.Payload),
+		})
+
+		delivery.Attempts++
+		delivery.ResponseStatus = status
+		if sendErr != nil {
+			delivery.LastError = sendErr.Error()
+			if delivery.Attempts >= webhookMaxAttempts {
+				delivery.DeliveryStatus = DeliveryStatusDeadLetter
+			} else {
+				delivery.NextAttemptAt = now.Add(retryBackoff(delivery.Attempts))
+			}
+		} else {
+			delivery.DeliveryStatus = DeliveryStatusDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+			delivered++
+		}
+
+		if err := s.DB.Save(delivery).Error; err != nil {
+			return delivered, fmt.Errorf("failed to update webhook delivery: %w", err)
+		}
+	}
+	return delivered, nil
+}
+
+// enqueueWebhookDeliveries queues the event for every active subscription that wants it.
+// It runs inside the transaction that wrote the outbox event.
+func enqueueWebhookDeliveries(tx *gorm.DB, outboxEvent *models.OutboxEvent, payload []byte) error {
+	var subscriptions []models.WebhookSubscription
+	if err := tx.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscribesTo(&subscription, outboxEvent.EventType) {
+			continue
+		}
+
+		delivery := models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			OutboxEventID:  outboxEvent.ID,
+			EventType:      outboxEvent.EventType,
+			Payload:        string(payload),
+			DeliveryStatus: DeliveryStatusPending,
+			NextAttemptAt:  outboxEvent.NextAttemptAt,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// subscribesTo reports whether the subscription lists the event type
+func subscribesTo(subscription *models.WebhookSubscription, eventType string) bool {
+	for _, t := range strings.Split(subscription.EventTypes, ",") {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSubscription checks the URL, secret and event types and normalizes the event list
+func validateSubscription(subscription *models.WebhookSubscription) error {
+	u, err := url.Parse(subscription.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewValidationError(CodeInvalidWebhook, "invalid webhook: url must be an absolute http or https URL")
+	}
+	if len(subscription.Secret) < webhookMinSecretSize {
+		return NewValidationError(CodeInvalidWebhook, "invalid webhook: secret must be at least %d characters", webhookMinSecretSize)
+	}
+
+	var eventTypes []string
+	for _, t := range strings.Split(subscription.EventTypes, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !webhookEventTypes[t] {
+			return NewValidationError(CodeInvalidWebhook, "invalid webhook: unknown event type %q", t)
+		}
+		eventTypes = append(eventTypes, t)
+	}
+	if len(eventTypes) == 0 {
+		return NewValidationError(CodeInvalidWebhook, "invalid webhook: at least one event type is required")
+	}
+
+	subscription.EventTypes = strings.Join(eventTypes, ",")
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"flight-booking/internal/models"
+	"flight-booking/internal/notification"
+	"flight-booking/internal/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebhookSecret = "partner-secret-0123"
+
+// TestWebhooks_SignedDeliveryAndDeadLetter tests signed delivery to a receiver, retries and the dead-letter list
+func TestWebhooks_SignedDeliveryAndDeadLetter(t *testing.T) {
+	// Given
+	db := setupTestDB(t)
+	bookings := newTestBookingService(db)
+	webhooks := NewWebhookService(db, webhook.NewSender())
+
+	failing := true
+	var verified []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify(testWebhookSecret, timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		verified = append(verified, r.Header.Get(webhook.HeaderEvent))
+	}))
+	defer receiver.Close()
+
+	subscription, err := webhooks.CreateSubscription(&models.WebhookSubscription{
+		URL:        receiver.URL,
+		Secret:     testWebhookSecret,
+		EventTypes: "booking.confirmed, booking.cancelled",
+	})
+	require.NoError(t, err)
+
+	flight := newTestFlight()
+	flight.AvailableSeats = 2
+	require.NoError(t, db.Create(&flight).Error)
+
+	booking, err := bookings.CreateBooking(newTestBooking(flight.ID, "Chen Wei", 1))
+	require.NoError(t, err)
+
+	// When: the receiver is down for every attempt
+	now := time.Now()
+	for attempt := 0; attempt < webhookMaxAttempts; attempt++ {
+		_, err := webhooks.DispatchPending(now)
+		require.NoError(t, err)
+		now = now.Add(retryMaxBackoff)
+	}
+
+	// Then
+	dead, err := webhooks.ListDeadLetters()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, notification.EventBookingConfirmed, dead[0].EventType)
+	assert.Equal(t, http.StatusServiceUnavailable, dead[0].ResponseStatus)
+
+	// When: the receiver recovers, the dead letter is retried and new events flow
+	failing = false
+	_, err = webhooks.RetryDelivery(dead[0].ID)
+	require.NoError(t, err)
+	_, err = bookings.CancelBooking(booking.ID)
+	require.NoError(t, err)
+
+	delivered, err := webhooks.DispatchPending(time.Now().Add(time.Second))
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []string{notification.EventBookingConfirmed, notification.EventBookingCancelled}, verified)
+
+	log, err := webhooks.ListDeliveries(subscription.ID)
+	require.NoError(t, err)
+	require.Len(t, log, 2)
+	for _, d := range log {
+		assert.Equal(t, DeliveryStatusDelivered, d.DeliveryStatus)
+		assert.NotContains(t, d.Payload, "contact_email")
+	}
+}
+
+// TestCreateSubscription_Invalid tests webhook subscription validation
+func TestCreateSubscription_Invalid(t *testing.T) {
+	db := setupTestDB(t)
+	webhooks := NewWebhookService(db, webhook.NewSender())
+
+	cases := map[string]models.WebhookSubscription{
+		"relative url":   {URL: "/hooks", Secret: testWebhookSecret, EventTypes: "booking.confirmed"},
+		"short secret":   {URL: "https://partner.example.com/hooks", Secret: "short", EventTypes: "booking.confirmed"},
+		"unknown event":  {URL: "https://partner.example.com/hooks", Secret: testWebhookSecret, EventTypes: "flight.delayed"},
+		"no event types": {URL: "https://partner.example.com/hooks", Secret: testWebhookSecret},
+	}
+
+	for name, subscription := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := webhooks.CreateSubscription(&subscription)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request is one signed webhook call
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID uint
+	EventType  string
+	Body       []byte
+}
+
+// Sender posts signed webhook requests
+type Sender struct {
+	Client *http.Client
+	Now    func() time.Time
+}
+
+// NewSender creates a Sender with a 10 second request timeout
+func NewSender() *Sender {
+	return &Sender{Client: &http.Client{Timeout: 10 * time.Second}, Now: time.Now}
+}
+
+// Send posts the request and returns the response status code. Any non-2xx
+// response is returned as an error together with its status code.
+func (s *Sender) Send(ctx context.Context, req Request) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+
+	timestamp := s.Now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(req.DeliveryID), 10))
+
+	resp, err := s.Client.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
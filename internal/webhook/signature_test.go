@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSignAndVerify tests that signatures only verify with the same secret, timestamp and body
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":1,"type":"booking.confirmed"}`)
+	signature := Sign("0123456789abcdef", 1700000000, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.True(t, Verify("0123456789abcdef", 1700000000, body, signature))
+	assert.False(t, Verify("wrong-secret-0000", 1700000000, body, signature))
+	assert.False(t, Verify("0123456789abcdef", 1700000001, body, signature))
+	assert.False(t, Verify("0123456789abcdef", 1700000000, []byte(`{}`), signature))
+}
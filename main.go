@@ -1,18 +1,76 @@
 package main
 
 import (
+	"context"
 	"flight-booking/internal/database"
+	"flight-booking/internal/notification"
+	"flight-booking/internal/payment"
 	"flight-booking/internal/router"
+	"flight-booking/internal/service"
+	"flight-booking/internal/webhook"
+	"os"
+	"time"
 )
 
 func main() {
-	db, err := database.InitDB()
+	dbConfig, err := database.ConfigFromEnv()
+	if err != nil {
+		panic("invalid database configuration: " + err.Error())
+	}
+	db, err := database.InitDB(dbConfig)
 	if err != nil {
 		panic("failed to connect database: " + err.Error())
 	}
 
+	// Airports and airlines are bundled with the binary, so each release brings its own data
+	if err := service.LoadReferenceData(db); err != nil {
+		panic("failed to load reference data: " + err.Error())
+	}
+
+	// TODO: 正式環境需換成真正的金流服務
+	gateway := payment.NewFakeGateway()
+
+	// Return seats of abandoned checkouts and unpaid bookings to their flights,
+	// and retry refunds that did not reach the gateway
+	ctx := context.Background()
+	holdService := service.NewHoldService(db, service.DefaultHoldTTL)
+	paymentService := service.NewPaymentService(db, gateway)
+	refundService := service.NewRefundService(db, gateway)
+	go service.RunSweeper(ctx, "hold sweeper", time.Minute, holdService.ReleaseExpiredHolds)
+	go service.RunSweeper(ctx, "payment sweeper", time.Minute, paymentService.ReleaseUnpaidBookings)
+	go service.RunSweeper(ctx, "refund sweeper", time.Minute, refundService.DispatchPendingRefunds)
+
+	// Roll the schedules' flights forward as days enter the booking horizon
+	scheduleService := service.NewScheduleService(db, service.DefaultScheduleHorizonDays)
+	go service.RunSweeper(ctx, "schedule generator", time.Hour, scheduleService.GenerateFlights)
+
+	// Deliver booking notifications written to the outbox
+	outbox := service.NewOutboxDispatcher(db, notificationSinks()...)
+	go service.RunSweeper(ctx, "outbox dispatcher", 10*time.Second, outbox.DispatchPending)
+
+	// Deliver signed booking events to partner webhooks
+	webhooks := service.NewWebhookService(db, webhook.NewSender())
+	go service.RunSweeper(ctx, "webhook dispatcher", 10*time.Second, webhooks.DispatchPending)
+
 	// Setup Gin router
-	r := router.SetupRouter(db)
+	r := router.SetupRouter(db, gateway)
 
 	r.Run() // listen and serve on 0.0.0.0:8080
 }
+
+// notificationSinks always logs notifications and also emails or posts them when
+// SMTP_ADDR or NOTIFY_WEBHOOK_URL is set
+func notificationSinks() []notification.Sink {
+	sinks := []notification.Sink{notification.NewLogSink()}
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		from := os.Getenv("SMTP_FROM")
+		if from == "" {
+			from = "no-reply@flight-booking.local"
+		}
+		sinks = append(sinks, notification.NewSMTPSink(addr, from, nil))
+	}
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, notification.NewWebhookSink(url))
+	}
+	return sinks
+}